@@ -0,0 +1,100 @@
+package tagops
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Number is the constraint for the numeric types of the aggregate functions.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Sum returns the sum of the values of the numeric field with the json tag
+// name of each element of the slice of structs.  The values are converted to
+// T, and it returns an error if a value does not fit.  Nil pointer values are
+// skipped.
+//
+// The values are taken as with Pluck, by the default Mapper, so the Mapper
+// options, i.e. Tag or Prefix, do not apply.  To aggregate with the options,
+// take the values with Mapper.Pluck.
+func Sum[T Number](slice any, tagName string) (T, error) {
+	nums, err := pluckNumbers[T](slice, tagName)
+	if err != nil {
+		return 0, err
+	}
+	var sum T
+	for _, n := range nums {
+		sum += n
+	}
+	return sum, nil
+}
+
+// Min returns the minimum of the values of the numeric field with the json tag
+// name, see Sum.  It returns zero if there are no values.
+func Min[T Number](slice any, tagName string) (T, error) {
+	nums, err := pluckNumbers[T](slice, tagName)
+	if err != nil || len(nums) == 0 {
+		return 0, err
+	}
+	return slices.Min(nums), nil
+}
+
+// Max returns the maximum of the values of the numeric field with the json tag
+// name, see Sum.  It returns zero if there are no values.
+func Max[T Number](slice any, tagName string) (T, error) {
+	nums, err := pluckNumbers[T](slice, tagName)
+	if err != nil || len(nums) == 0 {
+		return 0, err
+	}
+	return slices.Max(nums), nil
+}
+
+// Count returns the number of elements of the slice of structs, where the
+// field with the json tag name is not nil, like COUNT(column) in SQL.  Nil
+// pointers and interfaces are not counted, as NULL, while the zero values,
+// i.e. 0, "" or false, are.  The values are taken as in Sum.
+func Count(slice any, tagName string) (int, error) {
+	vals, err := Pluck(slice, tagName)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, v := range vals {
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() || (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// pluckNumbers returns the values of the field with the tag name of each
+// element of the slice, converted to T.  Nil pointers are skipped.
+func pluckNumbers[T Number](slice any, tagName string) ([]T, error) {
+	vals, err := Pluck(slice, tagName)
+	if err != nil {
+		return nil, err
+	}
+	nums := make([]T, 0, len(vals))
+	for i, v := range vals {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				continue
+			}
+			v = rv.Elem().Interface()
+		}
+		var n T
+		if err := assign(context.Background(), reflect.ValueOf(&n).Elem(), v); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		nums = append(nums, n)
+	}
+	return nums, nil
+}
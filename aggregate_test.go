@@ -0,0 +1,101 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type aggItem struct {
+	Name  string   `json:"name"`
+	Qty   int      `json:"qty"`
+	Price float64  `json:"price"`
+	Disc  *float64 `json:"disc"`
+}
+
+func ptr[T any](v T) *T { return &v }
+
+var aggItems = []aggItem{
+	{"apple", 3, 1.5, nil},
+	{"pear", 1, 2.25, ptr(0.5)},
+	{"", 6, 0.75, ptr(0.25)},
+}
+
+func TestSum(t *testing.T) {
+	n, err := Sum[int](aggItems, "qty")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+
+	f, err := Sum[float64](aggItems, "price")
+	assert.NoError(t, err)
+	assert.Equal(t, 4.5, f)
+
+	f, err = Sum[float64](aggItems, "disc")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, f, "nil pointers are skipped")
+
+	_, err = Sum[int](aggItems, "price")
+	assert.Error(t, err, "fractional floats do not fit int")
+
+	_, err = Sum[int](aggItems, "name")
+	assert.Error(t, err)
+
+	_, err = Sum[int](aggItems, "missing")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+
+	_, err = Sum[int](aggItems[0], "qty")
+	assert.ErrorIs(t, err, ErrNotSlice)
+}
+
+func TestMinMax(t *testing.T) {
+	lo, err := Min[int](aggItems, "qty")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lo)
+
+	hi, err := Max[float64](aggItems, "price")
+	assert.NoError(t, err)
+	assert.Equal(t, 2.25, hi)
+
+	hi, err = Max[float64](aggItems, "disc")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, hi)
+
+	lo, err = Min[int]([]aggItem{}, "qty")
+	assert.NoError(t, err)
+	assert.Zero(t, lo)
+
+	_, err = Max[uint8]([]aggItem{{Qty: 300}}, "qty")
+	assert.Error(t, err, "overflow")
+}
+
+func TestCount(t *testing.T) {
+	n, err := Count(aggItems, "name")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n, "empty strings are counted")
+
+	n, err = Count(aggItems, "disc")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	type anyItem struct {
+		V any `json:"v"`
+	}
+	n, err = Count([]anyItem{{nil}, {(*int)(nil)}, {ptr(0)}, {1}, {"x"}}, "v")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n, "nil values are skipped")
+
+	type zeroItem struct {
+		N int    `json:"n"`
+		S string `json:"s"`
+		B bool   `json:"b"`
+	}
+	zeros := []zeroItem{{}, {N: 5, S: "x", B: true}}
+	for _, name := range []string{"n", "s", "b"} {
+		n, err = Count(zeros, name)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n, "zero %s is counted", name)
+	}
+
+	_, err = Count(aggItems, "missing")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+}
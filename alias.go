@@ -0,0 +1,41 @@
+package tagops
+
+import (
+	"reflect"
+	"strings"
+)
+
+// aliasTag is the tag with the comma-separated list of the historic keys of
+// the field, i.e. `json:"user_name" alias:"username,login"`.  FromMap and
+// the Importer accept the aliases in the input, when the key is absent.  The
+// output always uses the key.
+const aliasTag = "alias"
+
+// aliases returns the alias keys of the field.
+func aliases(field reflect.StructField) []string {
+	tag := field.Tag.Get(aliasTag)
+	if tag == "" {
+		return nil
+	}
+	var out []string
+	for _, a := range strings.Split(tag, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// lookupKey returns the value of the key in src, or the value of the first
+// alias of the field present in src.
+func lookupKey(src map[string]any, key string, field reflect.StructField) (any, bool) {
+	if v, ok := src[key]; ok {
+		return v, true
+	}
+	for _, a := range aliases(field) {
+		if v, ok := src[a]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,66 @@
+package tagops
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FromArgs populates the struct pointed to by dst from the "key=value"
+// arguments args, see Mapper.FromArgs.
+func FromArgs(dst any, args []string) error {
+	return New().FromArgs(dst, args)
+}
+
+// FromArgs populates the struct pointed to by dst from the "key=value"
+// arguments args, i.e. the command line overrides of the configuration file
+// values.  The keys are the field keys in the tag, or the dot-separated key
+// paths for fields of nested structs, i.e. "db.host=localhost".  The values
+// are parsed as with ParseStrings, with the tag options of the field.  Each
+// key of a slice field adds the element to the slice, that replaces the
+// current value, i.e. "tags=a tags=b" sets the tags to ["a", "b"].  For other
+// fields, the last value wins.  The fields that are not in args are left
+// untouched.
+//
+// It returns an error wrapping ErrFieldNotFound if there's no field for the
+// key, ErrReadOnly if the field is read-only, and a FieldError with the key
+// as the path, if the value can not be parsed.
+func (m Mapper) FromArgs(dst any, args []string) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected non-nil pointer to struct, got %T", ErrNotStruct, dst)
+	}
+	m = m.With(ParseStrings())
+	ctx := context.Background()
+	reset := make(map[string]bool) // slices that were reset
+	for _, arg := range args {
+		key, val, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid argument %q, expected key=value", arg)
+		}
+		fv, f, ok := m.fieldByPath(v.Elem(), key)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrFieldNotFound, key)
+		}
+		if f.readOnly {
+			return fmt.Errorf("%w: %q", ErrReadOnly, key)
+		}
+		if fv.Kind() != reflect.Slice || isBytes(fv.Type()) {
+			if err := m.assign(ctx, fv, val, f.fi.Options); err != nil {
+				return &FieldError{Path: key, Err: err}
+			}
+			continue
+		}
+		if !reset[key] {
+			fv.Set(reflect.MakeSlice(fv.Type(), 0, 1))
+			reset[key] = true
+		}
+		elem := reflect.New(fv.Type().Elem()).Elem()
+		if err := m.assign(ctx, elem, val, f.fi.Options); err != nil {
+			return &FieldError{Path: key, Err: err}
+		}
+		fv.Set(reflect.Append(fv, elem))
+	}
+	return nil
+}
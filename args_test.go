@@ -0,0 +1,74 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromArgs(t *testing.T) {
+	type db struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type config struct {
+		Name    string        `json:"name"`
+		Debug   bool          `json:"debug"`
+		Timeout time.Duration `json:"timeout"`
+		Tags    []string      `json:"tags"`
+		Ports   []int         `json:"ports"`
+		Ratio   *float64      `json:"ratio"`
+		DB      db            `json:"db"`
+	}
+	cfg := config{
+		Name: "app",
+		Tags: []string{"from-file"},
+		DB:   db{Host: "db.internal", Port: 5432},
+	}
+	err := FromArgs(&cfg, []string{
+		"debug=true",
+		"timeout=1m30s",
+		"tags=a",
+		"ports=80",
+		"tags=b=c",
+		"ports=443",
+		"ratio=0.5",
+		"db.port=6432",
+		"name=first",
+		"name=",
+	})
+	require.NoError(t, err)
+	ratio := 0.5
+	assert.Equal(t, config{
+		Name:    "",
+		Debug:   true,
+		Timeout: 90 * time.Second,
+		Tags:    []string{"a", "b=c"},
+		Ports:   []int{80, 443},
+		Ratio:   &ratio,
+		DB:      db{Host: "db.internal", Port: 6432},
+	}, cfg)
+
+	t.Run("tag options", func(t *testing.T) {
+		var s struct {
+			At time.Time `json:"at,unix"`
+		}
+		require.NoError(t, FromArgs(&s, []string{"at=1700000000"}))
+		assert.Equal(t, time.Unix(1700000000, 0), s.At)
+	})
+	t.Run("errors", func(t *testing.T) {
+		var cfg config
+		assert.ErrorIs(t, FromArgs(&cfg, []string{"nope=1"}), ErrFieldNotFound)
+		assert.ErrorIs(t, FromArgs(&cfg, []string{"db.nope=1"}), ErrFieldNotFound)
+		assert.Error(t, FromArgs(&cfg, []string{"debug"}))
+		assert.Error(t, FromArgs(&cfg, []string{"=x"}))
+		assert.ErrorIs(t, FromArgs(cfg, nil), ErrNotStruct)
+
+		err := FromArgs(&cfg, []string{"ports=80", "ports=x"})
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "ports", fe.Path)
+	})
+}
@@ -0,0 +1,136 @@
+package tagops
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// Batch converts many structs with ToMap, and keeps the results in one arena:
+// the keys are interned, and the keys and values of all records share the
+// same backing slices.  The export of millions of records then holds a few
+// large slices, instead of a map per record, which reduces the GC pressure.
+// The maps produced by the conversion are short-lived and are discarded, once
+// copied to the arena.
+//
+// Batch is not safe for concurrent use.
+type Batch struct {
+	m      Mapper
+	intern map[string]string
+	keys   []string
+	values []any
+	ends   []int // end offsets of the records in keys and values
+}
+
+// NewBatch returns the empty Batch that converts the structs with the Mapper
+// configured with options opts.
+func NewBatch(opts ...Option) *Batch {
+	return New(opts...).NewBatch()
+}
+
+// NewBatch returns the empty Batch that converts the structs with the Mapper.
+func (m Mapper) NewBatch() *Batch {
+	return &Batch{m: m, intern: make(map[string]string)}
+}
+
+// Grow grows the arena to fit another n records with fields fields each,
+// without reallocation.
+func (b *Batch) Grow(n, fields int) {
+	b.keys = slices.Grow(b.keys, n*fields)
+	b.values = slices.Grow(b.values, n*fields)
+	b.ends = slices.Grow(b.ends, n)
+}
+
+// Add converts the struct a, see Mapper.ToMapE, and appends the record to
+// the batch.  If the conversion fails, the record is not added, and the error
+// is returned.
+func (b *Batch) Add(a any) error {
+	mp, err := b.m.ToMapE(a)
+	if err != nil {
+		return err
+	}
+	start := len(b.keys)
+	for k := range mp {
+		b.keys = append(b.keys, b.internKey(k))
+	}
+	slices.Sort(b.keys[start:])
+	for _, k := range b.keys[start:] {
+		b.values = append(b.values, mp[k])
+	}
+	b.ends = append(b.ends, len(b.keys))
+	return nil
+}
+
+// AddSlice adds the elements of the slice of structs to the batch.  It stops
+// on the first error, the elements converted before the error remain in the
+// batch.
+func (b *Batch) AddSlice(slice any) error {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return err
+	}
+	for i := range sv.Len() {
+		if err := b.Add(sv.Index(i).Interface()); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// internKey returns the interned copy of the key k.
+func (b *Batch) internKey(k string) string {
+	if s, ok := b.intern[k]; ok {
+		return s
+	}
+	b.intern[k] = k
+	return k
+}
+
+// Len returns the number of records in the batch.
+func (b *Batch) Len() int {
+	return len(b.ends)
+}
+
+// Row returns the keys, sorted, and the values of the i-th record.  The
+// slices are the views of the arena, they must not be modified, and are
+// valid until Reset.  It panics if i is out of range.
+func (b *Batch) Row(i int) (keys []string, values []any) {
+	start := 0
+	if i > 0 {
+		start = b.ends[i-1]
+	}
+	end := b.ends[i]
+	return b.keys[start:end:end], b.values[start:end:end]
+}
+
+// Map returns the i-th record as the map, as returned by ToMap.  It
+// allocates the new map.
+func (b *Batch) Map(i int) map[string]any {
+	keys, values := b.Row(i)
+	mp := make(map[string]any, len(keys))
+	for j, k := range keys {
+		mp[k] = values[j]
+	}
+	return mp
+}
+
+// All returns the iterator over the records of the batch, see Row.
+func (b *Batch) All() iter.Seq2[[]string, []any] {
+	return func(yield func([]string, []any) bool) {
+		for i := range b.Len() {
+			if !yield(b.Row(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Reset removes all records from the batch, keeping the arena and the
+// interned keys for reuse.  The slices returned by Row before Reset must not
+// be used after it.
+func (b *Batch) Reset() {
+	clear(b.values) // release the references to the values
+	b.keys = b.keys[:0]
+	b.values = b.values[:0]
+	b.ends = b.ends[:0]
+}
@@ -0,0 +1,63 @@
+package tagops
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func TestBatch(t *testing.T) {
+	b := NewBatch(Omitempty())
+	b.Grow(3, 2)
+	require.NoError(t, b.AddSlice([]batchItem{{ID: 1, Name: "a"}, {ID: 2}}))
+	require.NoError(t, b.Add(&batchItem{ID: 3, Name: "c"}))
+	require.Equal(t, 3, b.Len())
+
+	keys, values := b.Row(0)
+	assert.Equal(t, []string{"id", "name"}, keys)
+	assert.Equal(t, []any{1, "a"}, values)
+	keys, values = b.Row(1)
+	assert.Equal(t, []string{"id"}, keys)
+	assert.Equal(t, []any{2}, values)
+	assert.Equal(t, map[string]any{"id": 3, "name": "c"}, b.Map(2))
+
+	// keys are interned, the key produced by the key function is shared.
+	kb := NewBatch(KeyFunc(strings.ToUpper))
+	require.NoError(t, kb.AddSlice([]struct{ Name string }{{"a"}, {"b"}}))
+	k0, _ := kb.Row(0)
+	k1, _ := kb.Row(1)
+	assert.Equal(t, []string{"NAME"}, k0)
+	assert.Same(t, unsafe.StringData(k0[0]), unsafe.StringData(k1[0]))
+
+	var ids []any
+	for _, values := range b.All() {
+		ids = append(ids, values[0])
+	}
+	assert.Equal(t, []any{1, 2, 3}, ids)
+
+	b.Reset()
+	assert.Equal(t, 0, b.Len())
+	require.NoError(t, b.Add(batchItem{ID: 4}))
+	assert.Equal(t, map[string]any{"id": 4}, b.Map(0))
+}
+
+func TestBatch_errors(t *testing.T) {
+	b := NewBatch()
+	assert.ErrorIs(t, b.Add(42), ErrNotStruct)
+	assert.ErrorIs(t, b.AddSlice(42), ErrNotSlice)
+	err := b.AddSlice([]any{batchItem{ID: 1}, "x"})
+	assert.ErrorIs(t, err, ErrNotStruct)
+	assert.ErrorContains(t, err, "element 1")
+	assert.Equal(t, 1, b.Len())
+	assert.Equal(t, []string{"id", "name"}, slices.Sorted(maps.Keys(b.Map(0))))
+}
@@ -0,0 +1,54 @@
+package tagops
+
+import "testing"
+
+type benchFlat struct {
+	ID      int     `json:"id"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email,omitempty"`
+	Age     int     `json:"age"`
+	Score   float64 `json:"score"`
+	Active  bool    `json:"active"`
+	Count   uint32  `json:"count"`
+	Balance int64   `json:"balance"`
+}
+
+var benchFlatValue = benchFlat{
+	ID:      123456,
+	Name:    "Ann",
+	Email:   "ann@example.com",
+	Age:     42,
+	Score:   99.5,
+	Active:  true,
+	Count:   7,
+	Balance: 1 << 40,
+}
+
+func BenchmarkToMap_flat(b *testing.B) {
+	m := New()
+	b.Run("value", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			_ = m.ToMap(benchFlatValue)
+		}
+	})
+	b.Run("pointer", func(b *testing.B) {
+		v := benchFlatValue
+		b.ReportAllocs()
+		for range b.N {
+			_ = m.ToMap(&v)
+		}
+	})
+}
+
+func BenchmarkFromMap_flat(b *testing.B) {
+	m := New()
+	src := m.ToMap(benchFlatValue)
+	b.ReportAllocs()
+	for range b.N {
+		var v benchFlat
+		if err := m.FromMap(src, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package tagops
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+)
+
+// float precision tag option prefix, i.e. "prec=2".
+const fPrecPrefix = "prec="
+
+// FloatPrecision returns an Option that rounds float values to n decimal
+// places, to avoid 0.30000000000000004-style artifacts in exports.  float32
+// and float64 values are emitted as float64, big.Float and big.Rat values are
+// emitted as strings with n decimal places.  The precision may be set per
+// field with the tag option "prec=", i.e. `json:"price,prec=2"`.
+func FloatPrecision(n int) Option {
+	return func(m *Mapper) {
+		m.floatPrec = &n
+	}
+}
+
+// isBig returns true if t is big.Int, big.Float or big.Rat, or a pointer to
+// any of them.
+func isBig(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case bigIntType, bigFloatType, bigRatType:
+		return true
+	}
+	return false
+}
+
+// floatPrecision returns the float precision, taking into account the tag
+// options opts.  It returns false if the precision is not set.
+func (m Mapper) floatPrecision(opts []string) (int, bool, error) {
+	for _, o := range opts {
+		if s, ok := strings.CutPrefix(o, fPrecPrefix); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				return 0, true, fmt.Errorf("invalid precision: %q", s)
+			}
+			return n, true, nil
+		}
+	}
+	if m.floatPrec == nil {
+		return 0, false, nil
+	}
+	return *m.floatPrec, true, nil
+}
+
+// formatFloat rounds the float value fv to the float precision.  It returns
+// false if the precision is not set.
+func (m Mapper) formatFloat(fv reflect.Value, opts []string) (any, bool, error) {
+	prec, ok, err := m.floatPrecision(opts)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	bits := fv.Type().Bits()
+	f, err := strconv.ParseFloat(strconv.FormatFloat(fv.Float(), 'f', prec, bits), 64)
+	return f, true, err
+}
+
+// formatBig formats the big number value fv as a string.  Nil pointers are
+// emitted as nil.
+func (m Mapper) formatBig(fv reflect.Value, opts []string) (any, error) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	prec, hasPrec, err := m.floatPrecision(opts)
+	if err != nil {
+		return nil, err
+	}
+	switch x := addr(fv).Interface().(type) {
+	case *big.Int:
+		return x.String(), nil
+	case *big.Float:
+		if hasPrec {
+			return x.Text('f', prec), nil
+		}
+		return x.Text('g', -1), nil
+	case *big.Rat:
+		if hasPrec {
+			return x.FloatString(prec), nil
+		}
+		return x.RatString(), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedKind, fv.Type())
+}
+
+// parseBig parses the map value sv, that is a string or a number, into the big
+// number value fv.  It returns false if sv is nil or of the same type as fv.
+func parseBig(fv reflect.Value, sv any) (bool, error) {
+	if sv == nil || reflect.TypeOf(sv) == fv.Type() {
+		return false, nil
+	}
+	elem := fv.Type()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	pv := reflect.New(elem)
+	s, isString := sv.(string)
+	var ok bool
+	switch x := pv.Interface().(type) {
+	case *big.Int:
+		if isString {
+			_, ok = x.SetString(s, 10)
+		} else if n, err := rawNumber(sv); err == nil {
+			_, ok = x.SetString(n, 10)
+		}
+	case *big.Float:
+		if isString {
+			_, ok = x.SetString(s)
+		} else if n, err := rawNumber(sv); err == nil {
+			_, ok = x.SetString(n)
+		}
+	case *big.Rat:
+		if isString {
+			_, ok = x.SetString(s)
+		} else if n, err := rawNumber(sv); err == nil {
+			_, ok = x.SetString(n)
+		}
+	}
+	if !ok {
+		return true, fmt.Errorf("cannot parse %v (%T) as %s", sv, sv, elem)
+	}
+	if fv.Kind() == reflect.Ptr {
+		fv.Set(pv)
+	} else {
+		fv.Set(pv.Elem())
+	}
+	return true, nil
+}
+
+// rawNumber returns the string representation of the numeric value v.
+func rawNumber(v any) (string, error) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case rv.CanUint():
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case rv.CanFloat():
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("expected number, got %T", v)
+}
+
+// addr returns the pointer to the value v.  If v is not addressable, the
+// pointer to a copy of v is returned.
+func addr(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+	pv := reflect.New(v.Type())
+	pv.Elem().Set(v)
+	return pv
+}
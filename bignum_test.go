@@ -0,0 +1,124 @@
+package tagops
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bigAccount struct {
+	Balance big.Int    `json:"balance"`
+	Supply  *big.Int   `json:"supply"`
+	Rate    *big.Float `json:"rate"`
+	Share   *big.Rat   `json:"share"`
+	Ratio   float64    `json:"ratio"`
+	Small   float32    `json:"small,prec=1"`
+}
+
+func newBigAccount() bigAccount {
+	var a bigAccount
+	a.Balance.SetString("123456789012345678901234567890", 10)
+	a.Supply = big.NewInt(42)
+	a.Rate = big.NewFloat(1.25)
+	a.Share = big.NewRat(1, 3)
+	x, y := 0.1, 0.2
+	a.Ratio = x + y
+	a.Small = 0.25
+	return a
+}
+
+func TestBigNumbers(t *testing.T) {
+	a := newBigAccount()
+	tests := []struct {
+		name string
+		m    Mapper
+		a    any
+		want map[string]any
+	}{
+		{
+			name: "strings",
+			m:    New(),
+			a:    a,
+			want: map[string]any{
+				"balance": "123456789012345678901234567890",
+				"supply":  "42",
+				"rate":    "1.25",
+				"share":   "1/3",
+				"ratio":   0.30000000000000004,
+				"small":   0.2,
+			},
+		},
+		{
+			name: "precision",
+			m:    New(FloatPrecision(2)),
+			a:    &a,
+			want: map[string]any{
+				"balance": "123456789012345678901234567890",
+				"supply":  "42",
+				"rate":    "1.25",
+				"share":   "0.33",
+				"ratio":   0.3,
+				"small":   0.2,
+			},
+		},
+		{
+			name: "nil pointers",
+			m:    New(),
+			a:    bigAccount{},
+			want: map[string]any{
+				"balance": "0",
+				"supply":  nil,
+				"rate":    nil,
+				"share":   nil,
+				"ratio":   float64(0),
+				"small":   float64(0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.ToMapE(tt.a)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("invalid precision", func(t *testing.T) {
+		_, err := New().ToMapE(struct {
+			F float64 `json:"f,prec=x"`
+		}{})
+		assert.Error(t, err)
+	})
+}
+
+func TestBigNumbers_FromMap(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		a := newBigAccount()
+		a.Small = 0.5
+		var got bigAccount
+		assert.NoError(t, New().FromMap(New().ToMap(a), &got))
+		assert.Equal(t, 0, a.Balance.Cmp(&got.Balance))
+		assert.Equal(t, 0, a.Supply.Cmp(got.Supply))
+		assert.Equal(t, 0, a.Rate.Cmp(got.Rate))
+		assert.Equal(t, 0, a.Share.Cmp(got.Share))
+	})
+	t.Run("numbers", func(t *testing.T) {
+		var got bigAccount
+		err := New().FromMap(map[string]any{
+			"balance": float64(12),
+			"supply":  7,
+			"rate":    0.5,
+			"share":   0.25,
+		}, &got)
+		assert.NoError(t, err)
+		assert.Equal(t, "12", got.Balance.String())
+		assert.Equal(t, "7", got.Supply.String())
+		assert.Equal(t, "0.5", got.Rate.String())
+		assert.Equal(t, "1/4", got.Share.RatString())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		var got bigAccount
+		assert.Error(t, New().FromMap(map[string]any{"supply": "forty two"}, &got))
+		assert.Error(t, New().FromMap(map[string]any{"supply": true}, &got))
+	})
+}
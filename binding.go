@@ -0,0 +1,96 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Binding is a Mapper bound to one struct type.  The type is inspected once,
+// when the Binding is created, and the keys are cached, so that repeated
+// conversions of values of that type do not need to recompute them.
+//
+// A Binding is not safe for concurrent use, as the Values buffer is reused
+// between calls.  Create a Binding per goroutine.
+type Binding struct {
+	m    Mapper
+	typ  reflect.Type
+	keys []string
+	buf  []any
+}
+
+// Bind returns the Binding for the type of the struct, or pointer to struct,
+// a.  The value of a is not used.
+func (m Mapper) Bind(a any) (*Binding, error) {
+	typ := reflect.TypeOf(a)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrNotStruct, a)
+	}
+	// keys of the zero value with omitempty disabled is the full set of
+	// keys for the type.
+	all := m
+	all.Omitempty = false
+	mp, err := all.ToMapE(reflect.New(typ).Interface())
+	if err != nil {
+		return nil, err
+	}
+	keys := KeysOrEmpty(mp)
+	return &Binding{m: m, typ: typ, keys: keys, buf: make([]any, len(keys))}, nil
+}
+
+// Type returns the struct type of the Binding.
+func (b *Binding) Type() reflect.Type {
+	return b.typ
+}
+
+// Tags returns the sorted list of all keys for the bound type, including
+// the keys of fields that may be omitted by Omitempty.
+func (b *Binding) Tags() []string {
+	return slices.Clone(b.keys)
+}
+
+// ToMap converts the struct a to a map, see Mapper.ToMapE.  a must be a
+// value of, or pointer to, the bound type.
+func (b *Binding) ToMap(a any) (map[string]any, error) {
+	if err := b.check(a, false); err != nil {
+		return nil, err
+	}
+	return b.m.ToMapE(a)
+}
+
+// Values returns the values of the struct a in the order of Tags.  Omitted
+// fields have nil values, so the number of values is the same for all values
+// of the bound type.  The returned slice is reused by the next call to
+// Values, copy it if it must be retained.
+func (b *Binding) Values(a any) ([]any, error) {
+	mp, err := b.ToMap(a)
+	if err != nil {
+		return nil, err
+	}
+	if err := MapValues(&b.buf, mp, b.keys); err != nil {
+		return nil, err
+	}
+	return b.buf, nil
+}
+
+// FromMap populates the struct pointed to by a with values from the map src,
+// see Mapper.FromMap.  a must be a pointer to the bound type.
+func (b *Binding) FromMap(src map[string]any, a any) error {
+	if err := b.check(a, true); err != nil {
+		return err
+	}
+	return b.m.FromMap(src, a)
+}
+
+// check returns an error if a is not of the bound type, or a pointer to it.
+// If ptr is true, a must be a pointer.
+func (b *Binding) check(a any, ptr bool) error {
+	typ := reflect.TypeOf(a)
+	if typ == reflect.PointerTo(b.typ) || (!ptr && typ == b.typ) {
+		return nil
+	}
+	return fmt.Errorf("%w: expected %s, got %T", ErrTypeMismatch, b.typ, a)
+}
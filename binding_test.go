@@ -0,0 +1,64 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+func TestMapper_Bind(t *testing.T) {
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := New().Bind(42)
+		assert.ErrorIs(t, err, ErrNotStruct)
+		_, err = New().Bind(nil)
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+	t.Run("pointer", func(t *testing.T) {
+		b, err := New().Bind(&bindUser{})
+		require.NoError(t, err)
+		assert.Equal(t, "bindUser", b.Type().Name())
+	})
+}
+
+func TestBinding(t *testing.T) {
+	b, err := New(Omitempty()).Bind(bindUser{})
+	require.NoError(t, err)
+
+	t.Run("tags include omitted fields", func(t *testing.T) {
+		assert.Equal(t, []string{"email", "id", "name"}, b.Tags())
+	})
+	t.Run("values are aligned with tags", func(t *testing.T) {
+		got, err := b.Values(bindUser{ID: 1, Name: "John"})
+		require.NoError(t, err)
+		assert.Equal(t, []any{nil, 1, "John"}, got)
+
+		got, err = b.Values(&bindUser{ID: 2, Email: "jane@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, []any{"jane@example.com", 2, nil}, got)
+	})
+	t.Run("to map and back", func(t *testing.T) {
+		u := bindUser{ID: 3, Name: "Bob"}
+		mp, err := b.ToMap(u)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": 3, "name": "Bob"}, mp)
+
+		var got bindUser
+		require.NoError(t, b.FromMap(mp, &got))
+		assert.Equal(t, u, got)
+	})
+	t.Run("type mismatch", func(t *testing.T) {
+		type other struct{ ID int }
+		_, err := b.ToMap(other{})
+		assert.ErrorIs(t, err, ErrTypeMismatch)
+		_, err = b.Values(nil)
+		assert.ErrorIs(t, err, ErrTypeMismatch)
+		assert.ErrorIs(t, b.FromMap(map[string]any{}, bindUser{}), ErrTypeMismatch)
+	})
+}
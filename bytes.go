@@ -0,0 +1,113 @@
+package tagops
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// BytesFormat defines how []byte and [N]byte values are emitted by ToMap.
+type BytesFormat int
+
+const (
+	// BytesRaw emits the values as is.  This is the default.
+	BytesRaw BytesFormat = iota
+	// BytesHex emits hex strings.  Tag option "hex".
+	BytesHex
+	// BytesBase64 emits standard base64 strings.  Tag option "base64".
+	BytesBase64
+)
+
+// bytes tag options.
+const (
+	fHex    = "hex"
+	fBase64 = "base64"
+)
+
+// FormatBytes returns an Option that sets the format of []byte and [N]byte
+// values, i.e. UUID-like arrays.  The format may be overridden per field with
+// the tag option, i.e. `json:"id,hex"`.  FromMap parses the values in the same
+// format back.
+func FormatBytes(f BytesFormat) Option {
+	return func(m *Mapper) {
+		m.bytesFmt = f
+	}
+}
+
+// isBytes returns true if t is a byte slice or a byte array.
+func isBytes(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8
+}
+
+// bytesFormat returns the bytes format, taking into account the tag options
+// opts.
+func (m Mapper) bytesFormat(opts []string) BytesFormat {
+	f := m.bytesFmt
+	for _, o := range opts {
+		switch o {
+		case fHex:
+			f = BytesHex
+		case fBase64:
+			f = BytesBase64
+		}
+	}
+	return f
+}
+
+// formatBytes formats the bytes value fv according to the bytes format.  It
+// returns false if the format is BytesRaw.  Nil slices are emitted as nil.
+func (m Mapper) formatBytes(fv reflect.Value, opts []string) (any, bool) {
+	f := m.bytesFormat(opts)
+	if f == BytesRaw {
+		return nil, false
+	}
+	if fv.Kind() == reflect.Slice && fv.IsNil() {
+		return nil, true
+	}
+	var b []byte
+	if fv.Kind() == reflect.Array {
+		b = make([]byte, fv.Len())
+		reflect.Copy(reflect.ValueOf(b), fv)
+	} else {
+		b = fv.Bytes()
+	}
+	if f == BytesHex {
+		return hex.EncodeToString(b), true
+	}
+	return base64.StdEncoding.EncodeToString(b), true
+}
+
+// parseBytes decodes the string sv formatted by formatBytes into fv.  It
+// returns false if sv is not a string, or the format is BytesRaw.
+func (m Mapper) parseBytes(fv reflect.Value, sv any, opts []string) (bool, error) {
+	s, ok := sv.(string)
+	if !ok {
+		return false, nil
+	}
+	f := m.bytesFormat(opts)
+	if f == BytesRaw {
+		return false, nil
+	}
+	var (
+		b   []byte
+		err error
+	)
+	if f == BytesHex {
+		b, err = hex.DecodeString(s)
+	} else {
+		b, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return true, err
+	}
+	if fv.Kind() == reflect.Array {
+		if len(b) != fv.Len() {
+			return true, fmt.Errorf("expected %d bytes, got %d", fv.Len(), len(b))
+		}
+		reflect.Copy(fv, reflect.ValueOf(b))
+		return true, nil
+	}
+	fv.Set(reflect.ValueOf(b).Convert(fv.Type()))
+	return true, nil
+}
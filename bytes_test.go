@@ -0,0 +1,55 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type uuid [4]byte
+
+func TestFormatBytes(t *testing.T) {
+	type S struct {
+		ID      uuid   `json:"id,hex"`
+		Payload []byte `json:"payload"`
+		Sig     []byte `json:"sig,base64"`
+		Nil     []byte `json:"nil"`
+	}
+	s := S{ID: uuid{0xde, 0xad, 0xbe, 0xef}, Payload: []byte("hi"), Sig: []byte{0xff}}
+
+	tests := []struct {
+		name string
+		m    Mapper
+		want map[string]any
+	}{
+		{
+			name: "raw, tag options",
+			m:    New(),
+			want: map[string]any{"id": "deadbeef", "payload": []byte("hi"), "sig": "/w==", "nil": []byte(nil)},
+		},
+		{
+			name: "base64",
+			m:    New(FormatBytes(BytesBase64)),
+			want: map[string]any{"id": "deadbeef", "payload": "aGk=", "sig": "/w==", "nil": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.m.ToMap(s))
+		})
+	}
+	t.Run("round trip", func(t *testing.T) {
+		for _, f := range []BytesFormat{BytesRaw, BytesHex, BytesBase64} {
+			m := New(FormatBytes(f))
+			var got S
+			assert.NoError(t, m.FromMap(m.ToMap(s), &got))
+			assert.Equal(t, s, got)
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		var got S
+		assert.Error(t, New().FromMap(map[string]any{"id": "dead"}, &got), "length")
+		assert.Error(t, New().FromMap(map[string]any{"id": "zz"}, &got), "hex")
+		assert.Error(t, New().FromMap(map[string]any{"sig": "!"}, &got), "base64")
+	})
+}
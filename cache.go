@@ -0,0 +1,148 @@
+package tagops
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// plans is the cache of the struct plans.
+var plans = newPlanCache()
+
+// planID identifies the struct plan.
+type planID struct {
+	typ     reflect.Type
+	tag     string
+	version string
+}
+
+// planEntry is the element of the plan cache list.
+type planEntry struct {
+	id   planID
+	plan *structPlan
+}
+
+// planCache is the cache of the struct plans, that evicts the least recently
+// used plans, if the limit is set.
+type planCache struct {
+	mu    sync.RWMutex
+	limit int
+	items map[planID]*list.Element
+	lru   *list.List // front is the most recently used
+
+	hits, misses, evictions atomic.Uint64
+	// gen is incremented by reset, so that the plans built before it are
+	// not cached.
+	gen atomic.Uint64
+}
+
+func newPlanCache() *planCache {
+	return &planCache{
+		items: make(map[planID]*list.Element),
+		lru:   list.New(),
+	}
+}
+
+// get returns the plan with the id, calling build to create it, if it's not
+// in the cache.  hit is true if the plan was cached.
+func (c *planCache) get(id planID, build func() *structPlan) (p *structPlan, hit bool) {
+	c.mu.RLock()
+	if c.limit == 0 {
+		// unbounded cache does not track the recency, so the hits only
+		// need the read lock.
+		if e, ok := c.items[id]; ok {
+			c.mu.RUnlock()
+			c.hits.Add(1)
+			return e.Value.(*planEntry).plan, true
+		}
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	if e, ok := c.items[id]; ok {
+		c.lru.MoveToFront(e)
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return e.Value.(*planEntry).plan, true
+	}
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	gen := c.gen.Load()
+	p = build()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[id]; ok {
+		// built concurrently.
+		return e.Value.(*planEntry).plan, false
+	}
+	if c.gen.Load() != gen {
+		// the cache was reset while building, the plan may be stale.
+		return p, false
+	}
+	c.items[id] = c.lru.PushFront(&planEntry{id: id, plan: p})
+	c.evict()
+	return p, false
+}
+
+// evict removes the least recently used plans over the limit.  The caller
+// must hold the lock.
+func (c *planCache) evict() {
+	for c.limit > 0 && c.lru.Len() > c.limit {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.items, e.Value.(*planEntry).id)
+		c.evictions.Add(1)
+	}
+}
+
+// reset removes all plans from the cache, i.e. when the converters change,
+// as the plans depend on them, see isNested.
+func (c *planCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen.Add(1)
+	clear(c.items)
+	c.lru.Init()
+}
+
+// SetCacheLimit sets the maximum number of struct plans in the cache, that
+// holds the parsed tags of the mapped struct types.  When the limit is
+// reached, the least recently used plans are evicted.  By default the cache
+// is unbounded, which is fine for the programs that map a fixed set of types,
+// but the long-running services that map many dynamically created types (see
+// reflect.StructOf) should set the limit.  n <= 0 removes the limit.  The
+// cache is shared by all Mappers, and is safe for concurrent use.
+func SetCacheLimit(n int) {
+	plans.mu.Lock()
+	defer plans.mu.Unlock()
+	plans.limit = max(n, 0)
+	plans.evict()
+}
+
+// CacheStat is the statistics of the struct plan cache, see CacheStats.
+type CacheStat struct {
+	// Size is the number of plans in the cache.
+	Size int
+	// Limit is the cache limit, 0 if the cache is unbounded.
+	Limit int
+	// Hits, Misses and Evictions are the counters since the program start.
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// CacheStats returns the statistics of the struct plan cache.
+func CacheStats() CacheStat {
+	plans.mu.RLock()
+	defer plans.mu.RUnlock()
+	return CacheStat{
+		Size:      plans.lru.Len(),
+		Limit:     plans.limit,
+		Hits:      plans.hits.Load(),
+		Misses:    plans.misses.Load(),
+		Evictions: plans.evictions.Load(),
+	}
+}
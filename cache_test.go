@@ -0,0 +1,115 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dynamicStruct returns the pointer to the new value of the struct type
+// created with reflect.StructOf, that has one int field with the key.
+func dynamicStruct(key string) any {
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeFor[int](),
+		Tag:  reflect.StructTag(fmt.Sprintf(`json:%q`, key)),
+	}})
+	return reflect.New(typ).Interface()
+}
+
+func TestSetCacheLimit(t *testing.T) {
+	t.Cleanup(func() { SetCacheLimit(0) })
+	SetCacheLimit(2)
+	before := CacheStats()
+	assert.Equal(t, 2, before.Limit)
+	assert.LessOrEqual(t, before.Size, 2)
+
+	for i := range 10 {
+		mp, err := New().ToMapE(dynamicStruct(fmt.Sprintf("k%d", i)))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{fmt.Sprintf("k%d", i): 0}, mp)
+	}
+	after := CacheStats()
+	assert.Equal(t, 2, after.Size)
+	assert.GreaterOrEqual(t, after.Misses-before.Misses, uint64(10))
+	assert.GreaterOrEqual(t, after.Evictions-before.Evictions, uint64(8))
+
+	SetCacheLimit(1)
+	assert.Equal(t, 1, CacheStats().Size)
+	SetCacheLimit(-1)
+	assert.Equal(t, 0, CacheStats().Limit)
+}
+
+func TestCacheStats_hits(t *testing.T) {
+	type S struct {
+		A int `json:"a"`
+	}
+	_, err := New().ToMapE(S{})
+	require.NoError(t, err)
+	before := CacheStats()
+	_, err = New().ToMapE(S{A: 1})
+	require.NoError(t, err)
+	var s S
+	require.NoError(t, New().FromMap(map[string]any{"a": 2}, &s))
+	after := CacheStats()
+	assert.GreaterOrEqual(t, after.Hits-before.Hits, uint64(2))
+}
+
+func TestPlanCache_concurrent(t *testing.T) {
+	c := newPlanCache()
+	c.limit = 4
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				id := planID{tag: fmt.Sprint((i + j) % 6)}
+				p, _ := c.get(id, func() *structPlan { return &structPlan{} })
+				assert.NotNil(t, p)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 4, c.lru.Len())
+	assert.Len(t, c.items, 4)
+	assert.Equal(t, uint64(800), c.hits.Load()+c.misses.Load())
+}
+
+// cacheInner is the test type, that gets the converter after its plan is
+// cached.
+type cacheInner struct {
+	X int `json:"x" col:"1"`
+}
+
+func TestRegisterConverter_resetsPlans(t *testing.T) {
+	type Outer struct {
+		B  int        `json:"b"`
+		In cacheInner `json:"in"`
+	}
+	typ := reflect.TypeOf(Outer{})
+	m := New()
+	assert.Equal(t, map[string]any{"b": 0, "in": map[string]any{"x": 0}}, m.ToMap(Outer{}))
+	require.True(t, m.plan(typ).pinned, "the nested struct has the pinned column")
+
+	RegisterConverter(reflect.TypeOf(cacheInner{}), reflect.TypeOf(""), func(v any) (any, error) {
+		return fmt.Sprint(v.(cacheInner).X), nil
+	})
+	assert.False(t, m.plan(typ).pinned, "the converted struct is a leaf value")
+	assert.Equal(t, map[string]any{"b": 0, "in": "0"}, m.ToMap(Outer{}))
+}
+
+func TestPlanCache_reset(t *testing.T) {
+	c := newPlanCache()
+	c.get(planID{tag: "a"}, func() *structPlan { return &structPlan{} })
+	c.get(planID{tag: "b"}, func() *structPlan {
+		c.reset() // i.e. the converter registered while building.
+		return &structPlan{}
+	})
+	assert.Zero(t, c.lru.Len())
+	assert.Empty(t, c.items)
+}
@@ -0,0 +1,139 @@
+package tagops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Canonical returns the canonical JSON representation of the struct a, see
+// Mapper.Canonical.
+func Canonical(a any) ([]byte, error) {
+	return New().Canonical(a)
+}
+
+// Canonical returns the canonical JSON representation of the map of the
+// struct a, suitable for computing signatures, or comparing the structs
+// byte for byte.  In the canonical form:
+//   - keys are sorted;
+//   - there is no insignificant whitespace, and HTML characters are not
+//     escaped;
+//   - time.Time values are RFC3339 strings in UTC;
+//   - numbers are written in the shortest form that round trips, without the
+//     exponent, unless they are smaller than 1e-6 or not smaller than 1e21,
+//     i.e. 1.0 as 1, and 0.00000025 as 2.5e-7.
+//
+// The Mapper time format settings are overridden.  NaN and infinite numbers
+// can not be represented, and result in error.
+func (m Mapper) Canonical(a any) ([]byte, error) {
+	mp, err := m.With(FormatTime(TimeRFC3339), TimeIn(time.UTC)).ToMapE(a)
+	if err != nil {
+		return nil, err
+	}
+	// round trip through JSON to get the values of the custom types, as they
+	// would be encoded.
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(mp); err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := writeCanonical(&out, v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// writeCanonical writes the canonical form of the decoded JSON value v to buf.
+func writeCanonical(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		buf.WriteByte('{')
+		for i, k := range Keys(v) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		s, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case string:
+		writeCanonicalString(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedKind, v)
+	}
+	return nil
+}
+
+// writeCanonicalString writes the JSON string s to buf without escaping HTML
+// characters.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)           // strings always encode
+	buf.Truncate(buf.Len() - 1) // trailing newline
+}
+
+// canonicalNumber returns the canonical form of the number n.
+func canonicalNumber(n json.Number) (string, error) {
+	s := n.String()
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return strconv.FormatInt(i, 10), nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return strconv.FormatUint(u, 10), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", err
+	}
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("unsupported number: %s", s)
+	}
+	if f == 0 {
+		return "0", nil // including negative zero
+	}
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		// as in encoding/json, clean up e-09 to e-9.
+		s = strconv.FormatFloat(f, 'e', -1, 64)
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+		return s, nil
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
@@ -0,0 +1,86 @@
+package tagops
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonical(t *testing.T) {
+	type inner struct {
+		Z int `json:"z"`
+		A any `json:"a"`
+	}
+	type doc struct {
+		Title  string    `json:"title"`
+		At     time.Time `json:"at"`
+		Ratio  float64   `json:"ratio"`
+		Whole  float64   `json:"whole"`
+		Big    uint64    `json:"big"`
+		Tags   []string  `json:"tags"`
+		Inner  inner     `json:"inner"`
+		Hidden string    `json:"-"`
+	}
+	paris := time.FixedZone("CET", 3600)
+	d := doc{
+		Title: "<a & b>",
+		At:    time.Date(2024, 5, 6, 13, 0, 0, 0, paris),
+		Ratio: 0.1,
+		Whole: 3.0,
+		Big:   math.MaxUint64,
+		Tags:  []string{"x", "y"},
+		Inner: inner{Z: 1, A: map[string]any{"b": 2.5e-7, "a": 1e21}},
+	}
+	got, err := Canonical(d)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"at":"2024-05-06T12:00:00Z","big":18446744073709551615,`+
+			`"inner":{"a":{"a":1e+21,"b":2.5e-7},"z":1},`+
+			`"ratio":0.1,"tags":["x","y"],"title":"<a & b>","whole":3}`,
+		string(got))
+	assert.True(t, json.Valid(got))
+
+	t.Run("same instant in other zone", func(t *testing.T) {
+		d2 := d
+		d2.At = d.At.UTC()
+		got2, err := Canonical(d2)
+		assert.NoError(t, err)
+		assert.Equal(t, got, got2)
+	})
+	t.Run("NaN", func(t *testing.T) {
+		_, err := Canonical(doc{Ratio: math.NaN()})
+		assert.Error(t, err)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := Canonical(42)
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+}
+
+func Test_canonicalNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"42", "42"},
+		{"-0", "0"},
+		{"1.0", "1"},
+		{"1e2", "100"},
+		{"1.50", "1.5"},
+		{"-2.5E-3", "-0.0025"},
+		{"1e21", "1e+21"},
+		{"0.00000025", "2.5e-7"},
+		{"-0.0", "0"},
+		{"18446744073709551615", "18446744073709551615"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := canonicalNumber(json.Number(tt.in))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
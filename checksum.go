@@ -0,0 +1,74 @@
+package tagops
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+)
+
+// checksum holds the checksum key settings.
+type checksum struct {
+	key     string
+	newHash func() hash.Hash
+}
+
+// Checksum returns an Option that adds the key with the checksum of the
+// values to the map returned by ToMap, and so to Values and Tags, for
+// tamper-evident data feeds.  The checksum is the hex-encoded hash of the
+// JSON encoding of the map without the checksum key, that is, of all values
+// ordered by keys.  If newHash is nil, SHA-256 is used.
+//
+// The checksum is added to the root map only, and not to the maps returned
+// by MapProvider.  It replaces the value of the field with the same key.  If
+// the map can not be encoded, the error is reported and the key is not added.
+// With Prefix, the checksum is computed over the prefixed keys, and the key is
+// prefixed as well, so the map is verified with the prefixed key.
+func Checksum(key string, newHash func() hash.Hash) Option {
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	return func(m *Mapper) {
+		m.checksum = &checksum{key: key, newHash: newHash}
+	}
+}
+
+// add adds the checksum of the map mp to it under the key.
+func (c *checksum) add(st *state, mp map[string]any, key string) {
+	delete(mp, key)
+	data, err := json.Marshal(mp)
+	if err != nil {
+		st.fail(key, fmt.Errorf("checksum: %w", err))
+		return
+	}
+	h := c.newHash()
+	h.Write(data)
+	mp[key] = hex.EncodeToString(h.Sum(nil))
+}
+
+// VerifyChecksum returns true if the map mp has the key with the valid
+// checksum of the other values, as added by the Checksum option with the
+// same hash.
+func VerifyChecksum(mp map[string]any, key string, newHash func() hash.Hash) bool {
+	sum, ok := mp[key].(string)
+	if !ok {
+		return false
+	}
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	rest := make(map[string]any, len(mp)-1)
+	for k, v := range mp {
+		if k != key {
+			rest[k] = v
+		}
+	}
+	data, err := json.Marshal(rest)
+	if err != nil {
+		return false
+	}
+	h := newHash()
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)) == sum
+}
@@ -0,0 +1,67 @@
+package tagops
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Payment struct {
+		ID      int       `json:"id"`
+		Amount  float64   `json:"amount"`
+		Created time.Time `json:"created"`
+		Address Address   `json:"address"`
+	}
+	p := Payment{ID: 1, Amount: 9.99, Created: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Address: Address{"Anytown"}}
+
+	m := New(Checksum("_sum", nil))
+	mp, err := m.ToMapE(p)
+	require.NoError(t, err)
+	require.Contains(t, mp, "_sum")
+	assert.Len(t, mp["_sum"], 64)
+	assert.True(t, VerifyChecksum(mp, "_sum", nil))
+
+	t.Run("stable", func(t *testing.T) {
+		again := m.ToMap(&p)
+		assert.Equal(t, mp["_sum"], again["_sum"])
+	})
+	t.Run("json round trip", func(t *testing.T) {
+		data, err := json.Marshal(mp)
+		require.NoError(t, err)
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, VerifyChecksum(decoded, "_sum", nil))
+		decoded["amount"] = 1000.0
+		assert.False(t, VerifyChecksum(decoded, "_sum", nil))
+	})
+	t.Run("values and tags", func(t *testing.T) {
+		assert.Equal(t, []string{"_sum", "address", "amount", "created", "id"}, m.Tags(p))
+		values, err := m.Values(p)
+		require.NoError(t, err)
+		assert.Equal(t, mp["_sum"], values[0])
+	})
+	t.Run("custom hash", func(t *testing.T) {
+		mp := New(Checksum("md5", md5.New)).ToMap(p)
+		assert.Len(t, mp["md5"], 32)
+		assert.True(t, VerifyChecksum(mp, "md5", md5.New))
+		assert.False(t, VerifyChecksum(mp, "md5", nil))
+	})
+	t.Run("prefix", func(t *testing.T) {
+		mp := m.With(Prefix("x.")).ToMap(p)
+		assert.NotContains(t, mp, "_sum")
+		require.Contains(t, mp, "x._sum")
+		assert.Contains(t, mp, "x.id")
+		assert.True(t, VerifyChecksum(mp, "x._sum", nil))
+	})
+	t.Run("missing", func(t *testing.T) {
+		assert.False(t, VerifyChecksum(New().ToMap(p), "_sum", nil))
+	})
+}
@@ -0,0 +1,93 @@
+package tagops
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// colTag is the tag that pins the position of the column in Tags, Values
+// and UnionTags, i.e. `col:"1"`.
+const colTag = "col"
+
+// colPosition returns the pinned column position of the field, starting
+// with 1.  It returns false if the field has no valid col tag.
+func colPosition(field reflect.StructField) (int, bool) {
+	s, ok := field.Tag.Lookup(colTag)
+	if !ok {
+		return 0, false
+	}
+	pos, err := strconv.Atoi(s)
+	if err != nil || pos < 1 {
+		return 0, false
+	}
+	return pos, true
+}
+
+// columnPins returns the column positions of the keys of the struct a, that
+// are pinned with the col tag, see columns.  The keys have the prefix, if
+// set, as in ToMap.  It returns nil if a is not a struct, or none of its
+// fields is pinned, which is looked up in the struct plan.
+func (m Mapper) columnPins(a any) map[string]int {
+	v, err := derefStruct(reflect.ValueOf(a))
+	if err != nil || !m.plan(v.Type()).pinned {
+		return nil
+	}
+	pins := make(map[string]int)
+	for _, f := range m.topFields(v.Type(), reflect.Value{}, true) {
+		if pos, ok := colPosition(f.fi.Field); ok {
+			pins[m.prefix+f.fi.Key] = pos
+		}
+	}
+	return pins
+}
+
+// columns returns the keys of mp in the column order: the keys pinned with
+// the col tag are at their positions, i.e. `col:"1"` is the first column, and
+// the other keys fill the remaining positions in the alphabetical order.  If
+// several keys are pinned to the same position, they take the consecutive
+// positions in the alphabetical order, and the keys pinned past the last
+// position go last.  The returned slice is never nil.
+func columns(mp map[string]any, pins map[string]int) []string {
+	keys := KeysOrEmpty(mp)
+	if len(pins) == 0 {
+		return keys
+	}
+	type pin struct {
+		key string
+		pos int
+	}
+	var pinned []pin
+	rest := keys[:0:0]
+	for _, k := range keys {
+		if pos, ok := pins[k]; ok {
+			pinned = append(pinned, pin{k, pos})
+		} else {
+			rest = append(rest, k)
+		}
+	}
+	slices.SortStableFunc(pinned, func(a, b pin) int { return cmp.Compare(a.pos, b.pos) })
+
+	out := make([]string, len(keys))
+	taken := make([]bool, len(keys))
+	var overflow []string
+	for _, p := range pinned {
+		i := p.pos - 1
+		for i < len(out) && taken[i] {
+			i++
+		}
+		if i >= len(out) {
+			overflow = append(overflow, p.key)
+			continue
+		}
+		out[i], taken[i] = p.key, true
+	}
+	rest = append(rest, overflow...)
+	for i := range out {
+		if !taken[i] {
+			out[i], rest = rest[0], rest[1:]
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ColBase struct {
+	ID int `json:"id" col:"1"`
+}
+
+type colUser struct {
+	ColBase
+	Name  string `json:"name" col:"2"`
+	Email string `json:"email,omitempty"`
+	Age   int    `json:"age"`
+	Zip   string `json:"zip" col:"9"`
+	Bad   string `json:"bad" col:"x"`
+}
+
+func Test_columns(t *testing.T) {
+	tests := []struct {
+		name string
+		pins map[string]int
+		mp   map[string]any
+		want []string
+	}{
+		{
+			name: "no pins",
+			mp:   map[string]any{"b": 1, "a": 2},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "pinned first and last",
+			pins: map[string]int{"z": 1, "a": 3},
+			mp:   map[string]any{"a": 1, "b": 2, "c": 3, "z": 4},
+			want: []string{"z", "b", "a", "c"},
+		},
+		{
+			name: "collision",
+			pins: map[string]int{"c": 1, "b": 1},
+			mp:   map[string]any{"a": 1, "b": 2, "c": 3},
+			want: []string{"b", "c", "a"},
+		},
+		{
+			name: "past the end",
+			pins: map[string]int{"a": 10},
+			mp:   map[string]any{"a": 1, "b": 2, "c": 3},
+			want: []string{"b", "c", "a"},
+		},
+		{
+			name: "pinned key missing",
+			pins: map[string]int{"x": 1, "c": 2},
+			mp:   map[string]any{"a": 1, "b": 2, "c": 3},
+			want: []string{"a", "c", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, columns(tt.mp, tt.pins))
+		})
+	}
+}
+
+func TestMapper_Tags_col(t *testing.T) {
+	m := New(Tag("json"))
+	u := colUser{ColBase{1}, "John", "", 42, "1000", "?"}
+	assert.Equal(t, []string{"id", "name", "age", "bad", "email", "zip"}, m.Tags(u))
+	assert.Equal(t, []string{"id", "name", "age", "bad", "email", "zip"}, m.Tags(&u))
+
+	vals, err := m.Values(u)
+	require.NoError(t, err)
+	assert.Equal(t, []any{1, "John", 42, "?", "", "1000"}, vals)
+
+	m = New(Tag("json"), Omitempty())
+	assert.Equal(t, []string{"id", "name", "age", "bad", "zip"}, m.Tags(u))
+
+	m = New(Tag("json"), Prefix("p_"))
+	assert.Equal(t, []string{"p_id", "p_name", "p_age", "p_bad", "p_email", "p_zip"}, m.Tags(u))
+}
+
+func TestMapper_columnPins(t *testing.T) {
+	type plain struct {
+		A int `json:"a"`
+	}
+	m := New()
+	assert.Nil(t, m.columnPins(plain{}), "no pins for the struct without the col tags")
+	assert.Nil(t, m.columnPins(42))
+	assert.Equal(t, map[string]int{"id": 1, "name": 2, "zip": 9}, m.columnPins(&colUser{}))
+}
+
+func TestMapper_UnionTags_col(t *testing.T) {
+	m := New(Tag("json"), Omitempty())
+	got, err := m.UnionTags([]colUser{
+		{Name: "John", Age: 1},
+		{ColBase{2}, "Jane", "jane@example.com", 0, "", ""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "age", "bad", "email", "zip"}, got)
+}
@@ -0,0 +1,39 @@
+package tagops
+
+import (
+	"fmt"
+	"slices"
+)
+
+// computedField is the derived key added by WithComputed.
+type computedField struct {
+	name string
+	fn   func(a any) any
+}
+
+// WithComputed returns an Option that adds the key name with the value
+// computed by fn to the map of the root struct, i.e. "full_name" from the
+// first and last names, so that the exports may include calculated columns
+// without wrapper structs.  fn receives the value passed to ToMap.  The key
+// is added before the checksum and the prefix, and appears in Tags and
+// Values as any other key.  Computed keys are ignored by FromMap.
+//
+// The option may be given several times to add several keys.  If the key is
+// already in the map, the value is kept, and the error wrapping
+// ErrKeyConflict is returned.
+func WithComputed(name string, fn func(a any) any) Option {
+	return func(m *Mapper) {
+		m.computed = append(slices.Clip(m.computed), computedField{name: name, fn: fn})
+	}
+}
+
+// addComputed adds the computed keys for the value a to mp.
+func (m Mapper) addComputed(st *state, a any, mp map[string]any) {
+	for _, c := range m.computed {
+		if _, ok := mp[c.name]; ok {
+			st.fail(c.name, fmt.Errorf("%w: computed key", ErrKeyConflict))
+			continue
+		}
+		mp[c.name] = c.fn(a)
+	}
+}
@@ -0,0 +1,60 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type computedPerson struct {
+	First string    `json:"first"`
+	Last  string    `json:"last"`
+	Born  time.Time `json:"born"`
+}
+
+func TestWithComputed(t *testing.T) {
+	p := computedPerson{First: "Ada", Last: "Lovelace", Born: time.Date(1815, 12, 10, 0, 0, 0, 0, time.UTC)}
+	fullName := WithComputed("full_name", func(a any) any {
+		p := a.(computedPerson)
+		return p.First + " " + p.Last
+	})
+	bornYear := WithComputed("born_year", func(a any) any {
+		return a.(computedPerson).Born.Year()
+	})
+	m := New(fullName, bornYear)
+
+	t.Run("ToMap", func(t *testing.T) {
+		got, err := m.ToMapE(p)
+		require.NoError(t, err)
+		assert.Equal(t, "Ada Lovelace", got["full_name"])
+		assert.Equal(t, 1815, got["born_year"])
+	})
+	t.Run("Tags and Values", func(t *testing.T) {
+		assert.Equal(t, []string{"born", "born_year", "first", "full_name", "last"}, m.Tags(p))
+		vals, err := m.Values(p)
+		require.NoError(t, err)
+		assert.Equal(t, []any{p.Born, 1815, "Ada", "Ada Lovelace", "Lovelace"}, vals)
+	})
+	t.Run("FromMap ignores computed keys", func(t *testing.T) {
+		var got computedPerson
+		require.NoError(t, m.FromMap(m.ToMap(p), &got))
+		assert.Equal(t, p, got)
+	})
+	t.Run("conflict", func(t *testing.T) {
+		m := New(WithComputed("first", func(any) any { return "x" }))
+		got, err := m.ToMapE(p)
+		assert.ErrorIs(t, err, ErrKeyConflict)
+		assert.Equal(t, "Ada", got["first"])
+	})
+	t.Run("With does not share computed keys", func(t *testing.T) {
+		base := New(fullName)
+		a := base.With(bornYear)
+		b := base.With(WithComputed("initials", func(any) any { return "AL" }))
+		assert.Contains(t, a.ToMap(p), "born_year")
+		assert.NotContains(t, a.ToMap(p), "initials")
+		assert.Contains(t, b.ToMap(p), "initials")
+		assert.NotContains(t, b.ToMap(p), "born_year")
+	})
+}
@@ -0,0 +1,96 @@
+package tagops
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stressAddress struct {
+	City string `json:"city" db:"city"`
+}
+
+type stressUser struct {
+	SliceBase
+	Name    string        `json:"name,omitempty" db:"name"`
+	Created time.Time     `json:"created,unix" db:"created"`
+	Timeout time.Duration `json:"timeout,seconds" db:"timeout"`
+	Level   level         `json:"level,enum" db:"level"`
+	Address stressAddress `json:"address" db:"address"`
+}
+
+// sharedMapper is shared between goroutines in TestMapper_concurrent.
+var sharedMapper = New(
+	FormatTime(TimeRFC3339),
+	AfterStruct(func(mp map[string]any) {
+		mp["seen"] = true
+	}),
+)
+
+func TestMapper_concurrent(t *testing.T) {
+	const (
+		workers = 16
+		iters   = 200
+	)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := sharedMapper.ToMap(stressUser{Name: "John"})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iters {
+				u := stressUser{
+					SliceBase: SliceBase{ID: w*iters + i},
+					Name:      fmt.Sprint("user", i),
+					Created:   created,
+					Timeout:   time.Duration(i) * time.Second,
+					Level:     info,
+					Address:   stressAddress{City: "Anytown"},
+				}
+				m := sharedMapper
+				if i%2 == 0 {
+					m = sharedMapper.With(Flatten(), Omitempty())
+				}
+				mp, err := m.ToMapCtx(context.Background(), u)
+				if err != nil {
+					errs <- err
+					return
+				}
+				var got stressUser
+				if err := m.FromMap(mp, &got); err != nil {
+					errs <- err
+					return
+				}
+				got.Created = got.Created.UTC() // unix times are parsed in local time
+				if !reflect.DeepEqual(u, got) {
+					errs <- fmt.Errorf("round trip: got %+v, want %+v", got, u)
+					return
+				}
+				_ = m.ToMultiMap(u, "json", "db")
+				if b, err := m.Bind(u); err != nil {
+					errs <- err
+					return
+				} else if _, err := b.Values(u); err != nil {
+					errs <- err
+					return
+				}
+				// registries are written to while in use.
+				RegisterEnum(map[string]level{"debug": debug, "info": info})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	assert.Equal(t, want, sharedMapper.ToMap(stressUser{Name: "John"}), "shared mapper must not change")
+}
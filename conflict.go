@@ -0,0 +1,151 @@
+package tagops
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// entry is a map value with the information needed to resolve key conflicts
+// between the fields of the struct and the fields promoted from flattened
+// structs, following the Go embedding rules, as encoding/json does.
+type entry struct {
+	// depth is the nesting depth of the fields relative to the struct.
+	depth int
+	// cands are the fields at that depth that map to the key.
+	cands []candidate
+	// one is the storage of cands for a single field, so that the entry is
+	// allocated at once.
+	one [1]candidate
+}
+
+// candidate is a field that maps to the key.
+type candidate struct {
+	val any
+	// tagged is true if the key comes from the tag.
+	tagged bool
+	path   string
+}
+
+// newEntry returns the entry for a single field at depth 0.
+func newEntry(val any, tagged bool, path string) *entry {
+	e := &entry{one: [1]candidate{{val: val, tagged: tagged, path: path}}}
+	e.cands = e.one[:]
+	return e
+}
+
+// dominant returns the field that wins: the only field, or the only tagged
+// one.  It returns false if the key is in conflict.
+func (e *entry) dominant() (candidate, bool) {
+	if len(e.cands) == 1 {
+		return e.cands[0], true
+	}
+	var (
+		c      candidate
+		tagged int
+	)
+	for _, cand := range e.cands {
+		if cand.tagged {
+			c = cand
+			tagged++
+		}
+	}
+	return c, tagged == 1
+}
+
+// entries is a map of keys to entries for a single struct.
+type entries map[string]*entry
+
+// add adds the entry e for the key.  The shallower fields win, fields at the
+// same depth are kept as candidates.
+func (es entries) add(key string, e *entry) {
+	ex, ok := es[key]
+	switch {
+	case !ok, e.depth < ex.depth:
+		es[key] = e
+	case e.depth == ex.depth:
+		ex.cands = append(ex.cands, e.cands...)
+	}
+}
+
+// merge adds the entries of the flattened struct es to dst, one level deeper.
+func (dst entries) merge(es entries) {
+	for key, e := range es {
+		e.depth++
+		dst.add(key, e)
+	}
+}
+
+// conflicts returns the errors for the keys in conflict.
+func (es entries) conflicts() []error {
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(es)) {
+		e := es[key]
+		if _, ok := e.dominant(); ok {
+			continue
+		}
+		var paths = make([]string, len(e.cands))
+		for i, c := range e.cands {
+			paths[i] = c.path
+		}
+		errs = append(errs, fmt.Errorf("%w: %q: %s", ErrKeyConflict, key, strings.Join(paths, ", ")))
+	}
+	return errs
+}
+
+// materialize returns the map of values, omitting keys in conflict.
+func (es entries) materialize() map[string]any {
+	out := make(map[string]any, len(es))
+	for key, e := range es {
+		if c, ok := e.dominant(); ok {
+			out[key] = c.val
+		}
+	}
+	return out
+}
+
+// update updates entries from the map mp, that was produced by materialize
+// and possibly modified.  Keys removed from mp are removed, keys added to mp
+// are added as untagged fields of the struct, and replace keys in conflict.
+func (es entries) update(mp map[string]any) {
+	for key, e := range es {
+		c, ok := e.dominant()
+		if !ok {
+			continue
+		}
+		if val, ok := mp[key]; ok {
+			c.val = val
+			es[key] = &entry{depth: e.depth, cands: []candidate{c}}
+		} else {
+			delete(es, key)
+		}
+	}
+	for key, val := range mp {
+		if e, ok := es[key]; !ok || len(e.cands) > 1 {
+			es[key] = newEntry(val, false, "")
+		}
+	}
+}
+
+// StrictConflicts returns an Option that makes ToMapE report an error
+// wrapping ErrKeyConflict, when several fields at the same depth map to the
+// same key, instead of silently omitting the key.
+func StrictConflicts() Option {
+	return func(m *Mapper) {
+		m.strictConflicts = true
+	}
+}
+
+// isTagged returns true if the field has the name in the tag.
+func isTagged(fld reflect.StructField, tag string) bool {
+	name, _, _ := strings.Cut(tagValue(fld, tag), tagsep)
+	return name != "" && name != "-"
+}
+
+// isIgnored returns true if the field has the name "-" in the tag.
+func isIgnored(fld reflect.StructField, tag string) bool {
+	name, _, _ := strings.Cut(tagValue(fld, tag), tagsep)
+	return name == "-"
+}
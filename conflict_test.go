@@ -0,0 +1,106 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	ConflictA struct {
+		Name string `db:"name"`
+		A    int    `db:"a"`
+	}
+	ConflictB struct {
+		Name string `db:"name"`
+		B    int    `db:"b"`
+	}
+	ConflictUntagged struct {
+		Name string
+	}
+	ConflictDeep struct {
+		ConflictA
+	}
+)
+
+// TestConflicts uses the db tag, as go vet reports duplicate json tags.  The
+// expected results are the same as encoding/json output.
+func TestConflicts(t *testing.T) {
+	type (
+		outerWins struct {
+			Name string `db:"name"`
+			ConflictA
+		}
+		outerWinsAfter struct {
+			ConflictA
+			Name string `db:"name"`
+		}
+		sameDepth struct {
+			ConflictA
+			ConflictB
+		}
+		taggedWins struct {
+			ConflictUntagged
+			Other struct {
+				Name string `db:"Name"`
+			} `db:"other"`
+			ConflictTagged
+		}
+		shallowerWins struct {
+			ConflictDeep
+			ConflictB
+		}
+	)
+	a := ConflictA{Name: "a", A: 1}
+	b := ConflictB{Name: "b", B: 2}
+	tests := []struct {
+		name string
+		a    any
+		want map[string]any
+	}{
+		{"outer wins", outerWins{"outer", a}, map[string]any{"name": "outer", "a": 1}},
+		{"outer wins, declared after", outerWinsAfter{a, "outer"}, map[string]any{"name": "outer", "a": 1}},
+		{"same depth conflict is dropped", sameDepth{a, b}, map[string]any{"a": 1, "b": 2}},
+		{"shallower wins", shallowerWins{ConflictDeep{a}, b}, map[string]any{"name": "b", "a": 1, "b": 2}},
+		{"tagged wins", taggedWins{ConflictUntagged: ConflictUntagged{"untagged"}, ConflictTagged: ConflictTagged{"tagged"}}, map[string]any{"Name": "tagged", "other": map[string]any{"Name": ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(Tag("db")).ToMapE(tt.a)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			mm := ToMultiMap(tt.a, "db")
+			assert.Equal(t, tt.want, mm["db"], "multimap")
+		})
+	}
+}
+
+type ConflictTagged struct {
+	Name string `db:"Name"`
+}
+
+func TestStrictConflicts(t *testing.T) {
+	type sameDepth struct {
+		ConflictA
+		ConflictB
+	}
+	got, err := New(Tag("db"), StrictConflicts()).ToMapE(sameDepth{})
+	assert.ErrorIs(t, err, ErrKeyConflict)
+	assert.EqualError(t, err, `: key conflict: "name": ConflictA.Name, ConflictB.Name`)
+	assert.Equal(t, map[string]any{"a": 0, "b": 0}, got)
+}
+
+func TestConflicts_afterStruct(t *testing.T) {
+	type sameDepth struct {
+		ConflictA
+		ConflictB
+	}
+	m := New(Tag("db"), AfterStruct(func(mp map[string]any) {
+		delete(mp, "b")
+		if _, ok := mp["name"]; !ok {
+			mp["name"] = "root"
+		}
+	}))
+	assert.Equal(t, map[string]any{"a": 1, "name": "root"}, m.ToMap(sameDepth{ConflictA: ConflictA{A: 1}}))
+}
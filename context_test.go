@@ -0,0 +1,88 @@
+package tagops
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+// token is a test type, which converter reads the value from the context.
+type token string
+
+func init() {
+	RegisterConverterCtx(reflect.TypeOf(token("")), reflect.TypeOf(""), func(ctx context.Context, v any) (any, error) {
+		prefix, _ := ctx.Value(ctxKey{}).(string)
+		return prefix + string(v.(token)), nil
+	})
+	RegisterConverterCtx(reflect.TypeOf(""), reflect.TypeOf(token("")), func(ctx context.Context, v any) (any, error) {
+		prefix, _ := ctx.Value(ctxKey{}).(string)
+		return token(v.(string)[len(prefix):]), nil
+	})
+}
+
+type ctxCard struct {
+	Holder string `json:"holder"`
+	Number token  `json:"number"`
+}
+
+func TestMapper_ToMapCtx(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "tok_")
+	t.Run("converter and hooks receive ctx", func(t *testing.T) {
+		var hookCtx []any
+		m := New(
+			BeforeFieldCtx(func(ctx context.Context, fi FieldInfo, v reflect.Value) bool {
+				hookCtx = append(hookCtx, ctx.Value(ctxKey{}))
+				return false
+			}),
+			AfterStructCtx(func(ctx context.Context, mp map[string]any) {
+				hookCtx = append(hookCtx, ctx.Value(ctxKey{}))
+			}),
+		)
+		got, err := m.ToMapCtx(ctx, ctxCard{Holder: "John", Number: "4111"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"holder": "John", "number": "tok_4111"}, got)
+		assert.Equal(t, []any{"tok_", "tok_", "tok_"}, hookCtx)
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		got, err := New().ToMapCtx(ctx, ctxCard{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, got)
+	})
+	t.Run("cancelled in hook", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var calls int
+		m := New(BeforeField(func(fi FieldInfo, v reflect.Value) bool {
+			calls++
+			cancel()
+			return false
+		}))
+		_, err := m.ToMapCtx(ctx, ctxCard{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestMapper_FromMapCtx(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "tok_")
+	t.Run("converter receives ctx", func(t *testing.T) {
+		var got ctxCard
+		err := New().FromMapCtx(ctx, map[string]any{"holder": "John", "number": "tok_4111"}, &got)
+		assert.NoError(t, err)
+		assert.Equal(t, ctxCard{Holder: "John", Number: "4111"}, got)
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		var got ctxCard
+		err := New().FromMapCtx(ctx, map[string]any{"holder": "John"}, &got)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, ctxCard{}, got)
+	})
+}
@@ -0,0 +1,121 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ConvertFunc converts the value v to some other type.  It is registered with
+// RegisterConverter.  ToMap omits the field if the function returns ErrSkip.
+type ConvertFunc func(v any) (any, error)
+
+// ConvertCtxFunc is the context-aware variant of ConvertFunc.  It receives the
+// context passed to ToMapCtx or FromMapCtx.
+type ConvertCtxFunc func(ctx context.Context, v any) (any, error)
+
+// convKey is the key of the converter registry.
+type convKey struct {
+	from, to reflect.Type
+}
+
+// registry holds registered converters.
+var registry = struct {
+	mu sync.RWMutex
+	// fns holds converters for a (from, to) pair.
+	fns map[convKey]ConvertCtxFunc
+	// out maps the source type to the target type used by ToMap.
+	out map[reflect.Type]reflect.Type
+}{
+	fns: make(map[convKey]ConvertCtxFunc),
+	out: make(map[reflect.Type]reflect.Type),
+}
+
+// RegisterConverter registers the converter function fn that converts values
+// of type from to values of type to.  The converter is used in both
+// directions of mapping: ToMap uses it to convert the struct field of type
+// from into the map value, and FromMap uses it to convert the map value of
+// type from into the struct field of type to.  Normally, one registers a pair
+// of converters, i.e. for time.Time to string and string to time.Time.
+//
+// If there are several converters registered for the same source type, ToMap
+// uses the one registered last.  Converters from predeclared types, such as
+// string or int64, are used only by FromMap, otherwise ToMap would convert all
+// fields of that type.  Struct types that have a converter are treated as leaf
+// values, and are not descended into.  Registering a converter clears the
+// cache of the struct plans, see SetCacheLimit, so converters are best
+// registered at the program start.
+//
+// It panics if any of the types or fn is nil.
+func RegisterConverter(from, to reflect.Type, fn ConvertFunc) {
+	if from == nil || to == nil || fn == nil {
+		panic("tagops: RegisterConverter: nil type or function")
+	}
+	RegisterConverterCtx(from, to, func(_ context.Context, v any) (any, error) {
+		return fn(v)
+	})
+}
+
+// RegisterConverterCtx is the context-aware variant of RegisterConverter.  Use
+// it for converters that call out to external services, i.e. encryption or
+// tokenization.
+func RegisterConverterCtx(from, to reflect.Type, fn ConvertCtxFunc) {
+	if from == nil || to == nil || fn == nil {
+		panic("tagops: RegisterConverterCtx: nil type or function")
+	}
+	registry.mu.Lock()
+	registry.fns[convKey{from, to}] = fn
+	if !isPredeclared(from) {
+		registry.out[from] = to
+	}
+	registry.mu.Unlock()
+	// the cached plans may treat the from type as nested.
+	plans.reset()
+}
+
+// isPredeclared returns true if t is a predeclared type, such as int or
+// string.
+func isPredeclared(t reflect.Type) bool {
+	return t.Name() != "" && t.PkgPath() == ""
+}
+
+// converterFor returns the converter for the (from, to) pair, if registered.
+func converterFor(from, to reflect.Type) (ConvertCtxFunc, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	fn, ok := registry.fns[convKey{from, to}]
+	return fn, ok
+}
+
+// outConverter returns the converter that ToMap should apply to values of type
+// from.
+func outConverter(from reflect.Type) (ConvertCtxFunc, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	to, ok := registry.out[from]
+	if !ok {
+		return nil, false
+	}
+	fn, ok := registry.fns[convKey{from, to}]
+	return fn, ok
+}
+
+// convertOut converts the value v with the ToMap converter registered for its
+// type.  If there's no converter, v is returned as is.
+func convertOut(ctx context.Context, v reflect.Value) (any, error) {
+	val := v.Interface()
+	fn, ok := outConverter(v.Type())
+	if !ok {
+		return val, nil
+	}
+	ret, err := fn(ctx, val)
+	if errors.Is(err, ErrSkip) {
+		return nil, err
+	}
+	if err != nil {
+		return val, fmt.Errorf("convert %s: %w", v.Type(), err)
+	}
+	return ret, nil
+}
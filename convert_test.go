@@ -0,0 +1,100 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// money is a test type that has converters registered.
+type money struct {
+	cents int64
+}
+
+func init() {
+	RegisterConverter(reflect.TypeOf(money{}), reflect.TypeOf(""), func(v any) (any, error) {
+		m := v.(money)
+		return fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100), nil
+	})
+	RegisterConverter(reflect.TypeOf(""), reflect.TypeOf(money{}), func(v any) (any, error) {
+		var units, cents int64
+		if _, err := fmt.Sscanf(v.(string), "%d.%d", &units, &cents); err != nil {
+			return nil, err
+		}
+		return money{cents: units*100 + cents}, nil
+	})
+}
+
+func TestRegisterConverter(t *testing.T) {
+	type Order struct {
+		ID    int    `json:"id"`
+		Note  string `json:"note"`
+		Price money  `json:"price"`
+	}
+	t.Run("ToMap", func(t *testing.T) {
+		got := ToMap(Order{ID: 1, Note: "x", Price: money{1050}}, "json", false, false)
+		assert.Equal(t, map[string]any{"id": 1, "note": "x", "price": "10.50"}, got)
+	})
+	t.Run("FromMap", func(t *testing.T) {
+		var got Order
+		err := FromMap(map[string]any{"id": 1, "price": "10.50"}, &got, "json", false)
+		assert.NoError(t, err)
+		assert.Equal(t, Order{ID: 1, Price: money{1050}}, got)
+	})
+	t.Run("FromMap converter error", func(t *testing.T) {
+		var got Order
+		err := FromMap(map[string]any{"price": "ten"}, &got, "json", false)
+		assert.Error(t, err)
+	})
+	t.Run("nil arguments panic", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterConverter(nil, reflect.TypeOf(""), nil) })
+	})
+}
+
+func Test_convertOut(t *testing.T) {
+	type failing struct{ X int }
+	RegisterConverter(reflect.TypeOf(failing{}), reflect.TypeOf(0), func(v any) (any, error) {
+		return nil, errors.New("boom")
+	})
+	tests := []struct {
+		name    string
+		v       any
+		want    any
+		wantErr bool
+	}{
+		{"no converter", 42, 42, false},
+		{"converter", money{199}, "1.99", false},
+		{"converter error leaves value", failing{1}, failing{1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertOut(context.Background(), reflect.ValueOf(tt.v))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("convertOut() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_isPredeclared(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want bool
+	}{
+		{"string", "", true},
+		{"int64", int64(0), true},
+		{"named", money{}, false},
+		{"slice", []byte{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isPredeclared(reflect.TypeOf(tt.v)))
+		})
+	}
+}
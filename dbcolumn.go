@@ -0,0 +1,65 @@
+package tagops
+
+import "strings"
+
+// Database column tag options, i.e. `db:"id,pk,auto"`, see DBColumn.
+const (
+	// fPK marks the primary key column, or a part of it.
+	fPK = "pk"
+	// fAuto marks the column generated by the database, i.e. serial or
+	// auto-increment.
+	fAuto = "auto"
+	// fGenerated marks the column computed by the database, i.e. with a
+	// default value or a trigger, that is never written by the statements.
+	// Unlike readonly, it does not affect FromMap, so the column can be read
+	// back.
+	fGenerated = "generated"
+	// fTypePrefix sets the column type, i.e. "type=varchar(255)".
+	fTypePrefix = "type="
+)
+
+// DBColumn is the description of the database column, parsed from the tag
+// options of the field, see FieldInfo.DBColumn.
+type DBColumn struct {
+	// PK is true if the column is the primary key, or a part of it, "pk".
+	PK bool
+	// Auto is true if the value is generated by the database, i.e. serial
+	// or auto-increment column, "auto".
+	Auto bool
+	// Generated is true if the column is computed by the database, i.e.
+	// has a default value, and is never written, "generated".
+	Generated bool
+	// Type is the column type, i.e. "varchar(255)" for
+	// "type=varchar(255)".  The type may contain commas, i.e.
+	// "type=numeric(10,2)".
+	Type string
+}
+
+// DBColumn returns the database column description parsed from the tag
+// options of the field, i.e. `db:"id,pk,auto"` or
+// `db:"price,type=numeric(10,2)"`.  Options that don't describe the column
+// are ignored.
+func (fi FieldInfo) DBColumn() DBColumn {
+	var col DBColumn
+	for i := 0; i < len(fi.Options); i++ {
+		opt := fi.Options[i]
+		switch {
+		case opt == fPK:
+			col.PK = true
+		case opt == fAuto:
+			col.Auto = true
+		case opt == fGenerated:
+			col.Generated = true
+		case strings.HasPrefix(opt, fTypePrefix):
+			typ := strings.TrimPrefix(opt, fTypePrefix)
+			// the options are split on commas, so the type arguments
+			// are joined back.
+			for strings.Count(typ, "(") > strings.Count(typ, ")") && i+1 < len(fi.Options) {
+				i++
+				typ += "," + fi.Options[i]
+			}
+			col.Type = typ
+		}
+	}
+	return col
+}
@@ -0,0 +1,28 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldInfo_DBColumn(t *testing.T) {
+	var tests = []struct {
+		name string
+		opts []string
+		want DBColumn
+	}{
+		{"none", nil, DBColumn{}},
+		{"pk auto", []string{"pk", "auto"}, DBColumn{PK: true, Auto: true}},
+		{"generated", []string{"omitempty", "generated"}, DBColumn{Generated: true}},
+		{"readonly is not generated", []string{"readonly"}, DBColumn{}},
+		{"type", []string{"type=varchar(255)"}, DBColumn{Type: "varchar(255)"}},
+		{"type with commas", []string{"type=numeric(10", "2)", "pk"}, DBColumn{PK: true, Type: "numeric(10,2)"}},
+		{"unbalanced type", []string{"type=numeric(10"}, DBColumn{Type: "numeric(10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FieldInfo{Options: tt.opts}.DBColumn())
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// deprecatedTag is the tag that marks the field as deprecated, with the
+// message for the users, i.e. `json:"user_name" deprecated:"use username"`.
+const deprecatedTag = "deprecated"
+
+// OnDeprecated returns an Option that sets the callback fn, that is called
+// when FromMap populates the field marked with the deprecated tag, i.e. to
+// log a warning while the clients migrate to the new schema.  fn receives the
+// field information and the message from the tag.
+func OnDeprecated(fn func(fi FieldInfo, msg string)) Option {
+	return func(m *Mapper) {
+		m.onDeprecated = fn
+	}
+}
+
+// deprecated calls the deprecation callback, if set and the field is
+// deprecated.
+func (m Mapper) deprecated(field reflect.StructField, key, path, tag string) {
+	if m.onDeprecated == nil {
+		return
+	}
+	if msg, ok := field.Tag.Lookup(deprecatedTag); ok {
+		fi := newFieldInfo(field, key, path, tag)
+		m.onDeprecated(fi, msg)
+	}
+}
+
+// DeprecatedFields returns the deprecated fields of the struct a, see
+// Mapper.DeprecatedFields.  It returns nil if a is not a struct.
+func DeprecatedFields(a any) map[string]string {
+	fields, _ := New().DeprecatedFields(a)
+	return fields
+}
+
+// DeprecatedFields returns the map of the dot-separated key paths of the
+// fields of the struct a, or its type, marked with the deprecated tag, to the
+// messages from the tag, i.e. for the schema linters and the documentation.
+func (m Mapper) DeprecatedFields(a any) (map[string]string, error) {
+	typ := reflect.TypeOf(a)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrNotStruct, a)
+	}
+	out := make(map[string]string)
+	m.deprecatedFields(out, typ, "")
+	return out, nil
+}
+
+func (m Mapper) deprecatedFields(out map[string]string, typ reflect.Type, prefix string) {
+	m = m.withProfile(typ)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := m.tagFor(field)
+		if isNested(field.Type) && m.flattens(field, tag) {
+			m.deprecatedFields(out, field.Type, prefix)
+			continue
+		}
+		key, err := m.tagName(field, reflect.Value{}, tag, false)
+		if err != nil {
+			continue
+		}
+		if msg, ok := field.Tag.Lookup(deprecatedTag); ok {
+			out[prefix+key] = msg
+		}
+		if isNested(field.Type) {
+			m.deprecatedFields(out, field.Type, prefix+key+".")
+		}
+	}
+}
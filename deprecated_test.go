@@ -0,0 +1,61 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deprecatedAddress struct {
+	Street string `json:"street"`
+	Line1  string `json:"line1" deprecated:"use street"`
+}
+
+type deprecatedUser struct {
+	Name     string            `json:"username"`
+	UserName string            `json:"user_name" deprecated:"use username"`
+	Address  deprecatedAddress `json:"address"`
+	Legacy   deprecatedAddress `json:"legacy" deprecated:""`
+}
+
+func TestOnDeprecated(t *testing.T) {
+	type warning struct {
+		Key, Path, Msg string
+	}
+	var got []warning
+	m := New(OnDeprecated(func(fi FieldInfo, msg string) {
+		got = append(got, warning{fi.Key, fi.Path, msg})
+	}))
+	var u deprecatedUser
+	require.NoError(t, m.FromMap(map[string]any{
+		"username":  "ann",
+		"user_name": "ann",
+		"address":   map[string]any{"line1": "Main st", "street": "Main st"},
+		"legacy":    map[string]any{},
+	}, &u))
+	assert.ElementsMatch(t, []warning{
+		{"user_name", "UserName", "use username"},
+		{"line1", "Address.Line1", "use street"},
+		{"legacy", "Legacy", ""},
+	}, got)
+
+	got = nil
+	require.NoError(t, m.FromMap(map[string]any{"username": "ann"}, &u))
+	assert.Empty(t, got, "absent keys are not reported")
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"user_name":     "use username",
+		"address.line1": "use street",
+		"legacy":        "",
+		"legacy.line1":  "use street",
+	}, DeprecatedFields(&deprecatedUser{}))
+
+	got, err := New(Flatten()).DeprecatedFields(deprecatedAddress{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"line1": "use street"}, got)
+
+	assert.Nil(t, DeprecatedFields(42))
+}
@@ -0,0 +1,106 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is the difference of the field value between two structs.
+type Change struct {
+	// Path is the dot-separated key path of the field, i.e. "address.city".
+	Path string
+	// Old and New are the values before and after the change.  The value is
+	// nil if the key is missing, i.e. omitted with omitempty.
+	Old, New any
+}
+
+// String returns the change as "path: old → new".  Missing values are shown
+// as "(none)", and strings are quoted.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Path, formatDiffValue(c.Old), formatDiffValue(c.New))
+}
+
+// Diff returns the changes between the structs old and new, see Mapper.Diff.
+func Diff(old, new any) ([]Change, error) {
+	return New().Diff(old, new)
+}
+
+// FormatDiff returns the changes between the structs old and new as text,
+// one "path: old → new" line per change, see Mapper.FormatDiff.  It returns
+// an empty string if there are no changes, or on error.
+func FormatDiff(old, new any) string {
+	s, _ := New().FormatDiff(old, new)
+	return s
+}
+
+// Diff compares the maps returned by ToMap for the structs old and new, and
+// returns the changes ordered by path.  Nested maps are compared key by key,
+// other values are compared with reflect.DeepEqual.  The structs are usually
+// of the same type, but need not be.
+func (m Mapper) Diff(old, new any) ([]Change, error) {
+	om, err := m.ToMapE(old)
+	if err != nil {
+		return nil, fmt.Errorf("old: %w", err)
+	}
+	nm, err := m.ToMapE(new)
+	if err != nil {
+		return nil, fmt.Errorf("new: %w", err)
+	}
+	var out []Change
+	diffMaps(&out, "", om, nm)
+	return out, nil
+}
+
+// diffMaps appends the changes between the maps om and nm to out.
+func diffMaps(out *[]Change, prefix string, om, nm map[string]any) {
+	keys := make(map[string]any, len(om)+len(nm))
+	for k := range om {
+		keys[k] = nil
+	}
+	for k := range nm {
+		keys[k] = nil
+	}
+	for _, k := range Keys(keys) {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		ov, oOK := om[k]
+		nv, nOK := nm[k]
+		oMap, oIsMap := ov.(map[string]any)
+		nMap, nIsMap := nv.(map[string]any)
+		switch {
+		case oIsMap && nIsMap:
+			diffMaps(out, path, oMap, nMap)
+		case oOK != nOK || !reflect.DeepEqual(ov, nv):
+			*out = append(*out, Change{Path: path, Old: ov, New: nv})
+		}
+	}
+}
+
+// FormatDiff is like Diff, but returns the changes as text, one
+// "path: old → new" line per change.  Missing values are shown as "(none)".
+func (m Mapper) FormatDiff(old, new any) (string, error) {
+	changes, err := m.Diff(old, new)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, c := range changes {
+		sb.WriteString(c.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+// formatDiffValue formats the value v for FormatDiff.
+func formatDiffValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,57 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type diffAddress struct {
+	City string `json:"city"`
+	ZIP  string `json:"zip,omitempty"`
+}
+
+type diffUser struct {
+	Name    string      `json:"name"`
+	Age     int         `json:"age"`
+	Tags    []string    `json:"tags"`
+	Address diffAddress `json:"address"`
+}
+
+func TestDiff(t *testing.T) {
+	old := diffUser{Name: "John", Age: 30, Tags: []string{"a"}, Address: diffAddress{City: "Anytown", ZIP: "123"}}
+	new := diffUser{Name: "John", Age: 31, Tags: []string{"a", "b"}, Address: diffAddress{City: "Othertown"}}
+
+	t.Run("changes", func(t *testing.T) {
+		got, err := New(Omitempty()).Diff(old, &new)
+		require.NoError(t, err)
+		assert.Equal(t, []Change{
+			{Path: "address.city", Old: "Anytown", New: "Othertown"},
+			{Path: "address.zip", Old: "123", New: nil},
+			{Path: "age", Old: 30, New: 31},
+			{Path: "tags", Old: []string{"a"}, New: []string{"a", "b"}},
+		}, got)
+	})
+	t.Run("no changes", func(t *testing.T) {
+		got, err := Diff(old, old)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := Diff(old, 42)
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+}
+
+func TestFormatDiff(t *testing.T) {
+	old := diffUser{Name: "John", Age: 30, Address: diffAddress{City: "Anytown", ZIP: "123"}}
+	new := diffUser{Name: "Jane", Age: 30, Address: diffAddress{City: "Anytown"}}
+	want := "address.zip: \"123\" → (none)\n" +
+		"name: \"John\" → \"Jane\"\n"
+	got, err := New(Omitempty()).FormatDiff(old, new)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, "", FormatDiff(old, old))
+	assert.Equal(t, "", FormatDiff(old, nil))
+}
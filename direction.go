@@ -0,0 +1,56 @@
+package tagops
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Field direction tag options.
+const (
+	// fReadOnly marks the field as read-only, i.e. `json:"id,readonly"`.
+	// It is written by ToMap, but not populated by FromMap, JSON Patch,
+	// Update and FromArgs.  On a flattened struct, it applies to all of
+	// its fields.
+	fReadOnly = "readonly"
+	// fWriteOnly marks the field as write-only, i.e.
+	// `json:"password,writeonly"`.  It is populated by FromMap, but not
+	// written by ToMap.
+	fWriteOnly = "writeonly"
+)
+
+// ErrReadOnly is returned when the JSON Patch operation, Update or FromArgs
+// modifies a read-only field.
+var ErrReadOnly = errors.New("read-only field")
+
+// hasOption returns true if the field has the tag option opt in the tag.
+func hasOption(field reflect.StructField, tag, opt string) bool {
+	return slices.Contains(tagOptions(field, tag), opt)
+}
+
+// readOnlyPath returns the path of the read-only field on the path of keys
+// in the type t, if any.  Interface values are not descended into.
+func (m Mapper) readOnlyPath(t reflect.Type, path []string) (string, bool) {
+	for i, key := range path {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := m.lookupField(t, key)
+			if !ok {
+				return "", false
+			}
+			if f.readOnly {
+				return strings.Join(path[:i+1], "/"), true
+			}
+			t = f.fi.Field.Type
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,132 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type directionAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type directionUser struct {
+	ID       int               `json:"id,readonly"`
+	Name     string            `json:"name"`
+	Password string            `json:"password,writeonly"`
+	Audit    directionAudit    `json:"audit,readonly"`
+	Tags     map[string]string `json:"tags"`
+}
+
+type DirectionStamp struct {
+	Version int `json:"version"`
+}
+
+type directionDoc struct {
+	DirectionStamp `json:",readonly"`
+	Title          string `json:"title"`
+}
+
+func TestDirections(t *testing.T) {
+	u := directionUser{ID: 1, Name: "ann", Password: "secret", Audit: directionAudit{CreatedBy: "root"}}
+	t.Run("ToMap skips write-only", func(t *testing.T) {
+		got := ToMap(u, "json", false, false)
+		assert.Equal(t, map[string]any{
+			"id":    1,
+			"name":  "ann",
+			"audit": map[string]any{"created_by": "root"},
+			"tags":  map[string]string(nil),
+		}, got)
+		var keys []string
+		for k := range New().TopLevel(u) {
+			keys = append(keys, k)
+		}
+		assert.NotContains(t, keys, "password")
+	})
+	t.Run("FromMap skips read-only", func(t *testing.T) {
+		got := u
+		require.NoError(t, New().FromMap(map[string]any{
+			"id":       2,
+			"name":     "bob",
+			"password": "new",
+			"audit":    map[string]any{"created_by": "mallory"},
+		}, &got))
+		assert.Equal(t, directionUser{ID: 1, Name: "bob", Password: "new", Audit: directionAudit{CreatedBy: "root"}}, got)
+	})
+	t.Run("MergePatch", func(t *testing.T) {
+		got := u
+		require.NoError(t, MergePatch(&got, []byte(`{"id":3,"name":"eve"}`)))
+		assert.Equal(t, 1, got.ID)
+		assert.Equal(t, "eve", got.Name)
+	})
+	t.Run("JSON Patch", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			op      Op
+			wantErr error
+		}{
+			{"replace", Op{Op: "replace", Path: "/id", Value: 2}, ErrReadOnly},
+			{"nested", Op{Op: "replace", Path: "/audit/created_by", Value: "x"}, ErrReadOnly},
+			{"remove", Op{Op: "remove", Path: "/audit"}, ErrReadOnly},
+			{"move from", Op{Op: "move", From: "/audit/created_by", Path: "/name"}, ErrReadOnly},
+			{"copy from", Op{Op: "copy", From: "/audit/created_by", Path: "/name"}, nil},
+			{"test", Op{Op: "test", Path: "/id", Value: 1}, nil},
+			{"writable", Op{Op: "add", Path: "/tags/env", Value: "prod"}, nil},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got := u
+				err := ApplyJSONPatch(&got, []Op{tt.op})
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+					assert.Equal(t, u.ID, got.ID)
+					assert.Equal(t, u.Audit, got.Audit)
+					return
+				}
+				require.NoError(t, err)
+			})
+		}
+	})
+	t.Run("Importer", func(t *testing.T) {
+		imp, err := New().NewImporter([]string{"id", "name", "audit.created_by"}, directionUser{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"id", "audit.created_by"}, imp.Report().Unmatched)
+	})
+	t.Run("Update", func(t *testing.T) {
+		for _, key := range []string{"id", "audit.created_by"} {
+			got := u
+			err := Update(&got, map[string]func(any) any{key: func(any) any { return 2 }})
+			assert.ErrorIs(t, err, ErrReadOnly, key)
+			assert.Equal(t, u, got)
+		}
+		got := u
+		require.NoError(t, Update(&got, map[string]func(any) any{"name": func(any) any { return "bob" }}))
+		assert.Equal(t, "bob", got.Name)
+	})
+	t.Run("FromArgs", func(t *testing.T) {
+		for _, arg := range []string{"id=2", "audit.created_by=mallory"} {
+			got := u
+			assert.ErrorIs(t, FromArgs(&got, []string{arg}), ErrReadOnly, arg)
+			assert.Equal(t, u, got)
+		}
+	})
+	t.Run("flattened", func(t *testing.T) {
+		d := directionDoc{DirectionStamp: DirectionStamp{Version: 1}, Title: "draft"}
+		assert.Equal(t, map[string]any{"version": 1, "title": "draft"}, ToMap(d, "json", false, false))
+
+		got := d
+		require.NoError(t, New().FromMap(map[string]any{"version": 2, "title": "final"}, &got))
+		assert.Equal(t, directionDoc{DirectionStamp: DirectionStamp{Version: 1}, Title: "final"}, got)
+
+		got = d
+		assert.ErrorIs(t, ApplyJSONPatch(&got, []Op{{Op: "replace", Path: "/version", Value: 2}}), ErrReadOnly)
+		assert.ErrorIs(t, Update(&got, map[string]func(any) any{"version": func(any) any { return 2 }}), ErrReadOnly)
+		assert.ErrorIs(t, FromArgs(&got, []string{"version=2"}), ErrReadOnly)
+		assert.Equal(t, d, got)
+
+		imp, err := New().NewImporter([]string{"version", "title"}, directionDoc{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"version"}, imp.Report().Unmatched)
+	})
+}
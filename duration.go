@@ -0,0 +1,124 @@
+package tagops
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DurationFormat defines how time.Duration values are emitted by ToMap.
+type DurationFormat int
+
+const (
+	// DurationAsIs emits time.Duration values as is, which is int64
+	// nanoseconds for most encoders.  This is the default.
+	DurationAsIs DurationFormat = iota
+	// DurationString emits strings, i.e. "1h30m0s".  Tag option "durstr".
+	DurationString
+	// DurationSeconds emits float64 seconds.  Tag option "seconds".
+	DurationSeconds
+	// DurationMillis emits int64 milliseconds.  Tag option "millis".
+	DurationMillis
+)
+
+// duration tag options.
+const (
+	fDurString  = "durstr"
+	fDurSeconds = "seconds"
+	fDurMillis  = "millis"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// FormatDuration returns an Option that sets the format of time.Duration
+// values.  The format may be overridden per field with the tag option, i.e.
+// `json:"timeout,seconds"`.  FromMap parses the values in the same format back.
+func FormatDuration(f DurationFormat) Option {
+	return func(m *Mapper) {
+		m.durFormat = f
+	}
+}
+
+// durationFormat returns the duration format, taking into account the tag
+// options opts.
+func (m Mapper) durationFormat(opts []string) DurationFormat {
+	f := m.durFormat
+	for _, o := range opts {
+		switch o {
+		case fDurString:
+			f = DurationString
+		case fDurSeconds:
+			f = DurationSeconds
+		case fDurMillis:
+			f = DurationMillis
+		}
+	}
+	return f
+}
+
+// formatDuration formats the duration d according to the duration format.  It
+// returns false if the format is DurationAsIs.
+func (m Mapper) formatDuration(d time.Duration, opts []string) (any, bool) {
+	switch m.durationFormat(opts) {
+	case DurationString:
+		return d.String(), true
+	case DurationSeconds:
+		return d.Seconds(), true
+	case DurationMillis:
+		return d.Milliseconds(), true
+	default:
+		return nil, false
+	}
+}
+
+// parseDuration parses the map value sv formatted by formatDuration.  It
+// returns false if sv is a time.Duration, or the format is DurationAsIs.
+func (m Mapper) parseDuration(sv any, opts []string) (time.Duration, bool, error) {
+	if _, ok := sv.(time.Duration); ok {
+		return 0, false, nil
+	}
+	f := m.durationFormat(opts)
+	if f == DurationAsIs {
+		return 0, false, nil
+	}
+	if s, ok := sv.(string); ok {
+		if f == DurationString {
+			d, err := time.ParseDuration(s)
+			return d, true, err
+		}
+		// numeric string, i.e. from ToKV.
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			d, err := time.ParseDuration(s)
+			return d, true, err
+		}
+		sv = n
+	}
+	switch f {
+	case DurationSeconds:
+		var secs float64
+		if err := toFloat64(&secs, sv); err != nil {
+			return 0, true, fmt.Errorf("duration: %w", err)
+		}
+		return time.Duration(math.Round(secs * float64(time.Second))), true, nil
+	case DurationMillis:
+		var ms int64
+		if err := toInt64(&ms, sv); err != nil {
+			return 0, true, fmt.Errorf("duration: %w", err)
+		}
+		return time.Duration(ms) * time.Millisecond, true, nil
+	default:
+		return 0, true, fmt.Errorf("duration: expected string, got %T", sv)
+	}
+}
+
+// toFloat64 converts the numeric value v to float64.
+func toFloat64(f *float64, v any) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !isNumber(rv.Kind()) {
+		return fmt.Errorf("expected number, got %T", v)
+	}
+	return convertNumber(reflect.ValueOf(f).Elem(), rv)
+}
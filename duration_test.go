@@ -0,0 +1,75 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDuration(t *testing.T) {
+	type Config struct {
+		Timeout  time.Duration `json:"timeout"`
+		Interval time.Duration `json:"interval,seconds"`
+		Delay    time.Duration `json:"delay,millis"`
+		TTL      time.Duration `json:"ttl,durstr"`
+	}
+	d := 90*time.Minute + 500*time.Millisecond
+	cfg := Config{Timeout: d, Interval: d, Delay: d, TTL: d}
+
+	tests := []struct {
+		name string
+		m    Mapper
+		want map[string]any
+	}{
+		{
+			name: "as is, tag options",
+			m:    New(),
+			want: map[string]any{
+				"timeout":  d,
+				"interval": 5400.5,
+				"delay":    int64(5400500),
+				"ttl":      "1h30m0.5s",
+			},
+		},
+		{
+			name: "string",
+			m:    New(FormatDuration(DurationString)),
+			want: map[string]any{
+				"timeout":  "1h30m0.5s",
+				"interval": 5400.5,
+				"delay":    int64(5400500),
+				"ttl":      "1h30m0.5s",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.m.ToMap(cfg))
+		})
+	}
+	t.Run("round trip", func(t *testing.T) {
+		for _, f := range []DurationFormat{DurationAsIs, DurationString, DurationSeconds, DurationMillis} {
+			m := New(FormatDuration(f))
+			var got Config
+			assert.NoError(t, m.FromMap(m.ToMap(cfg), &got))
+			assert.Equal(t, cfg, got)
+		}
+	})
+	t.Run("parse json numbers and strings", func(t *testing.T) {
+		var got Config
+		err := New().FromMap(map[string]any{
+			"interval": "2s",
+			"delay":    float64(1500),
+			"ttl":      "1m",
+		}, &got)
+		assert.NoError(t, err)
+		assert.Equal(t, Config{Interval: 2 * time.Second, Delay: 1500 * time.Millisecond, TTL: time.Minute}, got)
+	})
+	t.Run("parse errors", func(t *testing.T) {
+		var got Config
+		assert.Error(t, New().FromMap(map[string]any{"ttl": "forever"}, &got))
+		assert.Error(t, New().FromMap(map[string]any{"ttl": 5}, &got))
+		assert.Error(t, New().FromMap(map[string]any{"delay": true}, &got))
+	})
+}
@@ -0,0 +1,77 @@
+package tagops
+
+import (
+	"maps"
+	"reflect"
+)
+
+// maxDepth is the maximum depth of the nested dynamic maps.
+const maxDepth = 1000
+
+// isStringMap returns true if t is a map with string keys.
+func isStringMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+// mapToMap converts the map with string keys v to a map, applying the key
+// function, flattening and omitempty rules.  path is the path of v from the
+// root value.
+func (m Mapper) mapToMap(st *state, v reflect.Value, path string) map[string]any {
+	out := make(map[string]any, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		if st.done() {
+			return out
+		}
+		name := iter.Key().String()
+		key := name
+		if m.keyFunc != nil {
+			key = m.keyFunc(name)
+		}
+		fpath := joinPath(path, name)
+
+		val := iter.Value()
+		for val.Kind() == reflect.Interface && !val.IsNil() {
+			val = val.Elem()
+		}
+		if m.Omitempty && isEmpty(val) {
+			continue
+		}
+		if val.Kind() == reflect.Interface {
+			// nil interface
+			out[key] = nil
+			continue
+		}
+
+		var nested map[string]any
+		switch {
+		case isNested(val.Type()):
+			nested = m.toMap(st, val, fpath)
+		case isStringMap(val.Type()):
+			if st.depth >= maxDepth {
+				st.fail(fpath, ErrTooDeep)
+				continue
+			}
+			st.depth++
+			nested = m.mapToMap(st, val, fpath)
+			st.depth--
+		}
+		if nested != nil {
+			if m.Flatten {
+				maps.Copy(out, nested)
+			} else {
+				out[key] = nested
+			}
+			continue
+		}
+
+		if res, ok := m.leaf(st, val, nil, fpath); ok {
+			out[key] = res
+		}
+	}
+	if m.afterStruct != nil {
+		m.afterStruct(st.ctx, out)
+	}
+	return out
+}
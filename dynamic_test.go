@@ -0,0 +1,95 @@
+package tagops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapper_ToMap_map(t *testing.T) {
+	type Address struct {
+		City string `json:"city,omitempty"`
+	}
+	src := map[string]any{
+		"Name":    "John",
+		"Age":     0,
+		"Note":    nil,
+		"Address": map[string]any{"Street": "123 Main St", "ZIP": ""},
+		"Office":  Address{City: "Anytown"},
+	}
+	tests := []struct {
+		name string
+		m    Mapper
+		a    any
+		want map[string]any
+	}{
+		{
+			name: "pass through",
+			m:    New(),
+			a:    src,
+			want: map[string]any{
+				"Name":    "John",
+				"Age":     0,
+				"Note":    nil,
+				"Address": map[string]any{"Street": "123 Main St", "ZIP": ""},
+				"Office":  map[string]any{"city": "Anytown"},
+			},
+		},
+		{
+			name: "key func, omitempty",
+			m:    New(KeyFunc(strings.ToLower), Omitempty()),
+			a:    src,
+			want: map[string]any{
+				"name":    "John",
+				"address": map[string]any{"street": "123 Main St"},
+				"office":  map[string]any{"city": "Anytown"},
+			},
+		},
+		{
+			name: "flatten",
+			m:    New(KeyFunc(strings.ToLower), Flatten()),
+			a:    src,
+			want: map[string]any{
+				"name":   "John",
+				"age":    0,
+				"note":   nil,
+				"street": "123 Main St",
+				"zip":    "",
+				"city":   "Anytown",
+			},
+		},
+		{
+			name: "typed map",
+			m:    New(KeyFunc(strings.ToUpper)),
+			a:    map[string]int{"a": 1},
+			want: map[string]any{"A": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.ToMapE(tt.a)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("int keys", func(t *testing.T) {
+		_, err := New().ToMapE(map[int]any{1: 1})
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+}
+
+func TestKeyFunc(t *testing.T) {
+	type S struct {
+		FirstName string `json:"first"`
+		LastName  string
+		Email     string `json:",omitempty"`
+		Skip      string `json:"-"`
+	}
+	m := New(KeyFunc(strings.ToLower))
+	assert.Equal(t, map[string]any{"first": "", "lastname": "", "email": ""}, m.ToMap(S{}))
+
+	var s S
+	assert.NoError(t, m.FromMap(map[string]any{"lastname": "Doe", "LastName": "x"}, &s))
+	assert.Equal(t, "Doe", s.LastName)
+}
@@ -0,0 +1,101 @@
+package tagops
+
+import (
+	"reflect"
+)
+
+// document returns the map of the struct a, for the encoders of the text
+// formats, with the structs in slices, arrays and maps converted to maps with
+// the same Mapper configuration, and the order of the keys, if FieldOrder is
+// set.
+func (m Mapper) document(a any) (map[string]any, *keyOrder, error) {
+	mp, err := m.ToMapE(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.plainMap(mp); err != nil {
+		return nil, nil, err
+	}
+	var ord *keyOrder
+	if m.fieldOrder {
+		if v, err := derefStruct(reflect.ValueOf(a)); err == nil {
+			ord = m.keyOrder(v.Type())
+		}
+	}
+	return mp, ord, nil
+}
+
+// plainMap replaces the values of mp with their plain form, see plain.
+func (m Mapper) plainMap(mp map[string]any) error {
+	for k, v := range mp {
+		pv, err := m.plain(v)
+		if err != nil {
+			return err
+		}
+		mp[k] = pv
+	}
+	return nil
+}
+
+// plain returns v with the structs, that are kept as values by ToMap, i.e. in
+// slices or maps, converted to maps.  Values without structs are returned as
+// is.
+func (m Mapper) plain(v any) (any, error) {
+	if mp, ok := v.(map[string]any); ok {
+		return mp, m.plainMap(mp)
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !hasStructs(rv.Type()) {
+		return v, nil
+	}
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return m.plain(rv.Elem().Interface())
+	case reflect.Struct:
+		return m.ToMapE(v)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		out := make([]any, rv.Len())
+		for i := range rv.Len() {
+			pv, err := m.plain(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			out[i] = pv
+		}
+		return out, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		out := make(map[string]any, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			pv, err := m.plain(it.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			out[it.Key().String()] = pv
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// hasStructs returns true if the values of type t may hold structs that are
+// converted to maps, see isNested.
+func hasStructs(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasStructs(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && hasStructs(t.Elem())
+	case reflect.Interface:
+		return true
+	}
+	return isNested(t)
+}
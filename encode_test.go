@@ -0,0 +1,187 @@
+package tagops
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encodeItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+type encodeOwner struct {
+	Name  string `json:"name"`
+	Email string `json:"e-mail"`
+}
+
+type encodeDoc struct {
+	Title   string            `json:"title"`
+	Score   float64           `json:"score"`
+	Created time.Time         `json:"created"`
+	Tags    []string          `json:"tags"`
+	Owner   encodeOwner       `json:"owner"`
+	Items   []encodeItem      `json:"items"`
+	Labels  map[string]string `json:"labels"`
+	Note    *string           `json:"note"`
+	Data    []byte            `json:"data"`
+}
+
+var encodeSample = encodeDoc{
+	Title:   "Say \"hi\"\n",
+	Score:   2,
+	Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	Tags:    []string{"a", "b"},
+	Owner:   encodeOwner{Name: "Ann", Email: "ann@example.com"},
+	Items:   []encodeItem{{SKU: "x", Qty: 1}, {SKU: "y", Qty: 2}},
+	Labels:  map[string]string{"env": "prod"},
+	Data:    []byte("hi"),
+}
+
+func TestEncodeTOML(t *testing.T) {
+	t.Run("sorted", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeTOML(&buf, encodeSample))
+		want := `created = 2024-01-02T03:04:05Z
+data = "aGk="
+labels = {env = "prod"}
+score = 2.0
+tags = ["a", "b"]
+title = "Say \"hi\"\n"
+
+[[items]]
+qty = 1
+sku = "x"
+
+[[items]]
+qty = 2
+sku = "y"
+
+[owner]
+e-mail = "ann@example.com"
+name = "Ann"
+`
+		assert.Equal(t, want, buf.String())
+	})
+	t.Run("field order and flatten", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeTOML(&buf, encodeSample, FieldOrder(), Flatten()))
+		want := `title = "Say \"hi\"\n"
+score = 2.0
+created = 2024-01-02T03:04:05Z
+tags = ["a", "b"]
+name = "Ann"
+e-mail = "ann@example.com"
+labels = {env = "prod"}
+data = "aGk="
+
+[[items]]
+sku = "x"
+qty = 1
+
+[[items]]
+sku = "y"
+qty = 2
+`
+		assert.Equal(t, want, buf.String())
+	})
+	t.Run("nested tables", func(t *testing.T) {
+		type inner struct {
+			V int `json:"v"`
+		}
+		type middle struct {
+			In inner `json:"in"`
+		}
+		type outer struct {
+			Mid   middle   `json:"mid"`
+			Empty struct{} `json:"empty"`
+		}
+		var buf bytes.Buffer
+		require.NoError(t, EncodeTOML(&buf, outer{Mid: middle{In: inner{V: 1}}}))
+		assert.Equal(t, "[empty]\n\n[mid.in]\nv = 1\n", buf.String())
+	})
+	t.Run("values", func(t *testing.T) {
+		tests := []struct {
+			v    any
+			want string
+		}{
+			{math.Inf(-1), "-inf"},
+			{math.NaN(), "nan"},
+			{1.5e300, "1.5e+300"},
+			{"\x01", `"\u0001"`},
+			{[]int{1, 2}, "[1, 2]"},
+		}
+		for _, tt := range tests {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			require.NoError(t, tomlValue(w, tt.v))
+			require.NoError(t, w.Flush())
+			assert.Equal(t, tt.want, buf.String())
+		}
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		type doc struct {
+			C complex128 `json:"c"`
+		}
+		var buf bytes.Buffer
+		err := EncodeTOML(&buf, doc{C: 1i})
+		assert.ErrorIs(t, err, ErrUnsupportedKind)
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "c", fe.Path)
+	})
+}
+
+func TestEncodeYAML(t *testing.T) {
+	t.Run("sorted", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeYAML(&buf, encodeSample))
+		want := `created: 2024-01-02T03:04:05Z
+data: aGk=
+items:
+  - qty: 1
+    sku: x
+  - qty: 2
+    sku: "y"
+labels:
+  env: prod
+note: null
+owner:
+  e-mail: ann@example.com
+  name: Ann
+score: 2
+tags:
+  - a
+  - b
+title: |
+  Say "hi"
+`
+		assert.Equal(t, want, buf.String())
+	})
+	t.Run("field order", func(t *testing.T) {
+		type doc struct {
+			Title string       `json:"title"`
+			Owner encodeOwner  `json:"owner"`
+			Items []encodeItem `json:"items"`
+			Data  []byte       `json:"data"`
+		}
+		var buf bytes.Buffer
+		require.NoError(t, EncodeYAML(&buf, doc{Title: "t", Owner: encodeOwner{Name: "Ann"}, Items: []encodeItem{{SKU: "x"}}}, FieldOrder()))
+		want := `title: t
+owner:
+  name: Ann
+  e-mail: ""
+items:
+  - sku: x
+    qty: 0
+data: null
+`
+		assert.Equal(t, want, buf.String())
+	})
+}
@@ -0,0 +1,103 @@
+package tagops
+
+import (
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// registerGob registers the container types of the encoder-safe values with
+// encoding/gob.
+var registerGob = sync.OnceFunc(func() {
+	gob.Register([]any{})
+	gob.Register(map[string]any{})
+})
+
+// EncoderSafe returns an Option that restricts the ToMap values to the types
+// that binary encoders, such as encoding/gob and msgpack, support without
+// registration of custom types or extensions:
+//
+//   - bool, int64, uint64, float64, string and []byte for the values of the
+//     corresponding kinds, including named types, i.e. enums;
+//   - string for time.Time in RFC 3339 format, unless FormatTime is set, and
+//     for complex numbers and other leaf structs, formatted with fmt;
+//   - []any for slices and arrays, and map[string]any for maps and structs
+//     within them;
+//   - nil for nil pointers and interfaces, other pointers are dereferenced.
+//
+// Fields of func, chan and unsafe pointer kinds are omitted.  The option
+// registers []any and map[string]any with encoding/gob.
+func EncoderSafe() Option {
+	registerGob()
+	return func(m *Mapper) {
+		m.encoderSafe = true
+	}
+}
+
+// safeValue returns the value v reduced to the encoder-safe types.  Structs
+// within slices and maps are converted to maps.  It returns false if the value
+// should be omitted.
+func (m Mapper) safeValue(v reflect.Value) (any, bool) {
+	if !v.IsValid() {
+		return nil, true
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex()), true
+	case reflect.String:
+		return v.String(), true
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, false
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, true
+		}
+		return m.safeValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, true
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return b, true
+		}
+		out := make([]any, 0, v.Len())
+		for i := range v.Len() {
+			if val, ok := m.safeValue(v.Index(i)); ok {
+				out = append(out, val)
+			}
+		}
+		return out, true
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, true
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if val, ok := m.safeValue(iter.Value()); ok {
+				out[fmt.Sprint(iter.Key().Interface())] = val
+			}
+		}
+		return out, true
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).Format(time.RFC3339Nano), true
+		}
+		if isNested(v.Type()) {
+			return m.ToMap(v.Interface()), true
+		}
+	}
+	return fmt.Sprint(v.Interface()), true
+}
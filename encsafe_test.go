@@ -0,0 +1,74 @@
+package tagops
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderSafe(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku"`
+	}
+	type Order struct {
+		ID       int               `json:"id"`
+		Level    level             `json:"level"`
+		Created  time.Time         `json:"created"`
+		Timeout  time.Duration     `json:"timeout"`
+		Total    *big.Int          `json:"total"`
+		Items    []Item            `json:"items"`
+		Counts   map[int]uint8     `json:"counts"`
+		Raw      []byte            `json:"raw"`
+		Note     *string           `json:"note"`
+		Z        complex64         `json:"z"`
+		Callback func()            `json:"callback"`
+		Labels   map[string]string `json:"labels"`
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	o := Order{
+		ID:       1,
+		Level:    info,
+		Created:  created,
+		Timeout:  time.Second,
+		Total:    big.NewInt(42),
+		Items:    []Item{{"a"}, {"b"}},
+		Counts:   map[int]uint8{1: 2},
+		Raw:      []byte("x"),
+		Z:        complex(1, 2),
+		Callback: func() {},
+	}
+
+	got, err := New(EncoderSafe()).ToMapE(o)
+	require.NoError(t, err)
+	want := map[string]any{
+		"id":      int64(1),
+		"level":   uint64(info),
+		"created": "2024-01-02T03:04:05Z",
+		"timeout": int64(time.Second),
+		"total":   "42",
+		"items":   []any{map[string]any{"sku": "a"}, map[string]any{"sku": "b"}},
+		"counts":  map[string]any{"1": uint64(2)},
+		"raw":     []byte("x"),
+		"note":    nil,
+		"z":       "(1+2i)",
+		"labels":  nil,
+	}
+	assert.Equal(t, want, got)
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(got))
+	var decoded map[string]any
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	assert.Equal(t, "2024-01-02T03:04:05Z", decoded["created"])
+
+	t.Run("time format is honored", func(t *testing.T) {
+		got, err := New(EncoderSafe(), FormatTime(TimeUnix)).ToMapE(o)
+		require.NoError(t, err)
+		assert.Equal(t, created.Unix(), got["created"])
+	})
+}
@@ -0,0 +1,86 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// enum tag option.
+const fEnum = "enum"
+
+// enums holds registered enum values, keyed by type.
+var enums sync.Map // map[reflect.Type]map[string]any
+
+// RegisterEnum registers the string names of the enum type T values, so that
+// FromMap can parse the names back into T.  Registered names are also used by
+// ToMap for types that don't implement fmt.Stringer, when EnumStrings is set.
+func RegisterEnum[T comparable](values map[string]T) {
+	names := make(map[string]any, len(values))
+	for name, v := range values {
+		names[name] = v
+	}
+	enums.Store(reflect.TypeFor[T](), names)
+}
+
+// EnumStrings returns an Option that makes ToMap emit integer enums as their
+// names: values of integer types that implement fmt.Stringer or that are
+// registered with RegisterEnum.  It can be enabled per field with the tag
+// option "enum", i.e. `json:"status,enum"`.
+func EnumStrings() Option {
+	return func(m *Mapper) {
+		m.enumStrings = true
+	}
+}
+
+// isInteger returns true if the kind k is an integer kind.
+func isInteger(k reflect.Kind) bool {
+	return isNumber(k) && k != reflect.Float32 && k != reflect.Float64
+}
+
+// formatEnum returns the name of the enum value fv.  It returns false if enum
+// names are not enabled, or fv is not an enum.
+func (m Mapper) formatEnum(fv reflect.Value, opts []string) (string, bool) {
+	if !isInteger(fv.Kind()) || !(m.enumStrings || slices.Contains(opts, fEnum)) {
+		return "", false
+	}
+	if s, ok := fv.Interface().(fmt.Stringer); ok {
+		return s.String(), true
+	}
+	names, ok := enums.Load(fv.Type())
+	if !ok {
+		return "", false
+	}
+	v := fv.Interface()
+	for name, ev := range names.(map[string]any) {
+		if ev == v {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// parseEnum parses the name sv of a registered enum into fv.  It returns false
+// if the type of fv is not registered, or sv is not a string.
+func parseEnum(fv reflect.Value, sv any) (bool, error) {
+	s, ok := sv.(string)
+	if !ok {
+		return false, nil
+	}
+	names, ok := enums.Load(fv.Type())
+	if !ok {
+		return false, nil
+	}
+	v, ok := names.(map[string]any)[s]
+	if !ok {
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			// numeric strings are left for ParseStrings.
+			return false, nil
+		}
+		return true, fmt.Errorf("unknown %s value: %q", fv.Type(), s)
+	}
+	fv.Set(reflect.ValueOf(v))
+	return true, nil
+}
@@ -0,0 +1,61 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// color implements fmt.Stringer.
+type color int
+
+const (
+	red color = iota
+	green
+)
+
+func (c color) String() string {
+	return [...]string{"red", "green"}[c]
+}
+
+// level does not implement fmt.Stringer.
+type level uint8
+
+const (
+	debug level = iota + 1
+	info
+)
+
+func init() {
+	RegisterEnum(map[string]color{"red": red, "green": green})
+	RegisterEnum(map[string]level{"debug": debug, "info": info})
+}
+
+func TestEnumStrings(t *testing.T) {
+	type S struct {
+		Color    color `json:"color"`
+		Level    level `json:"level"`
+		Fallback level `json:"fallback"`
+		Tagged   color `json:"tagged,enum"`
+	}
+	s := S{Color: green, Level: info, Fallback: 42, Tagged: green}
+	t.Run("disabled", func(t *testing.T) {
+		got := New().ToMap(s)
+		assert.Equal(t, map[string]any{"color": green, "level": info, "fallback": level(42), "tagged": "green"}, got)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		got := New(EnumStrings()).ToMap(s)
+		assert.Equal(t, map[string]any{"color": "green", "level": "info", "fallback": level(42), "tagged": "green"}, got)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		var got S
+		m := New(EnumStrings())
+		assert.NoError(t, m.FromMap(m.ToMap(s), &got))
+		assert.Equal(t, s, got)
+	})
+	t.Run("unknown name", func(t *testing.T) {
+		var got S
+		err := New().FromMap(map[string]any{"level": "trace"}, &got)
+		assert.EqualError(t, err, `Level: unknown tagops.level value: "trace"`)
+	})
+}
@@ -0,0 +1,115 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrSkip indicates that the field should be skipped.  A ConvertFunc
+	// may return it to omit the field from the output.
+	ErrSkip = errors.New("skip")
+	// ErrNotStruct is returned when the value is not a struct or a pointer
+	// to struct.
+	ErrNotStruct = errors.New("not a struct")
+	// ErrUnsupportedKind is returned when the field is of the kind that can
+	// not be mapped.
+	ErrUnsupportedKind = errors.New("unsupported kind")
+	// ErrNotSlice is returned when the value is not a slice or an array.
+	ErrNotSlice = errors.New("not a slice")
+	// ErrFieldNotFound is returned when there's no field with the requested
+	// tag name.
+	ErrFieldNotFound = errors.New("field not found")
+	// ErrKeyConflict is returned when several fields at the same depth map
+	// to the same key, see StrictConflicts.
+	ErrKeyConflict = errors.New("key conflict")
+	// ErrTypeMismatch is returned when the value is not of the type that the
+	// Binding is bound to, or when the fields of the elements have different
+	// types.
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrNonFinite is returned when the float value is NaN or infinite, that
+	// can not be represented in most encodings, i.e. JSON.
+	ErrNonFinite = errors.New("non-finite float")
+	// ErrTooDeep is returned when the nested maps exceed the maximum depth,
+	// i.e. when the map contains itself.
+	ErrTooDeep = errors.New("nesting too deep")
+	// ErrInvalidFieldName is returned when the struct field has an empty or
+	// malformed name, i.e. in a hand-made reflect.StructField.
+	ErrInvalidFieldName = errors.New("invalid field name")
+)
+
+// FieldError is an error that occurred while mapping the field at Path.  Use
+// errors.As to get it from the error returned by ToMapE or FromMap.
+type FieldError struct {
+	// Path is the dot-separated path of Go field names from the root
+	// struct, i.e. "Address.ZIP".
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// state holds the state of a single conversion.
+type state struct {
+	ctx  context.Context
+	errs []error
+	// aborted is set to the context error, if the conversion was
+	// cancelled.
+	aborted error
+	// depth is the current depth of the nested dynamic maps.
+	depth int
+}
+
+// newState returns a new conversion state for the context ctx.
+func newState(ctx context.Context) *state {
+	return &state{ctx: ctx}
+}
+
+// done returns true if the conversion should stop because the context is
+// done.
+func (st *state) done() bool {
+	if st.aborted != nil {
+		return true
+	}
+	if err := st.ctx.Err(); err != nil {
+		st.aborted = err
+		return true
+	}
+	return false
+}
+
+// fail records the error err for the field at path.
+func (st *state) fail(path string, err error) {
+	st.errs = append(st.errs, &FieldError{Path: path, Err: err})
+}
+
+// err returns the first recorded error, or, if collect is true, all errors
+// joined.
+func (st *state) err(collect bool) error {
+	if st.aborted != nil {
+		return st.aborted
+	}
+	if len(st.errs) == 0 {
+		return nil
+	}
+	if collect {
+		return errors.Join(st.errs...)
+	}
+	return st.errs[0]
+}
+
+// CollectErrors returns an Option that enables the error accumulation mode.
+// In this mode, ToMapE and FromMap do not stop at the first error, and
+// return all errors joined with errors.Join.  Each error carries the path of
+// the field that caused it.
+func CollectErrors() Option {
+	return func(m *Mapper) {
+		m.collectErrors = true
+	}
+}
@@ -0,0 +1,177 @@
+package tagops
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// broken is a test type, which converter always fails.
+type broken struct{ X int }
+
+func init() {
+	RegisterConverter(reflect.TypeOf(broken{}), reflect.TypeOf(""), func(v any) (any, error) {
+		return nil, errors.New("broken")
+	})
+}
+
+type errAddress struct {
+	Street string `json:"street"`
+	ZIP    int    `json:"zip"`
+	Geo    broken `json:"geo"`
+}
+
+type errPerson struct {
+	Name    string     `json:"name"`
+	Age     int        `json:"age"`
+	Extra   broken     `json:"extra"`
+	Address errAddress `json:"address"`
+}
+
+func TestMapper_ToMapE(t *testing.T) {
+	t.Run("first error", func(t *testing.T) {
+		mp, err := New().ToMapE(errPerson{Name: "John", Extra: broken{1}})
+		assert.Equal(t, "Extra: convert tagops.broken: broken", err.Error())
+		assert.Equal(t, broken{1}, mp["extra"])
+		assert.Equal(t, "John", mp["name"])
+	})
+	t.Run("collect", func(t *testing.T) {
+		_, err := New(CollectErrors()).ToMapE(errPerson{})
+		assert.EqualError(t, err, "Extra: convert tagops.broken: broken\nAddress.Geo: convert tagops.broken: broken")
+	})
+	t.Run("no errors", func(t *testing.T) {
+		mp, err := New().ToMapE(struct {
+			A int `json:"a"`
+		}{1})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": 1}, mp)
+	})
+}
+
+func TestMapper_FromMap_errors(t *testing.T) {
+	src := map[string]any{
+		"name":    1,
+		"age":     "x",
+		"address": map[string]any{"zip": "y"},
+	}
+	t.Run("first error", func(t *testing.T) {
+		var p errPerson
+		err := New().FromMap(src, &p)
+		var fe *FieldError
+		assert.ErrorAs(t, err, &fe)
+		assert.Equal(t, "Name", fe.Path)
+	})
+	t.Run("collect", func(t *testing.T) {
+		var p errPerson
+		err := New(CollectErrors()).FromMap(src, &p)
+		assert.EqualError(t, err, "Name: cannot assign int to string\nAge: cannot assign string to int\nAddress.ZIP: cannot assign string to int")
+	})
+}
+
+// skipped is a test type, which converter returns ErrSkip.
+type skipped int
+
+func init() {
+	RegisterConverter(reflect.TypeOf(skipped(0)), reflect.TypeOf(""), func(v any) (any, error) {
+		return nil, ErrSkip
+	})
+	RegisterConverter(reflect.TypeOf(""), reflect.TypeOf(skipped(0)), func(v any) (any, error) {
+		return nil, ErrSkip
+	})
+}
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("ErrNotStruct ToMapE", func(t *testing.T) {
+		_, err := New().ToMapE(42)
+		assert.ErrorIs(t, err, ErrNotStruct)
+		_, err = New().ToMapE(nil)
+		assert.ErrorIs(t, err, ErrNotStruct)
+		_, err = New().ToMapE((*struct{})(nil))
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+	t.Run("ErrNotStruct FromMap", func(t *testing.T) {
+		err := New().FromMap(map[string]any{}, &[]int{})
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+	t.Run("ErrUnsupportedKind", func(t *testing.T) {
+		var s struct {
+			C chan int `json:"c"`
+		}
+		err := New().FromMap(map[string]any{"c": make(chan int)}, &s)
+		assert.ErrorIs(t, err, ErrUnsupportedKind)
+		var fe *FieldError
+		assert.ErrorAs(t, err, &fe)
+		assert.Equal(t, "C", fe.Path)
+	})
+	t.Run("ErrSkip from converter", func(t *testing.T) {
+		type S struct {
+			A int     `json:"a"`
+			B skipped `json:"b"`
+		}
+		mp, err := New().ToMapE(S{A: 1, B: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"a": 1}, mp)
+
+		s := S{B: 2}
+		assert.NoError(t, New().FromMap(map[string]any{"b": "x"}, &s))
+		assert.Equal(t, skipped(2), s.B)
+	})
+	t.Run("ErrInvalidFieldName", func(t *testing.T) {
+		type S struct {
+			A int `json:"a"`
+			B int `json:"b"`
+		}
+		m := New()
+		// struct types can not have malformed names, so break the plan.
+		m.plan(reflect.TypeOf(S{})).fields[1].field.Name = "\xff"
+
+		mp, err := m.ToMapE(S{A: 1, B: 2})
+		assert.ErrorIs(t, err, ErrInvalidFieldName)
+		var fe *FieldError
+		assert.ErrorAs(t, err, &fe)
+		assert.Equal(t, "\xff", fe.Path)
+		assert.Equal(t, map[string]any{"a": 1}, mp)
+
+		var s S
+		err = m.FromMap(map[string]any{"a": 1, "b": 2}, &s)
+		assert.ErrorIs(t, err, ErrInvalidFieldName)
+		assert.Equal(t, S{A: 1}, s)
+	})
+	t.Run("ErrNonFinite", func(t *testing.T) {
+		type celsius float32
+		type S struct {
+			A float64 `json:"a"`
+			B celsius `json:"b"`
+			C float64 `json:"c"`
+		}
+		mp, err := New(CollectErrors()).ToMapE(S{A: math.NaN(), B: celsius(math.Inf(-1)), C: 1})
+		assert.ErrorIs(t, err, ErrNonFinite)
+		var fe *FieldError
+		assert.ErrorAs(t, err, &fe)
+		assert.Equal(t, "A", fe.Path)
+		assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+		assert.Equal(t, 1.0, mp["c"], "the value is kept")
+
+		_, err = Values(S{A: math.Inf(1)}, "json")
+		assert.ErrorIs(t, err, ErrNonFinite)
+	})
+	t.Run("ErrTooDeep", func(t *testing.T) {
+		cyclic := map[string]any{"a": 1}
+		cyclic["self"] = cyclic
+		_, err := New().ToMapE(cyclic)
+		assert.ErrorIs(t, err, ErrTooDeep)
+		var fe *FieldError
+		assert.ErrorAs(t, err, &fe)
+		assert.Equal(t, maxDepth+1, strings.Count(fe.Path, "self"))
+	})
+	t.Run("unexported only", func(t *testing.T) {
+		type S struct{ a, b int }
+		mp, err := New().ToMapE(S{})
+		assert.NoError(t, err)
+		assert.Empty(t, mp)
+	})
+}
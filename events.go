@@ -0,0 +1,81 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ChangeEvent is the change of a single field, i.e. for publishing as the
+// change data capture event.
+type ChangeEvent struct {
+	// Entity is the type name of the struct, i.e. "User".
+	Entity string `json:"entity"`
+	// Field is the dot-separated key path of the field, see Change.Path.
+	Field string `json:"field"`
+	// Old and New are the values before and after the change, see Change.
+	Old any `json:"old"`
+	New any `json:"new"`
+	// Time is the time when the events were produced, it is the same for all
+	// events of one call.
+	Time time.Time `json:"time"`
+}
+
+// Events returns the change events between the structs old and new, with the
+// field names from the tag topicTag, see Mapper.Events.  It returns nil on
+// error.
+func Events(old, new any, topicTag string) []ChangeEvent {
+	ee, _ := New(Tag(topicTag)).Events(old, new)
+	return ee
+}
+
+// Events returns the change event for each change between the structs old
+// and new, as returned by Diff, ordered by field.  Either old or new may be
+// nil, for the entity that is created or deleted, then all fields are
+// reported as changed from or to nil.  The entity is the type name of new, or
+// of old if new is nil.
+func (m Mapper) Events(old, new any) ([]ChangeEvent, error) {
+	om, err := m.eventMap(old)
+	if err != nil {
+		return nil, fmt.Errorf("old: %w", err)
+	}
+	nm, err := m.eventMap(new)
+	if err != nil {
+		return nil, fmt.Errorf("new: %w", err)
+	}
+	var changes []Change
+	diffMaps(&changes, "", om, nm)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	entity := typeName(new)
+	if entity == "" {
+		entity = typeName(old)
+	}
+	now := time.Now()
+	ee := make([]ChangeEvent, len(changes))
+	for i, c := range changes {
+		ee[i] = ChangeEvent{Entity: entity, Field: c.Path, Old: c.Old, New: c.New, Time: now}
+	}
+	return ee, nil
+}
+
+// eventMap returns the map of a, or nil if a is nil.
+func (m Mapper) eventMap(a any) (map[string]any, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return m.ToMapE(a)
+}
+
+// typeName returns the name of the type of a, dereferencing pointers.
+func typeName(a any) string {
+	t := reflect.TypeOf(a)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name()
+}
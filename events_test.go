@@ -0,0 +1,58 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type evUser struct {
+	ID    int    `json:"id" event:"user_id"`
+	Name  string `json:"name" event:"full_name"`
+	Email string `json:"email" event:"-"`
+}
+
+func TestEvents(t *testing.T) {
+	before := time.Now()
+	old := evUser{ID: 1, Name: "Bob", Email: "bob@example.com"}
+	upd := evUser{ID: 1, Name: "Robert", Email: "robert@example.com"}
+
+	ee := Events(old, &upd, "event")
+	require.Len(t, ee, 1)
+	assert.Equal(t, "evUser", ee[0].Entity)
+	assert.Equal(t, "full_name", ee[0].Field)
+	assert.Equal(t, "Bob", ee[0].Old)
+	assert.Equal(t, "Robert", ee[0].New)
+	assert.False(t, ee[0].Time.Before(before))
+
+	ee = Events(old, upd, "json")
+	require.Len(t, ee, 2)
+	assert.Equal(t, []string{"email", "name"}, []string{ee[0].Field, ee[1].Field})
+	assert.Equal(t, ee[0].Time, ee[1].Time)
+
+	assert.Nil(t, Events(old, old, "json"))
+	assert.Nil(t, Events(42, old, "json"))
+}
+
+func TestMapper_Events_createDelete(t *testing.T) {
+	m := New(Tag("event"))
+	u := evUser{ID: 1, Name: "Bob"}
+
+	created, err := m.Events(nil, u)
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	assert.Equal(t, ChangeEvent{Entity: "evUser", Field: "full_name", New: "Bob", Time: created[0].Time}, created[0])
+	assert.Equal(t, ChangeEvent{Entity: "evUser", Field: "user_id", New: 1, Time: created[1].Time}, created[1])
+
+	deleted, err := m.Events(&u, nil)
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+	assert.Equal(t, "evUser", deleted[0].Entity)
+	assert.Equal(t, 1, deleted[1].Old)
+	assert.Nil(t, deleted[1].New)
+
+	_, err = m.Events(nil, 42)
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
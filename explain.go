@@ -0,0 +1,235 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Decision describes what ToMap does with the struct field, see Explain.
+type Decision struct {
+	// Path is the dot-separated path of Go field names from the root
+	// struct, i.e. "Address.ZIP".
+	Path string
+	// Key is the dot-separated key path in the output map, i.e.
+	// "address.zip", including the prefix.  It is empty if the field is
+	// skipped before the key is resolved.
+	Key string
+	// Skipped is true if the field is not in the output.
+	Skipped bool
+	// Reason is the reason why the field is skipped.
+	Reason string
+	// Conversion describes how the field is converted, i.e.
+	// "time.Time → int64", or that it's flattened or nested.
+	Conversion string
+	// Err is the conversion error, if any.  The field keeps its original
+	// value in that case.
+	Err error
+}
+
+// String returns the one-line description of the decision.
+func (d Decision) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Path)
+	if d.Key != "" {
+		fmt.Fprintf(&sb, " → %q", d.Key)
+	}
+	if d.Skipped {
+		fmt.Fprintf(&sb, ": skipped: %s", d.Reason)
+	} else if d.Conversion != "" {
+		fmt.Fprintf(&sb, ": %s", d.Conversion)
+	}
+	if d.Err != nil {
+		fmt.Fprintf(&sb, ": error: %v", d.Err)
+	}
+	return sb.String()
+}
+
+// Explain describes, per field, what ToMap does with the struct a, see
+// Mapper.Explain.  It returns nil if a is not a struct.
+func Explain(a any) []Decision {
+	ds, _ := New().Explain(a)
+	return ds
+}
+
+// Explain describes, per field of the struct a, including the fields of the
+// nested structs, the resolved key, whether the field is skipped and why, and
+// how it's converted, i.e. to find out why the field is missing from the
+// output.  The decisions are in the order of the fields.
+//
+// Explain converts the values to describe them, but does not call the hooks,
+// and does not resolve the key conflicts, see StrictConflicts.
+func (m Mapper) Explain(a any) ([]Decision, error) {
+	v, err := derefStruct(reflect.ValueOf(a))
+	if err != nil {
+		return nil, err
+	}
+	var ds []Decision
+	m.explain(&ds, v, "", m.prefix)
+	return ds, nil
+}
+
+// explain appends the decisions for the fields of the struct value v at
+// path, with the keys prefixed with prefix.
+func (m Mapper) explain(ds *[]Decision, v reflect.Value, path, prefix string) {
+	typ := v.Type()
+	m = m.withProfile(typ)
+	for i := range v.NumField() {
+		field := typ.Field(i)
+		fv := v.Field(i)
+		d := Decision{Path: joinPath(path, field.Name)}
+		if !field.IsExported() {
+			*ds = append(*ds, d.skip("unexported"))
+			continue
+		}
+		tag := m.tagFor(field)
+		_, isProvider := asMapProvider(fv)
+		nested := isProvider || isNested(field.Type)
+		flatten := nested && m.flattens(field, tag)
+
+		key, err := m.tagName(field, fv, tag, m.Omitempty)
+		if errors.Is(err, ErrSkip) && !flatten {
+			*ds = append(*ds, d.skip(m.skipReason(field, tag)))
+			continue
+		}
+		if !flatten {
+			d.Key = prefix + key
+		}
+		if hasOption(field, tag, fWriteOnly) {
+			*ds = append(*ds, d.skip("write-only"))
+			continue
+		}
+		if omit, err := m.omitIf(v, field); err != nil {
+			d.Err = err
+		} else if omit {
+			*ds = append(*ds, d.skip(fmt.Sprintf("omitif condition %q holds", field.Tag.Get(omitIfTag))))
+			continue
+		}
+		if reason, ok := m.excluded(d.Key, nested && !flatten); ok {
+			*ds = append(*ds, d.skip(reason))
+			continue
+		}
+
+		switch {
+		case isOptional(field.Type):
+			switch fv.Interface().(optional).optionalState() {
+			case absent:
+				d = d.skip("optional is absent")
+			case null:
+				d.Conversion = "optional, null"
+			default:
+				d.Conversion = "optional, set"
+			}
+		case isProvider:
+			d.Conversion = "map provider"
+			if flatten {
+				d.Conversion += ", flattened"
+			}
+		case flatten:
+			d.Conversion = "flattened"
+			*ds = append(*ds, d)
+			m.explain(ds, fv, d.Path, prefix)
+			continue
+		case nested:
+			d.Conversion = "nested map"
+			*ds = append(*ds, d)
+			m.explain(ds, fv, d.Path, d.Key+".")
+			continue
+		default:
+			m.explainLeaf(&d, field, fv, tag)
+		}
+		*ds = append(*ds, d)
+	}
+}
+
+// explainLeaf describes the conversion of the leaf field value fv.
+func (m Mapper) explainLeaf(d *Decision, field reflect.StructField, fv reflect.Value, tag string) {
+	val, err := m.value(context.Background(), fv, tagOptions(field, tag))
+	switch {
+	case errors.Is(err, ErrSkip):
+		*d = d.skip("skipped by the converter, the tag option or the unsupported kind policy")
+		return
+	case errors.Is(err, ErrUnsupportedKind):
+		*d = d.skip("unsupported kind")
+		d.Err = err
+		return
+	case err != nil:
+		d.Err = err
+	}
+	var notes []string
+	if name, _, ok := optionHandler(tagOptions(field, tag)); ok {
+		notes = append(notes, fmt.Sprintf("tag option %q", name))
+	} else if _, ok := outConverter(fv.Type()); ok {
+		notes = append(notes, "converter")
+	}
+	if m.encrypt != nil && isSecure(field) {
+		notes = append(notes, "encrypted")
+	}
+	d.Conversion = fmt.Sprintf("%s → %s", fv.Type(), typeString(val))
+	if len(notes) > 0 {
+		d.Conversion += " (" + strings.Join(notes, ", ") + ")"
+	}
+}
+
+// skip returns the decision marked as skipped for the reason.
+func (d Decision) skip(reason string) Decision {
+	d.Skipped, d.Reason = true, reason
+	return d
+}
+
+// skipReason returns the reason why the tag name of the field is skipped.
+func (m Mapper) skipReason(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(tagValue(field, tag), tagsep)
+	switch {
+	case name == "-":
+		return fmt.Sprintf(`%s tag is "-"`, tag)
+	case name == "" && m.untagged == SkipUntagged:
+		return "untagged, see Untagged"
+	}
+	return "empty, with omitempty"
+}
+
+// excluded returns the reason, if the key path is not in the output due to
+// Include or Exclude.  partial is true if the key is a nested map, that is
+// kept if only some of its keys are included.
+func (m Mapper) excluded(key string, partial bool) (string, bool) {
+	path := strings.Split(strings.TrimPrefix(key, m.prefix), ".")
+	if m.include != nil {
+		if all, some := m.include.covers(path); !all && !(partial && some) {
+			return "not included, see Include", true
+		}
+	}
+	if m.exclude != nil {
+		if all, _ := m.exclude.covers(path); all {
+			return "excluded, see Exclude", true
+		}
+	}
+	return "", false
+}
+
+// covers returns true if the mask selects the whole value at the key path,
+// and, if not, whether it selects some of its nested keys.
+func (mk mask) covers(path []string) (all, some bool) {
+	cur := mk
+	for _, key := range path {
+		sub, ok := cur[key]
+		if !ok {
+			return false, false
+		}
+		if sub == nil {
+			return true, true
+		}
+		cur = sub
+	}
+	return false, true
+}
+
+// typeString returns the type of v, or "nil".
+func typeString(v any) string {
+	if v == nil {
+		return "nil"
+	}
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,92 @@
+package tagops
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ExplainBase struct {
+	ID int `json:"id"`
+}
+
+type explainAddress struct {
+	City string `json:"city"`
+	ZIP  string `json:"zip"`
+}
+
+type explainUser struct {
+	ExplainBase
+	Name     string `json:"name"`
+	secret   string
+	Internal string         `json:"-"`
+	Nick     string         `json:"nick,omitempty"`
+	Password string         `json:"password,writeonly"`
+	Status   string         `json:"status"`
+	Reason   string         `json:"reason" omitif:"status!=banned"`
+	Created  time.Time      `json:"created"`
+	Address  explainAddress `json:"address"`
+	Age      Optional[int]  `json:"age"`
+}
+
+func TestExplain(t *testing.T) {
+	u := explainUser{Name: "ann", Status: "active", Created: time.Unix(0, 0)}
+	m := New(Omitempty(), FormatTime(TimeUnix), Exclude("address.zip"), Prefix("u."))
+	got, err := m.Explain(&u)
+	require.NoError(t, err)
+	want := []Decision{
+		{Path: "ExplainBase", Conversion: "flattened"},
+		{Path: "ExplainBase.ID", Key: "u.id", Conversion: "int → int"},
+		{Path: "Name", Key: "u.name", Conversion: "string → string"},
+		{Path: "secret", Skipped: true, Reason: "unexported"},
+		{Path: "Internal", Skipped: true, Reason: `json tag is "-"`},
+		{Path: "Nick", Skipped: true, Reason: "empty, with omitempty"},
+		{Path: "Password", Key: "u.password", Skipped: true, Reason: "write-only"},
+		{Path: "Status", Key: "u.status", Conversion: "string → string"},
+		{Path: "Reason", Key: "u.reason", Skipped: true, Reason: `omitif condition "status!=banned" holds`},
+		{Path: "Created", Key: "u.created", Conversion: "time.Time → int64"},
+		{Path: "Address", Key: "u.address", Conversion: "nested map"},
+		{Path: "Address.City", Key: "u.address.city", Conversion: "string → string"},
+		{Path: "Address.ZIP", Key: "u.address.zip", Skipped: true, Reason: "excluded, see Exclude"},
+		{Path: "Age", Key: "u.age", Skipped: true, Reason: "optional is absent"},
+	}
+	assert.Equal(t, want, got)
+
+	t.Run("matches ToMap", func(t *testing.T) {
+		mp, err := m.ToMapE(u)
+		require.NoError(t, err)
+		kv, err := m.ToKV(u, "", ".")
+		require.NoError(t, err)
+		var keys []string
+		for _, d := range got {
+			if !d.Skipped && d.Conversion != "flattened" && d.Conversion != "nested map" {
+				keys = append(keys, d.Key)
+			}
+		}
+		assert.ElementsMatch(t, slices.Collect(maps.Keys(kv)), keys)
+		assert.Len(t, mp, 5)
+	})
+	t.Run("include", func(t *testing.T) {
+		got, err := New(Include("address.city")).Explain(u)
+		require.NoError(t, err)
+		for _, d := range got {
+			switch d.Path {
+			case "Address", "Address.City":
+				assert.False(t, d.Skipped, d.Path)
+			case "secret", "Internal", "Address.ZIP", "Name":
+				assert.True(t, d.Skipped, d.Path)
+			}
+		}
+	})
+	t.Run("String", func(t *testing.T) {
+		assert.Equal(t, `Created → "u.created": time.Time → int64`, want[9].String())
+		assert.Equal(t, `Nick: skipped: empty, with omitempty`, want[5].String())
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		assert.Nil(t, Explain(1))
+	})
+}
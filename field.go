@@ -0,0 +1,79 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// fieldByKey returns the value of the field of the struct value v, that has
+// the key name in m.Tag.  Flattened nested structs are searched too, as their
+// fields are promoted into the parent map.  The key conflicts are resolved as
+// in ToMap, see Mapper.topFields.
+func (m Mapper) fieldByKey(v reflect.Value, name string) (reflect.Value, bool) {
+	idx, ok := m.fieldIndex(v.Type(), name)
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return v.FieldByIndex(idx), true
+}
+
+// fieldIndex returns the index sequence of the field of the struct type typ,
+// that has the key name, see fieldByKey.
+func (m Mapper) fieldIndex(typ reflect.Type, name string) ([]int, bool) {
+	f, ok := m.lookupField(typ, name)
+	return f.index, ok
+}
+
+// lookupField returns the field of the struct type typ, that has the key
+// name, see fieldByKey.
+func (m Mapper) lookupField(typ reflect.Type, name string) (topField, bool) {
+	for _, f := range m.topFields(typ, reflect.Value{}, false) {
+		if f.fi.Key == name {
+			return f, true
+		}
+	}
+	return topField{}, false
+}
+
+// derefStruct dereferences pointers and interfaces, and returns the struct
+// value.  It returns ErrNotStruct if v is not a struct or a nil pointer.
+func derefStruct(v reflect.Value) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, fmt.Errorf("%w: nil %s", ErrNotStruct, v.Kind())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		if !v.IsValid() {
+			return reflect.Value{}, fmt.Errorf("%w: nil", ErrNotStruct)
+		}
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrNotStruct, v.Type())
+	}
+	return v, nil
+}
+
+// sliceValue returns the slice or array value of a.
+func sliceValue(a any) (reflect.Value, error) {
+	v := reflect.ValueOf(a)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return reflect.Value{}, fmt.Errorf("%w: %T", ErrNotSlice, a)
+	}
+	return v, nil
+}
+
+// elemField returns the field with the key name of the struct element v.
+func (m Mapper) elemField(v reflect.Value, name string) (reflect.Value, error) {
+	sv, err := derefStruct(v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	fv, ok := m.fieldByKey(sv, name)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%w: %q", ErrFieldNotFound, name)
+	}
+	return fv, nil
+}
@@ -0,0 +1,74 @@
+package tagops
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// tagOptions returns the options of the field tag, i.e. ["omitempty"] for
+// `json:"x,omitempty"`.
+func tagOptions(fld reflect.StructField, tag string) []string {
+	opts := strings.Split(tagValue(fld, tag), tagsep)
+	if len(opts) < 2 {
+		return nil
+	}
+	return opts[1:]
+}
+
+// format returns the value of fv formatted according to the tag options opts
+// and the Mapper format settings.  It returns false if no format applies.
+func (m Mapper) format(fv reflect.Value, opts []string) (any, bool, error) {
+	switch fv.Type() {
+	case timeType:
+		return m.formatTime(fv.Interface().(time.Time), opts)
+	case durationType:
+		val, ok := m.formatDuration(time.Duration(fv.Int()), opts)
+		return val, ok, nil
+	}
+	if isBig(fv.Type()) {
+		val, err := m.formatBig(fv, opts)
+		return val, true, err
+	}
+	if _, hasConv := outConverter(fv.Type()); hasConv {
+		return nil, false, nil
+	}
+	if name, ok := m.formatEnum(fv, opts); ok {
+		return name, true, nil
+	}
+	if isBytes(fv.Type()) {
+		val, ok := m.formatBytes(fv, opts)
+		return val, ok, nil
+	}
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return m.formatFloat(fv, opts)
+	}
+	return nil, false, nil
+}
+
+// parse is the reverse of format.  It parses the map value sv and sets fv
+// accordingly.  It returns false if no format applies.
+func (m Mapper) parse(fv reflect.Value, sv any, opts []string) (bool, error) {
+	switch fv.Type() {
+	case timeType:
+		t, ok, err := m.parseTime(sv, opts)
+		if ok && err == nil {
+			fv.Set(reflect.ValueOf(t))
+		}
+		return ok, err
+	case durationType:
+		d, ok, err := m.parseDuration(sv, opts)
+		if ok && err == nil {
+			fv.SetInt(int64(d))
+		}
+		return ok, err
+	}
+	if isBig(fv.Type()) {
+		return parseBig(fv, sv)
+	}
+	if isBytes(fv.Type()) {
+		return m.parseBytes(fv, sv, opts)
+	}
+	return parseEnum(fv, sv)
+}
@@ -0,0 +1,153 @@
+package tagops
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldSpec describes the struct field for form builders.
+type FieldSpec struct {
+	// Key is the name of the field in the tag.
+	Key string `json:"key"`
+	// Name is the Go field name.
+	Name string `json:"name"`
+	// Type is the Go type of the field, i.e. "time.Time".
+	Type string `json:"type"`
+	// Input is the input kind: "text", "number", "checkbox", "datetime",
+	// "duration", "select", "list" or "group".
+	Input string `json:"input"`
+	// Required is true if the field has the "required" tag option, or the
+	// "required" validation rule.
+	Required bool `json:"required,omitempty"`
+	// Options are the tag options of the field.
+	Options []string `json:"options,omitempty"`
+	// Validate is the value of the "validate" tag.
+	Validate string `json:"validate,omitempty"`
+	// Default is the value of the "default" tag.
+	Default string `json:"default,omitempty"`
+	// Label is the value of the "label" tag, or the Go field name.
+	Label string `json:"label"`
+	// Doc is the value of the "doc" tag.
+	Doc string `json:"doc,omitempty"`
+	// Choices are the sorted names of the registered enum values, see
+	// RegisterEnum.
+	Choices []string `json:"choices,omitempty"`
+	// Fields are the fields of the nested struct, for the "group" input.
+	Fields []FieldSpec `json:"fields,omitempty"`
+}
+
+// FormSpec returns the field specifications of the struct a, see
+// Mapper.FormSpec.
+func FormSpec(a any) []FieldSpec {
+	return New().FormSpec(a)
+}
+
+// FormSpec returns the field specifications of the struct a, in the order of
+// fields, for rendering the struct as a form.  The specification combines the
+// key in the Mapper tag with the Go type, and the "validate", "default",
+// "label" and "doc" tags.  Fields of flattened structs are included inline,
+// nested structs are groups.  It returns nil if a is not a struct.
+func (m Mapper) FormSpec(a any) []FieldSpec {
+	typ := reflect.TypeOf(a)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return m.formSpec(reflect.New(typ).Elem())
+}
+
+// formSpec returns the field specifications of the struct value v.
+func (m Mapper) formSpec(v reflect.Value) []FieldSpec {
+	var out []FieldSpec
+	typ := v.Type()
+	m = m.withProfile(typ)
+	for i := range v.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		tag := m.tagFor(field)
+		if isNested(field.Type) && m.flattens(field, tag) {
+			out = append(out, m.formSpec(fv)...)
+			continue
+		}
+		key, err := m.tagName(field, fv, tag, false)
+		if err != nil {
+			continue
+		}
+		opts := tagOptions(field, tag)
+		validate := field.Tag.Get("validate")
+		spec := FieldSpec{
+			Key:      key,
+			Name:     field.Name,
+			Type:     field.Type.String(),
+			Input:    inputKind(field.Type),
+			Required: slices.Contains(opts, fRequired) || slices.Contains(strings.Split(validate, ","), "required"),
+			Options:  opts,
+			Validate: validate,
+			Default:  field.Tag.Get("default"),
+			Label:    field.Tag.Get("label"),
+			Doc:      field.Tag.Get("doc"),
+			Choices:  enumNames(field.Type),
+		}
+		if spec.Label == "" {
+			spec.Label = field.Name
+		}
+		if spec.Choices != nil {
+			spec.Input = "select"
+		}
+		if isNested(field.Type) {
+			spec.Fields = m.formSpec(fv)
+		}
+		out = append(out, spec)
+	}
+	return out
+}
+
+// inputKind returns the form input kind for the type t.
+func inputKind(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return "datetime"
+	case t == durationType:
+		return "duration"
+	case isNested(t):
+		return "group"
+	case isBig(t):
+		return "number"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "checkbox"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return "list"
+		}
+	}
+	return "text"
+}
+
+// enumNames returns the sorted names of the enum type t, if registered.
+func enumNames(t reflect.Type) []string {
+	names, ok := enums.Load(t)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(names.(map[string]any)))
+	for name := range names.(map[string]any) {
+		out = append(out, name)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,44 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormSpec(t *testing.T) {
+	type Address struct {
+		City string `json:"city" label:"City"`
+	}
+	type Base struct {
+		ID int64 `json:"id"`
+	}
+	type Signup struct {
+		Base
+		Email   string        `json:"email,required" label:"E-mail" doc:"Used to sign in."`
+		Age     *int          `json:"age,omitempty" validate:"gte=18,required"`
+		Level   level         `json:"level" default:"info"`
+		Remind  time.Duration `json:"remind"`
+		Since   time.Time     `json:"since"`
+		Agree   bool          `json:"agree"`
+		Tags    []string      `json:"tags"`
+		Address Address       `json:"address"`
+		Secret  string        `json:"-"`
+	}
+	want := []FieldSpec{
+		{Key: "id", Name: "ID", Type: "int64", Input: "number", Label: "ID"},
+		{Key: "email", Name: "Email", Type: "string", Input: "text", Required: true, Options: []string{"required"}, Label: "E-mail", Doc: "Used to sign in."},
+		{Key: "age", Name: "Age", Type: "*int", Input: "number", Required: true, Options: []string{"omitempty"}, Validate: "gte=18,required", Label: "Age"},
+		{Key: "level", Name: "Level", Type: "tagops.level", Input: "select", Default: "info", Label: "Level", Choices: []string{"debug", "info"}},
+		{Key: "remind", Name: "Remind", Type: "time.Duration", Input: "duration", Label: "Remind"},
+		{Key: "since", Name: "Since", Type: "time.Time", Input: "datetime", Label: "Since"},
+		{Key: "agree", Name: "Agree", Type: "bool", Input: "checkbox", Label: "Agree"},
+		{Key: "tags", Name: "Tags", Type: "[]string", Input: "list", Label: "Tags"},
+		{Key: "address", Name: "Address", Type: "tagops.Address", Input: "group", Label: "Address", Fields: []FieldSpec{
+			{Key: "city", Name: "City", Type: "string", Input: "text", Label: "City"},
+		}},
+	}
+	assert.Equal(t, want, FormSpec(&Signup{}))
+	assert.Nil(t, FormSpec(42))
+}
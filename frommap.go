@@ -0,0 +1,519 @@
+package tagops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"time"
+)
+
+// FromMap populates the struct pointed to by a with values from the map src.
+// See package-level FromMap for details.
+func (m Mapper) FromMap(src map[string]any, a any) error {
+	return m.FromMapCtx(context.Background(), src, a)
+}
+
+// FromMapCtx is like FromMap, but passes the context ctx to converters.  The
+// conversion stops if ctx is cancelled, and the context error is returned.
+func (m Mapper) FromMapCtx(ctx context.Context, src map[string]any, a any) (err error) {
+	if m.metrics != nil {
+		defer func(start time.Time) { m.metrics.conversion("FromMap", start, err) }(time.Now())
+	}
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected non-nil pointer to struct, got %T", ErrNotStruct, a)
+	}
+	src = m.trimPrefix(src)
+	if m.defaults != nil {
+		src = withDefaults(src, m.defaults)
+	}
+	st := newState(ctx)
+	m.fromMap(st, src, v.Elem(), "")
+	return st.err(m.collectErrors)
+}
+
+// WithDefaults returns an Option that sets the default values for FromMap.
+// The values from defaults are used for the keys that are absent in the
+// source map, and are converted in the same way as the source values.  The
+// nested maps are merged key by key, so defaults may have the same shape as
+// the source map.  The keys of defaults are not prefixed, see Prefix.  The
+// defaults map must not be modified after it's set.
+func WithDefaults(defaults map[string]any) Option {
+	return func(m *Mapper) {
+		m.defaults = defaults
+	}
+}
+
+// withDefaults returns the map src with the keys absent in src set from
+// defaults.  The nested maps present in both are merged recursively.  The
+// maps are not modified.
+func withDefaults(src, defaults map[string]any) map[string]any {
+	out := maps.Clone(src)
+	if out == nil {
+		out = make(map[string]any, len(defaults))
+	}
+	for k, dv := range defaults {
+		sv, ok := out[k]
+		if !ok {
+			out[k] = dv
+			continue
+		}
+		sm, sIsMap := sv.(map[string]any)
+		dm, dIsMap := dv.(map[string]any)
+		if sIsMap && dIsMap {
+			out[k] = withDefaults(sm, dm)
+		}
+	}
+	return out
+}
+
+// fromMap populates the struct value v with values from the map src.  path is
+// the path of v from the root struct.  It returns false if the conversion
+// should stop.
+func (m Mapper) fromMap(st *state, src map[string]any, v reflect.Value, path string) bool {
+	typ := v.Type()
+	m = m.withProfile(typ)
+	m.trace(TraceEvent{Kind: TraceEnterStruct, Op: "FromMap", Path: path, Type: typ})
+	plan := m.plan(typ)
+	for i := range plan.fields {
+		if st.done() {
+			return false
+		}
+		fp := &plan.fields[i]
+		field := fp.field
+		if !field.IsExported() {
+			m.traceSkip("FromMap", field, joinPath(path, field.Name), "", "unexported")
+			continue
+		}
+		fv := v.Field(i)
+		fpath := joinPath(path, field.Name)
+		tag := fp.tag
+
+		if isNested(field.Type) && m.flattens(field, tag) {
+			if fp.has(fReadOnly) {
+				// all fields of the read-only struct are read-only
+				m.traceSkip("FromMap", field, fpath, "", "read-only")
+				continue
+			}
+			// flattened structs are populated from the same map
+			if !m.fromMap(st, src, fv, fpath) {
+				return false
+			}
+			continue
+		}
+
+		key, err := m.fieldKey(fp, fv, false)
+		if errors.Is(err, ErrInvalidFieldName) {
+			st.fail(fpath, err)
+			if !m.collectErrors {
+				return false
+			}
+			continue
+		}
+		if errors.Is(err, ErrSkip) {
+			if m.tracing() {
+				m.traceSkip("FromMap", field, fpath, "", m.skipReason(field, tag))
+			}
+			continue
+		}
+		if fp.has(fReadOnly) {
+			m.traceSkip("FromMap", field, fpath, key, "read-only")
+			continue
+		}
+		sv, ok := lookupKey(src, key, field)
+		if !ok {
+			m.traceSkip("FromMap", field, fpath, key, "absent in the source")
+			continue
+		}
+		m.trace(TraceEvent{Kind: TraceFieldResolved, Op: "FromMap", Path: fpath, Type: field.Type, Key: key, Value: sv})
+		m.deprecated(field, key, path, tag)
+		if sv, err = m.decryptField(field, key, sv); err != nil {
+			st.fail(fpath, err)
+			if !m.collectErrors {
+				return false
+			}
+			continue
+		}
+		if nested, ok := sv.(map[string]any); ok && m.mergeMaps && isStringMap(field.Type) {
+			if !m.mergeMap(st, fv, nested, fpath) {
+				return false
+			}
+			continue
+		}
+		if isOptional(field.Type) {
+			if !m.optionalIn(st, fv, sv, fp.opts, fpath) {
+				return false
+			}
+			continue
+		}
+		if isNested(field.Type) && sv != nil && !isJSONUnmarshaler(field.Type) {
+			nested, ok := sv.(map[string]any)
+			if !ok {
+				st.fail(fpath, fmt.Errorf("expected map[string]any, got %T", sv))
+				if !m.collectErrors {
+					return false
+				}
+				continue
+			}
+			if !m.fromMap(st, nested, fv, fpath) {
+				return false
+			}
+			continue
+		}
+		if nested, ok := sv.(map[string]any); ok && isNestedPtr(field.Type) {
+			// the existing struct is updated, as in encoding/json.
+			if fv.IsNil() {
+				fv.Set(reflect.New(field.Type.Elem()))
+			}
+			if !m.fromMap(st, nested, fv.Elem(), fpath) {
+				return false
+			}
+			continue
+		}
+		if sv, err = normalize(field, sv); err != nil {
+			st.fail(fpath, err)
+			if !m.collectErrors {
+				return false
+			}
+			continue
+		}
+		if ok, cont := m.fromCollection(st, fv, sv, fpath); ok {
+			if !cont {
+				return false
+			}
+			continue
+		}
+		err = m.assign(st.ctx, fv, sv, fp.opts)
+		m.trace(TraceEvent{Kind: TraceValueConverted, Op: "FromMap", Path: fpath, Type: field.Type, Key: key, Value: sv, Err: err})
+		if err != nil {
+			st.fail(fpath, err)
+			if !m.collectErrors {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// structElem returns the element type of the slice, array or string-keyed
+// map type t, if the element is a struct or a pointer to struct, that is
+// populated from a nested map.
+func structElem(t reflect.Type) (reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if !isNested(elem) || isJSONUnmarshaler(elem) {
+		return nil, false
+	}
+	return t.Elem(), true
+}
+
+// fromCollection populates the slice, array or map of structs fv from the
+// slice or map sv, i.e. []any or map[string]any of nested maps.  It returns
+// false as the first value if fv or sv is not a collection, and false as the
+// second value if the conversion should stop.
+func (m Mapper) fromCollection(st *state, fv reflect.Value, sv any, path string) (handled bool, cont bool) {
+	elemType, ok := structElem(fv.Type())
+	if !ok || sv == nil {
+		return false, true
+	}
+	rv := reflect.ValueOf(sv)
+	if rv.Type().AssignableTo(fv.Type()) {
+		return false, true
+	}
+	fail := func(path string, err error) (bool, bool) {
+		st.fail(path, err)
+		return true, m.collectErrors
+	}
+	switch {
+	case fv.Kind() == reflect.Map && rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		out := reflect.MakeMapWithSize(fv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			epath := fmt.Sprintf("%s[%s]", path, iter.Key().String())
+			elem := reflect.New(elemType).Elem()
+			if !m.fromElem(st, elem, iter.Value().Interface(), epath) {
+				return true, false
+			}
+			out.SetMapIndex(iter.Key().Convert(fv.Type().Key()), elem)
+		}
+		fv.Set(out)
+	case fv.Kind() != reflect.Map && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array):
+		var out reflect.Value
+		if fv.Kind() == reflect.Slice {
+			out = reflect.MakeSlice(fv.Type(), rv.Len(), rv.Len())
+		} else if rv.Len() > fv.Len() {
+			return fail(path, fmt.Errorf("%d elements do not fit %s", rv.Len(), fv.Type()))
+		} else {
+			out = reflect.New(fv.Type()).Elem()
+		}
+		for i := range rv.Len() {
+			if !m.fromElem(st, out.Index(i), rv.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i)) {
+				return true, false
+			}
+		}
+		fv.Set(out)
+	default:
+		return fail(path, fmt.Errorf("cannot assign %s to %s", rv.Type(), fv.Type()))
+	}
+	return true, true
+}
+
+// fromElem populates the struct or pointer to struct element ev of the
+// collection from the value sv.  It returns false if the conversion should
+// stop.
+func (m Mapper) fromElem(st *state, ev reflect.Value, sv any, path string) bool {
+	if sv == nil {
+		ev.SetZero()
+		return true
+	}
+	if rv := reflect.ValueOf(sv); rv.Type().AssignableTo(ev.Type()) {
+		ev.Set(rv)
+		return true
+	}
+	nested, ok := sv.(map[string]any)
+	if !ok {
+		st.fail(path, fmt.Errorf("expected map[string]any, got %T", sv))
+		return m.collectErrors
+	}
+	if ev.Kind() == reflect.Ptr {
+		ev.Set(reflect.New(ev.Type().Elem()))
+		ev = ev.Elem()
+	}
+	return m.fromMap(st, nested, ev, path)
+}
+
+// assign assigns the value sv to fv, parsing it according to the custom and
+// built-in tag options opts and the Mapper format settings, if any apply.  If
+// the value can not be assigned, and fv implements json.Unmarshaler, or the
+// json/v2 json.UnmarshalerFrom, see JSONv2Unmarshal, the value is re-encoded
+// to JSON and passed to the unmarshaler.
+func (m Mapper) assign(ctx context.Context, fv reflect.Value, sv any, opts []string) error {
+	if fv.Kind() == reflect.Ptr && sv != nil && allocates(fv.Type(), reflect.TypeOf(sv)) {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := m.assign(ctx, ptr.Elem(), sv, opts); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if ok, err := parseOption(ctx, fv, sv, opts); ok {
+		return err
+	}
+	if ok, err := m.parse(fv, sv, opts); ok {
+		return err
+	}
+	if s, ok := sv.(string); ok && m.parseStrings {
+		if ok, err := parseString(fv, s); ok {
+			return err
+		}
+	}
+	err := assign(ctx, fv, sv)
+	if err == nil || sv == nil {
+		return err
+	}
+	if m.jsonV2Unmarshal != nil && jsonV2Of(fv.Type()).unmarshaler {
+		return unmarshalJSONv2(fv, sv, m.jsonV2Unmarshal)
+	}
+	if isJSONUnmarshaler(fv.Type()) {
+		return unmarshalJSON(fv, sv)
+	}
+	return err
+}
+
+// allocates returns true if the value of type st is assigned to the newly
+// allocated value the pointer type pt points to, i.e. 42 to *int.  Pointers
+// to big numbers and the pointer types with registered converters from st are
+// assigned as is.
+func allocates(pt, st reflect.Type) bool {
+	if st.AssignableTo(pt) || isBig(pt) {
+		return false
+	}
+	_, hasConv := converterFor(st, pt)
+	return !hasConv
+}
+
+// isNestedPtr returns true if t is a pointer to struct, that is populated from
+// the nested map.
+func isNestedPtr(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && isNested(t.Elem()) && !isJSONUnmarshaler(t.Elem())
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// isJSONUnmarshaler returns true if t or a pointer to t implements
+// json.Unmarshaler.
+func isJSONUnmarshaler(t reflect.Type) bool {
+	return t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType)
+}
+
+// unmarshalJSON encodes sv to JSON and decodes it into the addressable value
+// fv, that implements json.Unmarshaler.  Nil pointers are allocated.
+func unmarshalJSON(fv reflect.Value, sv any) error {
+	data, err := json.Marshal(sv)
+	if err != nil {
+		return err
+	}
+	target := fv.Addr()
+	if fv.Kind() == reflect.Ptr && fv.Type().Implements(jsonUnmarshalerType) {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		target = fv
+	}
+	return target.Interface().(json.Unmarshaler).UnmarshalJSON(data)
+}
+
+// assign assigns the value sv to fv, converting it if necessary.  Nil value
+// resets fv to the zero value.
+func assign(ctx context.Context, fv reflect.Value, sv any) error {
+	if sv == nil {
+		fv.SetZero()
+		return nil
+	}
+	if isUnsupported(fv.Kind()) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedKind, fv.Kind())
+	}
+	rv := reflect.ValueOf(sv)
+	if fn, ok := converterFor(rv.Type(), fv.Type()); ok {
+		ret, err := fn(ctx, sv)
+		if errors.Is(err, ErrSkip) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("convert %s to %s: %w", rv.Type(), fv.Type(), err)
+		}
+		if ret == nil {
+			fv.SetZero()
+			return nil
+		}
+		rv = reflect.ValueOf(ret)
+	}
+	switch {
+	case rv.Type().AssignableTo(fv.Type()):
+		fv.Set(rv)
+	case rv.Kind() == fv.Kind() && rv.Type().ConvertibleTo(fv.Type()):
+		// named types, i.e. type Status string.
+		fv.Set(rv.Convert(fv.Type()))
+	case isNumber(rv.Kind()) && isNumber(fv.Kind()):
+		return convertNumber(fv, rv)
+	case isList(rv.Kind()) && isList(fv.Kind()):
+		// i.e. []any from decoded JSON to []string.
+		return assignList(ctx, fv, rv)
+	case rv.Kind() == reflect.Map && fv.Kind() == reflect.Map && rv.Type().Key().ConvertibleTo(fv.Type().Key()):
+		return assignMap(ctx, fv, rv)
+	default:
+		return fmt.Errorf("cannot assign %s to %s", rv.Type(), fv.Type())
+	}
+	return nil
+}
+
+// isList returns true if the kind k is a slice or an array.
+func isList(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array
+}
+
+// assignList assigns the elements of the slice or array rv to the slice or
+// array fv, converting them with assign.
+func assignList(ctx context.Context, fv, rv reflect.Value) error {
+	var out reflect.Value
+	if fv.Kind() == reflect.Slice {
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			fv.SetZero()
+			return nil
+		}
+		out = reflect.MakeSlice(fv.Type(), rv.Len(), rv.Len())
+	} else if rv.Len() > fv.Len() {
+		return fmt.Errorf("%d elements do not fit %s", rv.Len(), fv.Type())
+	} else {
+		out = reflect.New(fv.Type()).Elem()
+	}
+	for i := range rv.Len() {
+		if err := assign(ctx, out.Index(i), rv.Index(i).Interface()); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	fv.Set(out)
+	return nil
+}
+
+// assignMap assigns the elements of the map rv to the map fv, converting
+// them with assign.
+func assignMap(ctx context.Context, fv, rv reflect.Value) error {
+	if rv.IsNil() {
+		fv.SetZero()
+		return nil
+	}
+	out := reflect.MakeMapWithSize(fv.Type(), rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		elem := reflect.New(fv.Type().Elem()).Elem()
+		if err := assign(ctx, elem, iter.Value().Interface()); err != nil {
+			return fmt.Errorf("key %v: %w", iter.Key(), err)
+		}
+		out.SetMapIndex(iter.Key().Convert(fv.Type().Key()), elem)
+	}
+	fv.Set(out)
+	return nil
+}
+
+// isNumber returns true if the kind k is an integer or a float.
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// convertNumber converts the numeric value rv to the numeric type of fv and
+// sets it.  It returns an error if the value does not fit into the
+// destination type, or if the destination is an integer and the value has a
+// fractional part.
+func convertNumber(fv, rv reflect.Value) error {
+	errOverflow := fmt.Errorf("value %v overflows %s", rv, fv.Type())
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := rv.Convert(fv.Type())
+		if fv.OverflowFloat(f.Float()) {
+			return errOverflow
+		}
+		fv.Set(f)
+		return nil
+	}
+	if rv.CanFloat() {
+		f := rv.Float()
+		if f != math.Trunc(f) || math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("value %v is not an integer", f)
+		}
+		if f < 0 && !fv.CanInt() {
+			return errOverflow
+		}
+	}
+	if rv.CanInt() && rv.Int() < 0 && !fv.CanInt() {
+		return errOverflow
+	}
+	c := rv.Convert(fv.Type())
+	// round trip check catches overflows.
+	if !c.Convert(rv.Type()).Equal(rv) {
+		return errOverflow
+	}
+	fv.Set(c)
+	return nil
+}
@@ -0,0 +1,452 @@
+package tagops
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromMap(t *testing.T) {
+	type (
+		Address struct {
+			Street string `json:"street"`
+			ZIP    int    `json:"zip"`
+		}
+		Person struct {
+			Name      string    `json:"name"`
+			Age       uint8     `json:"age"`
+			Score     float64   `json:"score"`
+			CreatedAt time.Time `json:"created_at"`
+			Address   Address   `json:"address"`
+			Ignored   string    `json:"-"`
+		}
+		Status   string
+		Employee struct {
+			Person
+			Status Status `json:"status"`
+		}
+	)
+	var testDate = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type args struct {
+		src     map[string]any
+		tag     string
+		flatten bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		init    Employee
+		want    Employee
+		wantErr bool
+	}{
+		{
+			name: "nested map",
+			args: args{
+				src: map[string]any{
+					"name":       "John",
+					"age":        float64(42), // as decoded from JSON
+					"score":      1,
+					"created_at": testDate,
+					"address":    map[string]any{"street": "123 Main St", "zip": 12345},
+					"status":     "active",
+					"Ignored":    "x",
+				},
+				tag: "json",
+			},
+			want: Employee{
+				Person: Person{
+					Name:      "John",
+					Age:       42,
+					Score:     1,
+					CreatedAt: testDate,
+					Address:   Address{Street: "123 Main St", ZIP: 12345},
+				},
+				Status: "active",
+			},
+		},
+		{
+			name: "flattened",
+			args: args{
+				src:     map[string]any{"name": "John", "street": "123 Main St", "zip": 12345},
+				tag:     "json",
+				flatten: true,
+			},
+			want: Employee{
+				Person: Person{
+					Name:    "John",
+					Address: Address{Street: "123 Main St", ZIP: 12345},
+				},
+			},
+		},
+		{
+			name: "absent keys are untouched, nil resets",
+			args: args{
+				src: map[string]any{"name": nil},
+				tag: "json",
+			},
+			init: Employee{Person: Person{Name: "John", Age: 42}},
+			want: Employee{Person: Person{Age: 42}},
+		},
+		{
+			name:    "overflow",
+			args:    args{src: map[string]any{"age": 256}, tag: "json"},
+			wantErr: true,
+		},
+		{
+			name:    "fractional to int",
+			args:    args{src: map[string]any{"age": 1.5}, tag: "json"},
+			wantErr: true,
+		},
+		{
+			name:    "negative to unsigned",
+			args:    args{src: map[string]any{"age": -1}, tag: "json"},
+			wantErr: true,
+		},
+		{
+			name:    "type mismatch",
+			args:    args{src: map[string]any{"name": 1}, tag: "json"},
+			wantErr: true,
+		},
+		{
+			name:    "nested is not a map",
+			args:    args{src: map[string]any{"address": "somewhere"}, tag: "json"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.init
+			err := FromMap(tt.args.src, &got, tt.args.tag, tt.args.flatten)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromMap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFromMap_notPointer(t *testing.T) {
+	type S struct{}
+	assert.Error(t, FromMap(map[string]any{}, S{}, "json", false))
+	assert.Error(t, FromMap(map[string]any{}, (*S)(nil), "json", false))
+}
+
+func TestFromMap_roundTrip(t *testing.T) {
+	type S struct {
+		A string `json:"a"`
+		B int    `json:"b"`
+		C struct {
+			D bool `json:"d"`
+		} `json:"c"`
+	}
+	var in S
+	in.A, in.B, in.C.D = "a", 1, true
+	for _, flatten := range []bool{false, true} {
+		var out S
+		err := FromMap(ToMap(in, "json", false, flatten), &out, "json", flatten)
+		assert.NoError(t, err)
+		assert.Equal(t, in, out)
+	}
+}
+
+// celsius implements json.Unmarshaler, and decodes {"c": 36.6} or "36.6C".
+type celsius struct {
+	deg float64
+}
+
+func (c *celsius) UnmarshalJSON(data []byte) error {
+	var obj struct {
+		C float64 `json:"c"`
+	}
+	if err := json.Unmarshal(data, &obj); err == nil {
+		c.deg = obj.C
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	_, err := fmt.Sscanf(s, "%fC", &c.deg)
+	return err
+}
+
+func TestFromMap_jsonUnmarshaler(t *testing.T) {
+	type Reading struct {
+		Temp    celsius   `json:"temp"`
+		Ptr     *celsius  `json:"ptr"`
+		Str     celsius   `json:"str"`
+		Created time.Time `json:"created"`
+	}
+	var got Reading
+	err := FromMap(map[string]any{
+		"temp":    map[string]any{"c": 36.6},
+		"ptr":     map[string]any{"c": 1.5},
+		"str":     "20C",
+		"created": "2021-01-01T00:00:00Z",
+	}, &got, "json", false)
+	assert.NoError(t, err)
+	assert.Equal(t, Reading{
+		Temp:    celsius{36.6},
+		Ptr:     &celsius{1.5},
+		Str:     celsius{20},
+		Created: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, got)
+
+	err = FromMap(map[string]any{"str": true}, &got, "json", false)
+	assert.Error(t, err)
+}
+
+func TestMapper_WithDefaults(t *testing.T) {
+	type db struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type config struct {
+		Name    string        `json:"name"`
+		Timeout time.Duration `json:"timeout,seconds"`
+		Debug   bool          `json:"debug"`
+		DB      db            `json:"db"`
+	}
+	defaults := map[string]any{
+		"name":    "app",
+		"timeout": 30,
+		"db":      map[string]any{"host": "localhost", "port": 5432.0},
+	}
+	m := New(WithDefaults(defaults))
+	tests := []struct {
+		name string
+		src  map[string]any
+		want config
+	}{
+		{
+			name: "empty source",
+			src:  nil,
+			want: config{Name: "app", Timeout: 30 * time.Second, DB: db{Host: "localhost", Port: 5432}},
+		},
+		{
+			name: "source wins",
+			src:  map[string]any{"name": "svc", "debug": true, "db": map[string]any{"port": 6432}},
+			want: config{Name: "svc", Timeout: 30 * time.Second, Debug: true, DB: db{Host: "localhost", Port: 6432}},
+		},
+		{
+			name: "nil value is not absent",
+			src:  map[string]any{"name": nil},
+			want: config{Timeout: 30 * time.Second, DB: db{Host: "localhost", Port: 5432}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got config
+			require.NoError(t, m.FromMap(tt.src, &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.Equal(t, map[string]any{"host": "localhost", "port": 5432.0}, defaults["db"], "defaults are not modified")
+
+	t.Run("flatten", func(t *testing.T) {
+		var got config
+		require.NoError(t, New(Flatten(), WithDefaults(map[string]any{"host": "db"})).FromMap(map[string]any{"port": 1}, &got))
+		assert.Equal(t, db{Host: "db", Port: 1}, got.DB)
+	})
+}
+
+func TestFromMap_collections(t *testing.T) {
+	type item struct {
+		SKU string `json:"sku"`
+		Qty int    `json:"qty"`
+	}
+	type order struct {
+		Items    []item           `json:"items"`
+		Ptrs     []*item          `json:"ptrs"`
+		ByName   map[string]item  `json:"by_name"`
+		PtrByKey map[string]*item `json:"ptr_by_key"`
+		Top      [2]item          `json:"top"`
+	}
+	payload := `{
+		"items": [{"sku": "a", "qty": 1}, {"sku": "b", "qty": 2}],
+		"ptrs": [{"sku": "c"}, null],
+		"by_name": {"x": {"sku": "x", "qty": 3}},
+		"ptr_by_key": {"y": {"qty": 4}},
+		"top": [{"sku": "t"}]
+	}`
+	var src map[string]any
+	require.NoError(t, json.Unmarshal([]byte(payload), &src))
+
+	var got order
+	got.Top[1] = item{SKU: "stale"}
+	require.NoError(t, New().FromMap(src, &got))
+	assert.Equal(t, order{
+		Items:    []item{{"a", 1}, {"b", 2}},
+		Ptrs:     []*item{{SKU: "c"}, nil},
+		ByName:   map[string]item{"x": {"x", 3}},
+		PtrByKey: map[string]*item{"y": {Qty: 4}},
+		Top:      [2]item{{SKU: "t"}},
+	}, got)
+
+	t.Run("typed values are assigned", func(t *testing.T) {
+		var got order
+		items := []item{{"a", 1}}
+		require.NoError(t, New().FromMap(map[string]any{"items": items, "ptrs": []any{&item{SKU: "p"}}}, &got))
+		assert.Equal(t, items, got.Items)
+		assert.Equal(t, []*item{{SKU: "p"}}, got.Ptrs)
+	})
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			src  map[string]any
+			path string
+		}{
+			{"element not a map", map[string]any{"items": []any{42}}, "Items[0]"},
+			{"element field", map[string]any{"items": []any{map[string]any{}, map[string]any{"qty": "x"}}}, "Items[1].Qty"},
+			{"map element field", map[string]any{"by_name": map[string]any{"k": map[string]any{"qty": "x"}}}, "ByName[k].Qty"},
+			{"not a collection", map[string]any{"items": "x"}, "Items"},
+			{"array overflow", map[string]any{"top": []any{nil, nil, nil}}, "Top"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var got order
+				err := New().FromMap(tt.src, &got)
+				var fe *FieldError
+				require.ErrorAs(t, err, &fe)
+				assert.Equal(t, tt.path, fe.Path)
+			})
+		}
+	})
+}
+
+func TestFromMap_pointers(t *testing.T) {
+	type inner struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}
+	type patch struct {
+		Int    *int       `json:"int"`
+		Str    *string    `json:"str"`
+		When   *time.Time `json:"when,unix"`
+		PP     **int      `json:"pp"`
+		Inner  *inner     `json:"inner"`
+		Status *string    `json:"status"`
+	}
+	one := 1
+	status := "active"
+	orig := &inner{A: 1, B: "keep"}
+	dst := patch{Int: &one, Inner: orig, Status: &status}
+
+	src := map[string]any{
+		"int":    42.0,
+		"str":    "x",
+		"when":   int64(60),
+		"pp":     7,
+		"inner":  map[string]any{"a": 2},
+		"status": nil,
+	}
+	require.NoError(t, New().FromMap(src, &dst))
+
+	require.NotNil(t, dst.Int)
+	assert.Equal(t, 42, *dst.Int)
+	assert.Equal(t, 1, one, "the previous value is not overwritten")
+	require.NotNil(t, dst.Str)
+	assert.Equal(t, "x", *dst.Str)
+	require.NotNil(t, dst.When)
+	assert.Equal(t, int64(60), dst.When.Unix())
+	require.NotNil(t, dst.PP)
+	assert.Equal(t, 7, **dst.PP)
+	assert.Same(t, orig, dst.Inner, "the nested struct is updated in place")
+	assert.Equal(t, inner{A: 2, B: "keep"}, *dst.Inner)
+	assert.Nil(t, dst.Status, "explicit null sets nil")
+
+	t.Run("absent keys are untouched", func(t *testing.T) {
+		p := patch{Int: &one}
+		require.NoError(t, New().FromMap(map[string]any{}, &p))
+		assert.Same(t, &one, p.Int)
+	})
+	t.Run("allocated nested struct", func(t *testing.T) {
+		var p patch
+		require.NoError(t, New().FromMap(map[string]any{"inner": map[string]any{"b": "y"}}, &p))
+		assert.Equal(t, &inner{B: "y"}, p.Inner)
+	})
+	t.Run("errors", func(t *testing.T) {
+		var p patch
+		err := New().FromMap(map[string]any{"int": "x"}, &p)
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "Int", fe.Path)
+		assert.Nil(t, p.Int, "not allocated on error")
+
+		err = New().FromMap(map[string]any{"inner": map[string]any{"a": "x"}}, &p)
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "Inner.A", fe.Path)
+	})
+}
+
+func TestFromMap_decodedJSONLists(t *testing.T) {
+	type S struct {
+		Tags   []string          `json:"tags"`
+		Nums   [3]int            `json:"nums"`
+		Scores map[string]int    `json:"scores"`
+		Grid   [][]float64       `json:"grid"`
+		Attrs  map[string]string `json:"attrs"`
+	}
+	var src map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"tags": ["a", "b"],
+		"nums": [1, 2],
+		"scores": {"x": 1, "y": 2},
+		"grid": [[1.5], []],
+		"attrs": null
+	}`), &src))
+	s := S{Attrs: map[string]string{"old": "1"}}
+	require.NoError(t, New().FromMap(src, &s))
+	assert.Equal(t, S{
+		Tags:   []string{"a", "b"},
+		Nums:   [3]int{1, 2, 0},
+		Scores: map[string]int{"x": 1, "y": 2},
+		Grid:   [][]float64{{1.5}, {}},
+	}, s)
+
+	err := New().FromMap(map[string]any{"tags": []any{"a", 1}}, &s)
+	assert.EqualError(t, err, "Tags: element 1: cannot assign int to string")
+	err = New().FromMap(map[string]any{"nums": []any{1, 2, 3, 4}}, &s)
+	assert.Error(t, err)
+}
+
+func TestFromMap_alias(t *testing.T) {
+	type profile struct {
+		Bio string `json:"bio" alias:"about"`
+	}
+	type user struct {
+		Name    string  `json:"username" alias:"user_name,login"`
+		Age     int     `json:"age"`
+		Profile profile `json:"profile" alias:"details"`
+	}
+	tests := []struct {
+		name string
+		src  map[string]any
+		want user
+	}{
+		{"key", map[string]any{"username": "ann"}, user{Name: "ann"}},
+		{"first alias", map[string]any{"user_name": "ann"}, user{Name: "ann"}},
+		{"second alias", map[string]any{"login": "ann"}, user{Name: "ann"}},
+		{"key wins", map[string]any{"login": "bob", "username": "ann"}, user{Name: "ann"}},
+		{"alias order", map[string]any{"login": "bob", "user_name": "ann"}, user{Name: "ann"}},
+		{"nested", map[string]any{"details": map[string]any{"about": "hi"}}, user{Profile: profile{Bio: "hi"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got user
+			require.NoError(t, New().FromMap(tt.src, &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("output uses the key", func(t *testing.T) {
+		assert.Equal(t, map[string]any{"username": "ann", "age": 0, "profile": map[string]any{"bio": ""}}, ToMap(user{Name: "ann"}, "json", false, false))
+	})
+}
@@ -0,0 +1,86 @@
+package tagops
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+type fuzzInner struct {
+	N  int               `json:"n,omitempty"`
+	S  string            `json:"s"`
+	M  map[string]any    `json:"m"`
+	SS []string          `json:"ss"`
+	MS map[string]string `json:"ms"`
+}
+
+type fuzzStruct struct {
+	Name  string        `json:"name,omitempty"`
+	Int   int8          `json:"int"`
+	Uint  uint16        `json:"uint"`
+	Float float64       `json:"float"`
+	Bytes []byte        `json:"bytes,base64"`
+	Time  time.Time     `json:"time,unix"`
+	Dur   time.Duration `json:"dur,seconds"`
+	Any   any           `json:"any"`
+	Ptr   *fuzzInner    `json:"ptr"`
+	Inner fuzzInner     `json:"inner"`
+	Norm  string        `json:"norm" norm:"trim,lower"`
+}
+
+// FuzzToMap checks that the conversion of arbitrary values does not panic,
+// and that the map converts back.
+func FuzzToMap(f *testing.F) {
+	f.Add("name", int64(1), 1.5, []byte("x"), `{"a":{"b":[1,2]}}`)
+	f.Add("", int64(-1), math.Inf(1), []byte(nil), `null`)
+	f.Add("\xff", int64(math.MaxInt64), math.NaN(), []byte{0}, `[{}]`)
+	f.Fuzz(func(t *testing.T, s string, n int64, fl float64, b []byte, js string) {
+		var anyv any
+		_ = json.Unmarshal([]byte(js), &anyv)
+		m, _ := anyv.(map[string]any)
+		a := fuzzStruct{
+			Name:  s,
+			Int:   int8(n),
+			Uint:  uint16(n),
+			Float: fl,
+			Bytes: b,
+			Time:  time.Unix(n%1e10, 0),
+			Dur:   time.Duration(n),
+			Any:   anyv,
+			Ptr:   &fuzzInner{N: int(n), M: m},
+			Inner: fuzzInner{S: s, M: m, SS: []string{s}},
+			Norm:  s,
+		}
+		for _, mp := range []Mapper{New(), New(Omitempty(), Flatten()), New(CollectErrors(), EncoderSafe())} {
+			out, err := mp.ToMapE(a)
+			if err != nil {
+				continue
+			}
+			var back fuzzStruct
+			_ = mp.FromMap(out, &back)
+			_, _ = mp.Values(a)
+		}
+		if m != nil {
+			_, _ = New().ToMapE(m)
+		}
+	})
+}
+
+// FuzzFromMap checks that populating a struct from arbitrary JSON does not
+// panic.
+func FuzzFromMap(f *testing.F) {
+	f.Add(`{"name":"x","int":1,"inner":{"n":2,"m":{"a":1}}}`)
+	f.Add(`{"int":1e300,"uint":-1,"float":"x","bytes":5,"time":"now"}`)
+	f.Add(`{"ptr":{"ss":[1,null]},"inner":[],"dur":1.5}`)
+	f.Fuzz(func(t *testing.T, js string) {
+		var src map[string]any
+		if err := json.Unmarshal([]byte(js), &src); err != nil {
+			return
+		}
+		for _, mp := range []Mapper{New(), New(Flatten(), ParseStrings()), New(CollectErrors())} {
+			var a fuzzStruct
+			_ = mp.FromMap(src, &a)
+		}
+	})
+}
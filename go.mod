@@ -2,10 +2,14 @@ module github.com/rusq/tagops
 
 go 1.23
 
-require github.com/stretchr/testify v1.9.0
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/google/go-cmp v0.7.0
+	github.com/stretchr/testify v1.9.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
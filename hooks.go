@@ -0,0 +1,82 @@
+package tagops
+
+import (
+	"context"
+	"reflect"
+	"slices"
+)
+
+// FieldInfo describes the struct field being mapped.
+type FieldInfo struct {
+	// Field is the struct field.
+	Field reflect.StructField
+	// Key is the resolved map key of the field.  For flattened structs it
+	// is the key that the struct would have had if it was not flattened.
+	Key string
+	// Path is the dot-separated path of Go field names from the root
+	// struct, i.e. "Address.ZIP".
+	Path string
+	// Options are the tag options, i.e. ["omitempty"] for `json:"x,omitempty"`.
+	Options []string
+}
+
+// newFieldInfo returns the FieldInfo for the field fld with the key, located
+// in the struct at parent path.
+func newFieldInfo(fld reflect.StructField, key string, parent string, tag string) FieldInfo {
+	return FieldInfo{
+		Field:   fld,
+		Key:     key,
+		Path:    joinPath(parent, fld.Name),
+		Options: tagOptions(fld, tag),
+	}
+}
+
+// joinPath joins the parent path and the field name.
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// HasOption returns true if the field tag has the option opt.
+func (fi FieldInfo) HasOption(opt string) bool {
+	return slices.Contains(fi.Options, opt)
+}
+
+// BeforeField returns an Option that sets the hook fn, that is called before
+// each struct field is mapped by ToMap, including nested structs.  The hook
+// receives the field information and the field value.  If fn returns true,
+// the field is skipped.  Fields that are skipped by tag rules are not passed
+// to the hook.
+func BeforeField(fn func(fi FieldInfo, v reflect.Value) (skip bool)) Option {
+	return BeforeFieldCtx(func(_ context.Context, fi FieldInfo, v reflect.Value) bool {
+		return fn(fi, v)
+	})
+}
+
+// BeforeFieldCtx is the context-aware variant of BeforeField.  fn receives the
+// context passed to ToMapCtx.
+func BeforeFieldCtx(fn func(ctx context.Context, fi FieldInfo, v reflect.Value) (skip bool)) Option {
+	return func(m *Mapper) {
+		m.beforeField = fn
+	}
+}
+
+// AfterStruct returns an Option that sets the hook fn, that is called after
+// each struct, including nested ones, is converted by ToMap.  fn receives the
+// resulting map and may modify it.  For nested structs the hook is called
+// before the map is merged into the parent map.
+func AfterStruct(fn func(m map[string]any)) Option {
+	return AfterStructCtx(func(_ context.Context, m map[string]any) {
+		fn(m)
+	})
+}
+
+// AfterStructCtx is the context-aware variant of AfterStruct.  fn receives the
+// context passed to ToMapCtx.
+func AfterStructCtx(fn func(ctx context.Context, m map[string]any)) Option {
+	return func(m *Mapper) {
+		m.afterStruct = fn
+	}
+}
@@ -0,0 +1,77 @@
+package tagops
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hookAddress struct {
+	Street string `json:"street,omitempty"`
+	ZIP    int    `json:"zip"`
+}
+
+type hookPerson struct {
+	Name     string      `json:"name"`
+	Password string      `json:"password,secret"`
+	Address  hookAddress `json:"address"`
+}
+
+func TestBeforeField(t *testing.T) {
+	var seen []string
+	m := New(BeforeField(func(fi FieldInfo, v reflect.Value) bool {
+		seen = append(seen, fi.Path+"="+fi.Key)
+		return fi.HasOption("secret")
+	}))
+	got := m.ToMap(hookPerson{
+		Name:     "John",
+		Password: "qwerty",
+		Address:  hookAddress{Street: "123 Main St", ZIP: 12345},
+	})
+	assert.Equal(t, map[string]any{
+		"name":    "John",
+		"address": map[string]any{"street": "123 Main St", "zip": 12345},
+	}, got)
+	assert.Equal(t, []string{
+		"Name=name",
+		"Password=password",
+		"Address=address",
+		"Address.Street=street",
+		"Address.ZIP=zip",
+	}, seen)
+}
+
+func TestBeforeField_skipNested(t *testing.T) {
+	m := New(Flatten(), BeforeField(func(fi FieldInfo, v reflect.Value) bool {
+		return fi.Path == "Address"
+	}))
+	got := m.ToMap(hookPerson{Name: "John"})
+	assert.Equal(t, map[string]any{"name": "John", "password": ""}, got)
+}
+
+func TestAfterStruct(t *testing.T) {
+	var calls int
+	m := New(AfterStruct(func(mp map[string]any) {
+		calls++
+		delete(mp, "password")
+		mp["fields"] = len(mp)
+	}))
+	got := m.ToMap(hookPerson{Name: "John", Address: hookAddress{ZIP: 1}})
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, map[string]any{
+		"name":    "John",
+		"address": map[string]any{"street": "", "zip": 1, "fields": 2},
+		"fields":  2,
+	}, got)
+}
+
+func Test_newFieldInfo(t *testing.T) {
+	fld := field(t, hookPerson{}, 1)
+	got := newFieldInfo(fld, "password", "User", "json")
+	assert.Equal(t, "User.Password", got.Path)
+	assert.Equal(t, "password", got.Key)
+	assert.Equal(t, []string{"secret"}, got.Options)
+	assert.True(t, got.HasOption("secret"))
+	assert.False(t, got.HasOption("omitempty"))
+}
@@ -0,0 +1,177 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// required tag option.
+const fRequired = "required"
+
+// ImportReport describes how the header row of the spreadsheet, or a CSV file,
+// maps to the fields of the struct.
+type ImportReport struct {
+	// Matched lists the columns that map to the fields.
+	Matched []ColumnMatch
+	// Unmatched lists the headers that have no field, including the
+	// duplicate headers.
+	Unmatched []string
+	// Missing lists the keys of the fields with the "required" tag option
+	// that have no column.
+	Missing []string
+}
+
+// ColumnMatch is the column that maps to the field.
+type ColumnMatch struct {
+	// Index is the index of the column.
+	Index int
+	// Header is the column header.
+	Header string
+	// Key is the dot-separated key path of the field, i.e. "address.city"
+	// for a nested struct.
+	Key string
+}
+
+// OK returns true if all required fields have columns.
+func (r ImportReport) OK() bool {
+	return len(r.Missing) == 0
+}
+
+// Importer decodes rows of string values into structs of one type.
+type Importer struct {
+	m        Mapper
+	typ      reflect.Type
+	report   ImportReport
+	required map[string]bool
+}
+
+// NewImporter matches the header row to the fields of the type of the struct,
+// or pointer to struct, a.  Headers match the key paths of the fields, that
+// ToMap would produce, ignoring case and surrounding spaces.  Nested structs
+// are addressed with dot-separated paths, unless flattened.  Fields may be
+// marked as required with the "required" tag option, i.e.
+// `json:"email,required"`.  Headers also match the aliases of the fields,
+// listed in the alias tag, i.e. `alias:"e-mail,mail"`.
+//
+// Inspect the Report before decoding the rows to show what will be imported.
+func (m Mapper) NewImporter(header []string, a any) (*Importer, error) {
+	typ := reflect.TypeOf(a)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrNotStruct, a)
+	}
+	fields := m.leafFields(reflect.New(typ).Elem(), "")
+	imp := &Importer{m: m.With(ParseStrings()), typ: typ, required: make(map[string]bool)}
+	matched := make(map[string]bool)
+	for i, h := range header {
+		idx := slices.IndexFunc(fields, func(f leafField) bool {
+			return f.matches(strings.TrimSpace(h))
+		})
+		if idx < 0 || matched[fields[idx].key] {
+			imp.report.Unmatched = append(imp.report.Unmatched, h)
+			continue
+		}
+		matched[fields[idx].key] = true
+		imp.report.Matched = append(imp.report.Matched, ColumnMatch{Index: i, Header: h, Key: fields[idx].key})
+	}
+	for _, f := range fields {
+		if !slices.Contains(f.opts, fRequired) {
+			continue
+		}
+		imp.required[f.key] = true
+		if !matched[f.key] {
+			imp.report.Missing = append(imp.report.Missing, f.key)
+		}
+	}
+	return imp, nil
+}
+
+// Report returns the column mapping report.
+func (imp *Importer) Report() ImportReport {
+	return imp.report
+}
+
+// Decode populates the struct pointed to by a with the values of the row.
+// The values are parsed into the field types, see ParseStrings.  Empty
+// values leave the fields unchanged.  The error wraps ErrFieldNotFound if
+// the value of a required field is empty, or its column is missing.
+func (imp *Importer) Decode(row []string, a any) error {
+	if reflect.TypeOf(a) != reflect.PointerTo(imp.typ) {
+		return fmt.Errorf("%w: expected *%s, got %T", ErrTypeMismatch, imp.typ, a)
+	}
+	if !imp.report.OK() {
+		return fmt.Errorf("%w: missing required columns: %s", ErrFieldNotFound, strings.Join(imp.report.Missing, ", "))
+	}
+	src := make(map[string]any)
+	for _, col := range imp.report.Matched {
+		if col.Index >= len(row) || row[col.Index] == "" {
+			if imp.required[col.Key] {
+				return fmt.Errorf("%w: %s: required value is empty", ErrFieldNotFound, col.Key)
+			}
+			continue
+		}
+		setPath(src, strings.Split(col.Key, "."), row[col.Index])
+	}
+	return imp.m.FromMap(src, a)
+}
+
+// leafField is the leaf field of the struct.
+type leafField struct {
+	// key is the dot-separated key path.
+	key  string
+	opts []string
+	// aliases are the alternative key paths, see aliasTag.
+	aliases []string
+}
+
+// matches returns true if the header h matches the key or one of the
+// aliases, ignoring case.
+func (f leafField) matches(h string) bool {
+	if strings.EqualFold(f.key, h) {
+		return true
+	}
+	return slices.ContainsFunc(f.aliases, func(a string) bool {
+		return strings.EqualFold(a, h)
+	})
+}
+
+// leafFields returns the leaf fields of the struct value v, with key paths
+// prefixed with prefix.
+func (m Mapper) leafFields(v reflect.Value, prefix string) []leafField {
+	var out []leafField
+	typ := v.Type()
+	m = m.withProfile(typ)
+	for i := range v.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		tag := m.tagFor(field)
+		if hasOption(field, tag, fReadOnly) {
+			continue
+		}
+		if isNested(field.Type) && m.flattens(field, tag) {
+			out = append(out, m.leafFields(fv, prefix)...)
+			continue
+		}
+		key, err := m.tagName(field, fv, tag, false)
+		if err != nil {
+			continue
+		}
+		if isNested(field.Type) {
+			out = append(out, m.leafFields(fv, prefix+key+".")...)
+			continue
+		}
+		lf := leafField{key: prefix + key, opts: tagOptions(field, tag)}
+		for _, a := range aliases(field) {
+			lf.aliases = append(lf.aliases, prefix+a)
+		}
+		out = append(out, lf)
+	}
+	return out
+}
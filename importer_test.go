@@ -0,0 +1,96 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type importAddress struct {
+	City string `json:"city"`
+	ZIP  string `json:"zip"`
+}
+
+type importUser struct {
+	ID      int           `json:"id,required"`
+	Email   string        `json:"email,required"`
+	Name    string        `json:"name"`
+	Joined  time.Time     `json:"joined"`
+	Active  bool          `json:"active"`
+	Address importAddress `json:"address"`
+}
+
+func TestMapper_NewImporter(t *testing.T) {
+	t.Run("aliases", func(t *testing.T) {
+		type address struct {
+			City string `json:"city" alias:"town"`
+		}
+		type user struct {
+			Email   string  `json:"email,required" alias:"e-mail, mail"`
+			Address address `json:"address"`
+		}
+		imp, err := New().NewImporter([]string{"Mail", "address.town", "email"}, user{})
+		require.NoError(t, err)
+		assert.Equal(t, ImportReport{
+			Matched: []ColumnMatch{
+				{Index: 0, Header: "Mail", Key: "email"},
+				{Index: 1, Header: "address.town", Key: "address.city"},
+			},
+			Unmatched: []string{"email"},
+		}, imp.Report())
+		var u user
+		require.NoError(t, imp.Decode([]string{"a@example.com", "Paris", "b@example.com"}, &u))
+		assert.Equal(t, user{Email: "a@example.com", Address: address{City: "Paris"}}, u)
+	})
+	t.Run("report", func(t *testing.T) {
+		imp, err := New().NewImporter([]string{" ID ", "Name", "address.city", "Phone", "name"}, importUser{})
+		require.NoError(t, err)
+		assert.Equal(t, ImportReport{
+			Matched: []ColumnMatch{
+				{Index: 0, Header: " ID ", Key: "id"},
+				{Index: 1, Header: "Name", Key: "name"},
+				{Index: 2, Header: "address.city", Key: "address.city"},
+			},
+			Unmatched: []string{"Phone", "name"},
+			Missing:   []string{"email"},
+		}, imp.Report())
+		assert.False(t, imp.Report().OK())
+
+		var u importUser
+		assert.ErrorIs(t, imp.Decode([]string{"1", "John"}, &u), ErrFieldNotFound)
+	})
+	t.Run("decode", func(t *testing.T) {
+		imp, err := New().NewImporter([]string{"email", "id", "active", "joined", "address.zip"}, &importUser{})
+		require.NoError(t, err)
+		require.True(t, imp.Report().OK())
+
+		var u importUser
+		require.NoError(t, imp.Decode([]string{"john@example.com", "7", "true", "2024-01-02T00:00:00Z", "12345"}, &u))
+		assert.Equal(t, importUser{
+			ID:      7,
+			Email:   "john@example.com",
+			Active:  true,
+			Joined:  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Address: importAddress{ZIP: "12345"},
+		}, u)
+
+		var fe *FieldError
+		require.ErrorAs(t, imp.Decode([]string{"x", "seven"}, &u), &fe)
+		assert.Equal(t, "ID", fe.Path)
+		assert.ErrorIs(t, imp.Decode([]string{"", "7"}, &u), ErrFieldNotFound)
+		assert.ErrorIs(t, imp.Decode([]string{"x", "7"}, u), ErrTypeMismatch)
+	})
+	t.Run("flatten", func(t *testing.T) {
+		imp, err := New(Flatten()).NewImporter([]string{"id", "email", "city"}, importUser{})
+		require.NoError(t, err)
+		var u importUser
+		require.NoError(t, imp.Decode([]string{"1", "a@b", "Anytown"}, &u))
+		assert.Equal(t, "Anytown", u.Address.City)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := New().NewImporter(nil, 42)
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+}
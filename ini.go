@@ -0,0 +1,229 @@
+package tagops
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// INITag is the struct tag used by the package-level INI and properties
+// functions.
+const INITag = "ini"
+
+// EncodeINI writes the struct a to w in the INI format, with keys from the
+// "ini" tag, see Mapper.EncodeINI.
+func EncodeINI(w io.Writer, a any) error {
+	return New(Tag(INITag)).EncodeINI(w, a)
+}
+
+// DecodeINI populates the struct pointed to by a from the INI document in r,
+// with keys from the "ini" tag, see Mapper.DecodeINI.
+func DecodeINI(r io.Reader, a any) error {
+	return New(Tag(INITag)).DecodeINI(r, a)
+}
+
+// EncodeProperties writes the struct a to w in the properties format, with
+// keys from the "ini" tag, see Mapper.EncodeProperties.
+func EncodeProperties(w io.Writer, a any) error {
+	return New(Tag(INITag)).EncodeProperties(w, a)
+}
+
+// DecodeProperties populates the struct pointed to by a from the properties
+// document in r, with keys from the "ini" tag, see Mapper.DecodeProperties.
+func DecodeProperties(r io.Reader, a any) error {
+	return New(Tag(INITag)).DecodeProperties(r, a)
+}
+
+// EncodeINI writes the struct a to w in the INI format.  The leaf fields of
+// the struct are written first, without a section, then each nested struct is
+// written as the [section], named after its key.  The structs nested deeper
+// are written as the sections with the dot-separated names, i.e. [db.replica].
+// Flattened structs are written in the section of the parent.  The keys are
+// sorted within the section.
+//
+// The values are formatted as in ToKV, and are quoted as Go strings, if they
+// have leading or trailing spaces, line breaks, or the comment characters.
+func (m Mapper) EncodeINI(w io.Writer, a any) error {
+	mp, err := m.ToMapE(a)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	if err := m.writeINISection(bw, "", mp, true); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeINISection writes the leaf values of mp under the section name, and
+// then the nested maps as the subsections.  first is true, if nothing was
+// written yet.
+func (m Mapper) writeINISection(w *bufio.Writer, name string, mp map[string]any, first bool) error {
+	var (
+		leaves = make(map[string]string)
+		nested []string
+	)
+	for _, k := range Keys(mp) {
+		if _, ok := mp[k].(map[string]any); ok {
+			nested = append(nested, k)
+			continue
+		}
+		s, ok, err := m.kvString(mp[k])
+		if err != nil {
+			return fmt.Errorf("%s: %w", joinKey(name, k), err)
+		}
+		if ok {
+			leaves[k] = s
+		}
+	}
+	if len(leaves) > 0 && name != "" {
+		if !first {
+			w.WriteByte('\n')
+		}
+		fmt.Fprintf(w, "[%s]\n", name)
+	}
+	for _, k := range slices.Sorted(maps.Keys(leaves)) {
+		fmt.Fprintf(w, "%s = %s\n", k, quoteINI(leaves[k]))
+	}
+	first = first && len(leaves) == 0
+	for _, k := range nested {
+		if err := m.writeINISection(w, joinKey(name, k), mp[k].(map[string]any), first); err != nil {
+			return err
+		}
+		first = false
+	}
+	return nil
+}
+
+// DecodeINI populates the struct pointed to by a from the INI document in r,
+// written by EncodeINI.  The keys before the first section are the keys of
+// the root struct, the keys in the [section] are the keys of the nested
+// struct with the section key, and [a.b] is the section of the struct b
+// nested in a.  Lines starting with ";" or "#" are comments.  The values are
+// parsed into the field types, see ParseStrings.
+func (m Mapper) DecodeINI(r io.Reader, a any) error {
+	src := make(map[string]any)
+	var section []string
+	err := scanLines(r, func(line string) error {
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(line[1:], "]")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid section %q", line)
+			}
+			section = strings.Split(strings.TrimSpace(name), ".")
+			return nil
+		}
+		key, val, err := parseKeyValue(line, "=")
+		if err != nil {
+			return err
+		}
+		setPath(src, append(section[:len(section):len(section)], key), val)
+		return nil
+	}, ";", "#")
+	if err != nil {
+		return err
+	}
+	return m.With(ParseStrings()).FromMap(src, a)
+}
+
+// EncodeProperties writes the struct a to w in the properties format, one
+// "key = value" line for each leaf value, with the keys of nested structs
+// joined with ".", i.e. "db.host = localhost".  The lines are sorted by key.
+// The values are formatted and quoted as in EncodeINI.
+func (m Mapper) EncodeProperties(w io.Writer, a any) error {
+	kv, err := m.ToKV(a, "", ".")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for _, k := range slices.Sorted(maps.Keys(kv)) {
+		fmt.Fprintf(bw, "%s = %s\n", k, quoteINI(kv[k]))
+	}
+	return bw.Flush()
+}
+
+// DecodeProperties populates the struct pointed to by a from the properties
+// document in r, written by EncodeProperties.  The key and the value are
+// separated with "=" or ":".  Lines starting with "#" or "!" are comments.
+func (m Mapper) DecodeProperties(r io.Reader, a any) error {
+	kv := make(map[string]string)
+	err := scanLines(r, func(line string) error {
+		key, val, err := parseKeyValue(line, "=:")
+		if err != nil {
+			return err
+		}
+		kv[key] = val
+		return nil
+	}, "#", "!")
+	if err != nil {
+		return err
+	}
+	return m.FromKV(kv, "", ".", a)
+}
+
+// scanLines calls fn for each trimmed line of r, that is not empty and does
+// not start with one of the comment prefixes.  The errors are prefixed with
+// the line number.
+func scanLines(r io.Reader, fn func(line string) error, comments ...string) error {
+	sc := bufio.NewScanner(r)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || hasAnyPrefix(line, comments) {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return sc.Err()
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseKeyValue splits the line into the key and the unquoted value at the
+// first of the separator characters seps.
+func parseKeyValue(line string, seps string) (key, val string, err error) {
+	i := strings.IndexAny(line, seps)
+	if i < 0 {
+		return "", "", fmt.Errorf("expected key%cvalue: %q", seps[0], line)
+	}
+	key, val = strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+	if key == "" {
+		return "", "", fmt.Errorf("empty key: %q", line)
+	}
+	if strings.HasPrefix(val, `"`) {
+		if val, err = strconv.Unquote(val); err != nil {
+			return "", "", fmt.Errorf("%s: invalid quoted value: %w", key, err)
+		}
+	}
+	return key, val, nil
+}
+
+// quoteINI returns s quoted as Go string, if it can not be written as is.
+func quoteINI(s string) string {
+	if s != strings.TrimSpace(s) || strings.HasPrefix(s, `"`) || strings.ContainsAny(s, "\n\r;#!") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// joinKey joins the section name and the key with ".".
+func joinKey(name, key string) string {
+	if name == "" {
+		return key
+	}
+	return name + "." + key
+}
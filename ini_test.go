@@ -0,0 +1,112 @@
+package tagops
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type iniReplica struct {
+	Host string `ini:"host"`
+}
+
+type iniDB struct {
+	Host    string     `ini:"host"`
+	Port    int        `ini:"port"`
+	Replica iniReplica `ini:"replica"`
+}
+
+type iniConfig struct {
+	Name  string   `ini:"name"`
+	Debug bool     `ini:"debug"`
+	Motd  string   `ini:"motd"`
+	Tags  []string `ini:"tags"`
+	DB    iniDB    `ini:"db"`
+}
+
+var iniSample = iniConfig{
+	Name:  "app",
+	Debug: true,
+	Motd:  " hello; world ",
+	Tags:  []string{"a", "b"},
+	DB:    iniDB{Host: "localhost", Port: 5432, Replica: iniReplica{Host: "r1"}},
+}
+
+func TestEncodeINI(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeINI(&buf, iniSample))
+	want := `debug = true
+motd = " hello; world "
+name = app
+tags = ["a","b"]
+
+[db]
+host = localhost
+port = 5432
+
+[db.replica]
+host = r1
+`
+	assert.Equal(t, want, buf.String())
+}
+
+func TestDecodeINI(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeINI(&buf, iniSample))
+		var got iniConfig
+		require.NoError(t, DecodeINI(&buf, &got))
+		assert.Equal(t, iniSample, got)
+	})
+	t.Run("comments and separators", func(t *testing.T) {
+		src := "; comment\nname=app\n# another\n\n[db.replica]\nhost =  r2 \n[db]\nport = 1\n"
+		var got iniConfig
+		require.NoError(t, DecodeINI(strings.NewReader(src), &got))
+		assert.Equal(t, iniConfig{Name: "app", DB: iniDB{Port: 1, Replica: iniReplica{Host: "r2"}}}, got)
+	})
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			src  string
+			want string
+		}{
+			{"no separator", "name\n", "line 1: expected key=value"},
+			{"bad section", "name=x\n[db\n", "line 2: invalid section"},
+			{"bad quote", `motd = "abc`, "line 1: motd: invalid quoted value"},
+			{"bad value", "[db]\nport = x\n", "DB.Port"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var got iniConfig
+				err := DecodeINI(strings.NewReader(tt.src), &got)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+	})
+}
+
+func TestProperties(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeProperties(&buf, iniSample))
+	want := `db.host = localhost
+db.port = 5432
+db.replica.host = r1
+debug = true
+motd = " hello; world "
+name = app
+tags = ["a","b"]
+`
+	assert.Equal(t, want, buf.String())
+
+	var got iniConfig
+	require.NoError(t, DecodeProperties(&buf, &got))
+	assert.Equal(t, iniSample, got)
+
+	got = iniConfig{}
+	require.NoError(t, DecodeProperties(strings.NewReader("! comment\nname: app\n"), &got))
+	assert.Equal(t, "app", got.Name)
+}
@@ -0,0 +1,36 @@
+package tagops
+
+import "sync"
+
+// maxInterned is the maximum number of keys cached per key function, so that
+// the maps with arbitrary keys, see ToMap, don't grow the cache unboundedly.
+const maxInterned = 4096
+
+// internKeys returns the function that caches the keys returned by fn, so
+// that the key of each untagged field is derived once, and the repeated
+// conversions share the same key strings instead of allocating them on every
+// call.  fn must return the same key for the same name.
+func internKeys(fn func(name string) string) func(name string) string {
+	if fn == nil {
+		return nil
+	}
+	var (
+		mu   sync.RWMutex
+		keys = make(map[string]string)
+	)
+	return func(name string) string {
+		mu.RLock()
+		key, ok := keys[name]
+		mu.RUnlock()
+		if ok {
+			return key
+		}
+		key = fn(name)
+		mu.Lock()
+		if len(keys) < maxInterned {
+			keys[name] = key
+		}
+		mu.Unlock()
+		return key
+	}
+}
@@ -0,0 +1,53 @@
+package tagops
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyFunc_interned(t *testing.T) {
+	type S struct {
+		FirstName string
+		LastName  string
+	}
+	var calls int
+	m := New(KeyFunc(func(name string) string {
+		calls++
+		return strings.ToLower(name)
+	}))
+	first := m.ToMap(S{"ann", "lee"})
+	for range 10 {
+		assert.Equal(t, first, m.ToMap(S{"ann", "lee"}))
+	}
+	assert.Equal(t, 2, calls)
+
+	var k1, k2 string
+	for k := range first {
+		k1 = k
+	}
+	for k := range m.ToMap(S{}) {
+		if k == k1 {
+			k2 = k
+		}
+	}
+	assert.Same(t, unsafe.StringData(k1), unsafe.StringData(k2))
+}
+
+func TestInternKeys_limit(t *testing.T) {
+	var calls int
+	fn := internKeys(func(name string) string {
+		calls++
+		return name
+	})
+	for i := range maxInterned + 10 {
+		fn(fmt.Sprint(i))
+	}
+	fn("0")
+	fn(fmt.Sprint(maxInterned + 1))
+	assert.Equal(t, maxInterned+11, calls, "keys over the limit are not cached")
+	assert.Nil(t, internKeys(nil))
+}
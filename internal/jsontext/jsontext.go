@@ -0,0 +1,14 @@
+// Package jsontext is the stub of the encoding/json/jsontext package for
+// the tests of the json/v2 interfaces, so that tagops does not depend on
+// json/v2.
+package jsontext
+
+// Encoder is the stub of jsontext.Encoder.
+type Encoder struct {
+	Out []byte
+}
+
+// Decoder is the stub of jsontext.Decoder.
+type Decoder struct {
+	In []byte
+}
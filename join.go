@@ -0,0 +1,86 @@
+package tagops
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Join returns the inner join of the slices of structs left and right, see
+// Mapper.Join.  It returns nil on error.
+func Join(left, right any, leftTag, rightTag string) []map[string]any {
+	out, _ := New().Join(left, right, leftTag, rightTag)
+	return out
+}
+
+// LeftJoin returns the left outer join of the slices of structs left and
+// right, see Mapper.LeftJoin.  It returns nil on error.
+func LeftJoin(left, right any, leftTag, rightTag string) []map[string]any {
+	out, _ := New().LeftJoin(left, right, leftTag, rightTag)
+	return out
+}
+
+// Join returns the inner join of the slices of structs left and right, where
+// the value of the field with the tag name leftTag of the left element equals
+// the value of the field with the tag name rightTag of the right element.
+// Each row is the map of the left element merged with the map of the right
+// element, if the key is present in both maps, the left value is kept.  The
+// rows are in the order of the left slice, and then of the right slice.  The
+// key values are compared as is, so that they must be of the same type to
+// match.
+func (m Mapper) Join(left, right any, leftTag, rightTag string) ([]map[string]any, error) {
+	return m.join(left, right, leftTag, rightTag, false)
+}
+
+// LeftJoin is like Join, but the left elements without matching right
+// elements are included with their own map only.
+func (m Mapper) LeftJoin(left, right any, leftTag, rightTag string) ([]map[string]any, error) {
+	return m.join(left, right, leftTag, rightTag, true)
+}
+
+func (m Mapper) join(left, right any, leftTag, rightTag string, outer bool) ([]map[string]any, error) {
+	lv, err := sliceValue(left)
+	if err != nil {
+		return nil, fmt.Errorf("left: %w", err)
+	}
+	rv, err := sliceValue(right)
+	if err != nil {
+		return nil, fmt.Errorf("right: %w", err)
+	}
+	index := make(map[any][]map[string]any, rv.Len())
+	for i := range rv.Len() {
+		key, err := m.elemKey(rv.Index(i), rightTag)
+		if err != nil {
+			return nil, fmt.Errorf("right element %d: %w", i, err)
+		}
+		mp, err := m.ToMapE(rv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("right element %d: %w", i, err)
+		}
+		index[key] = append(index[key], mp)
+	}
+	var out []map[string]any
+	for i := range lv.Len() {
+		key, err := m.elemKey(lv.Index(i), leftTag)
+		if err != nil {
+			return nil, fmt.Errorf("left element %d: %w", i, err)
+		}
+		matches := index[key]
+		if len(matches) == 0 && !outer {
+			continue
+		}
+		mp, err := m.ToMapE(lv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("left element %d: %w", i, err)
+		}
+		if len(matches) == 0 {
+			out = append(out, mp)
+			continue
+		}
+		for _, rmp := range matches {
+			row := maps.Clone(rmp)
+			maps.Copy(row, mp)
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type joinUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type joinOrder struct {
+	ID     int    `json:"order_id"`
+	UserID int    `json:"user_id"`
+	Item   string `json:"item"`
+	Name   string `json:"name"`
+}
+
+var (
+	joinUsers = []joinUser{
+		{1, "John"},
+		{2, "Jane"},
+		{3, "Bob"},
+	}
+	joinOrders = []joinOrder{
+		{10, 2, "book", "gift"},
+		{11, 1, "pen", ""},
+		{12, 2, "lamp", ""},
+	}
+)
+
+func TestJoin(t *testing.T) {
+	got := Join(joinUsers, joinOrders, "id", "user_id")
+	assert.Equal(t, []map[string]any{
+		{"id": 1, "name": "John", "order_id": 11, "user_id": 1, "item": "pen"},
+		{"id": 2, "name": "Jane", "order_id": 10, "user_id": 2, "item": "book"},
+		{"id": 2, "name": "Jane", "order_id": 12, "user_id": 2, "item": "lamp"},
+	}, got)
+
+	assert.Nil(t, Join(joinUsers, []joinOrder{}, "id", "user_id"))
+	assert.Nil(t, Join(joinUsers, joinOrders, "id", "missing"))
+}
+
+func TestLeftJoin(t *testing.T) {
+	got := LeftJoin(joinUsers, joinOrders, "id", "user_id")
+	assert.Equal(t, []map[string]any{
+		{"id": 1, "name": "John", "order_id": 11, "user_id": 1, "item": "pen"},
+		{"id": 2, "name": "Jane", "order_id": 10, "user_id": 2, "item": "book"},
+		{"id": 2, "name": "Jane", "order_id": 12, "user_id": 2, "item": "lamp"},
+		{"id": 3, "name": "Bob"},
+	}, got)
+}
+
+func TestMapper_Join_errors(t *testing.T) {
+	m := New()
+	_, err := m.Join(42, joinOrders, "id", "user_id")
+	assert.ErrorIs(t, err, ErrNotSlice)
+	_, err = m.Join(joinUsers, 42, "id", "user_id")
+	assert.ErrorIs(t, err, ErrNotSlice)
+	_, err = m.Join(joinUsers, joinOrders, "missing", "user_id")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	_, err = m.LeftJoin(joinUsers, joinOrders, "id", "missing")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+}
@@ -0,0 +1,63 @@
+package tagops
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteJSONL writes the elements of the slice of structs to w in the JSON
+// Lines format, one object per line.  The objects are the maps returned by
+// ToMapE of the Mapper configured with options opts, so that the keys,
+// flattening and omitempty follow the same rules as the rest of the package.
+func WriteJSONL(w io.Writer, slice any, opts ...Option) error {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return err
+	}
+	m := New(opts...)
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for i := range sv.Len() {
+		mp, err := m.ToMapE(sv.Index(i).Interface())
+		if err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		if err := enc.Encode(mp); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return bw.Flush()
+}
+
+// JSONLDecoder reads structs from the JSON Lines stream.
+type JSONLDecoder struct {
+	m   Mapper
+	dec *json.Decoder
+	n   int
+}
+
+// NewJSONLDecoder returns the decoder that reads from r, and populates
+// structs with the Mapper configured with options opts, see FromMap.
+func NewJSONLDecoder(r io.Reader, opts ...Option) *JSONLDecoder {
+	return &JSONLDecoder{m: New(opts...), dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next object from the stream and populates the struct
+// pointed to by a.  It returns io.EOF at the end of the stream.  Numbers are
+// decoded as float64, and converted to the field type, as with FromMap.
+func (d *JSONLDecoder) Decode(a any) error {
+	var mp map[string]any
+	if err := d.dec.Decode(&mp); err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("object %d: %w", d.n+1, err)
+	}
+	d.n++
+	if err := d.m.FromMap(mp, a); err != nil {
+		return fmt.Errorf("object %d: %w", d.n, err)
+	}
+	return nil
+}
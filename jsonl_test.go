@@ -0,0 +1,71 @@
+package tagops
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonlAddress struct {
+	City string `json:"city"`
+}
+
+type jsonlUser struct {
+	ID      int          `json:"id"`
+	Name    string       `json:"name,omitempty"`
+	Address jsonlAddress `json:"address"`
+}
+
+func TestWriteJSONL(t *testing.T) {
+	users := []jsonlUser{
+		{ID: 1, Name: "John", Address: jsonlAddress{"Anytown"}},
+		{ID: 2, Address: jsonlAddress{"Othertown"}},
+	}
+	t.Run("flatten, omitempty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteJSONL(&buf, users, Flatten(), Omitempty()))
+		assert.Equal(t, `{"city":"Anytown","id":1,"name":"John"}`+"\n"+`{"city":"Othertown","id":2}`+"\n", buf.String())
+	})
+	t.Run("round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteJSONL(&buf, &users))
+
+		dec := NewJSONLDecoder(&buf)
+		var got []jsonlUser
+		for {
+			var u jsonlUser
+			err := dec.Decode(&u)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			got = append(got, u)
+		}
+		assert.Equal(t, users, got)
+	})
+	t.Run("not a slice", func(t *testing.T) {
+		assert.ErrorIs(t, WriteJSONL(io.Discard, users[0]), ErrNotSlice)
+	})
+}
+
+func TestJSONLDecoder_Decode(t *testing.T) {
+	t.Run("flattened", func(t *testing.T) {
+		dec := NewJSONLDecoder(strings.NewReader(`{"id":1,"city":"Anytown"}`), Flatten())
+		var u jsonlUser
+		require.NoError(t, dec.Decode(&u))
+		assert.Equal(t, jsonlUser{ID: 1, Address: jsonlAddress{"Anytown"}}, u)
+		assert.ErrorIs(t, dec.Decode(&u), io.EOF)
+	})
+	t.Run("errors have object numbers", func(t *testing.T) {
+		dec := NewJSONLDecoder(strings.NewReader("{\"id\":1}\n{\"id\":\"x\"}\n{"))
+		var u jsonlUser
+		require.NoError(t, dec.Decode(&u))
+		assert.ErrorContains(t, dec.Decode(&u), "object 2: ID:")
+		assert.ErrorContains(t, dec.Decode(&u), "object 3:")
+	})
+}
@@ -0,0 +1,410 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrTestFailed is returned by ApplyJSONPatch, when the "test" operation
+// fails.
+var ErrTestFailed = errors.New("test failed")
+
+// Op is the JSON Patch (RFC 6902) operation.  It may be decoded from the
+// JSON patch document with encoding/json.
+type Op struct {
+	// Op is the operation: "add", "remove", "replace", "move", "copy" or
+	// "test".
+	Op string `json:"op"`
+	// Path is the JSON pointer (RFC 6901) to the target, i.e. "/address/city".
+	Path string `json:"path"`
+	// From is the JSON pointer to the source of "move" and "copy".
+	From string `json:"from,omitempty"`
+	// Value is the value of "add", "replace" and "test".
+	Value any `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch applies the JSON Patch operations to the struct pointed to
+// by dst, see Mapper.ApplyJSONPatch.
+func ApplyJSONPatch(dst any, ops []Op) error {
+	return New().ApplyJSONPatch(dst, ops)
+}
+
+// ApplyJSONPatch applies the JSON Patch (RFC 6902) operations ops to the
+// struct pointed to by dst.  The path tokens are resolved through the tag
+// names for the struct fields, as in FromMap, through indexes for slices and
+// arrays, and through keys for maps.  The values are converted to the target
+// types as in FromMap, so the patch decoded from JSON may be applied.
+//
+// On the struct field, "add" is the same as "replace", and "remove" resets the
+// field to the zero value.  On slices, "add" inserts the element, or appends
+// it, if the index is "-", and "remove" deletes it.  The operations are
+// applied in order, and it stops at the first error, the operations applied
+// before it are not rolled back.  The failed "test" operation returns
+// ErrTestFailed, and the operations that modify the read-only field, or its
+// contents, return ErrReadOnly.
+func (m Mapper) ApplyJSONPatch(dst any, ops []Op) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected non-nil pointer to struct, got %T", ErrNotStruct, dst)
+	}
+	for i, op := range ops {
+		if err := m.applyOp(v.Elem(), op); err != nil {
+			return fmt.Errorf("op %d: %s %q: %w", i, op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+// applyOp applies the operation op to the root struct value v.
+func (m Mapper) applyOp(v reflect.Value, op Op) error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	if op.Op != "test" {
+		if err := m.checkWritable(v.Type(), path); err != nil {
+			return err
+		}
+	}
+	switch op.Op {
+	case "add", "replace":
+		return m.atPointer(v, path, func(c reflect.Value, tok string) error {
+			return m.patchSet(c, tok, op.Value, op.Op == "add", true)
+		})
+	case "remove":
+		return m.atPointer(v, path, m.patchRemove)
+	case "test":
+		return m.atPointer(v, path, func(c reflect.Value, tok string) error {
+			got, err := m.patchGet(c, tok)
+			if err != nil {
+				return err
+			}
+			want := reflect.New(got.Type()).Elem()
+			if err := m.patchValue(want, op.Value, op.Path, m.patchField(c, tok)); err != nil || !reflect.DeepEqual(want.Interface(), got.Interface()) {
+				return ErrTestFailed
+			}
+			return nil
+		})
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" && len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+			return fmt.Errorf("can not move %q into its child", op.From)
+		}
+		var val any
+		if err := m.atPointer(v, from, func(c reflect.Value, tok string) error {
+			got, err := m.patchGet(c, tok)
+			if err == nil && op.Op == "copy" {
+				// the copy must not share the maps and slices with the source.
+				got = deepCopy(got)
+			}
+			if err == nil {
+				val = got.Interface()
+			}
+			return err
+		}); err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		if op.Op == "move" {
+			if err := m.checkWritable(v.Type(), from); err != nil {
+				return fmt.Errorf("from: %w", err)
+			}
+			if err := m.atPointer(v, from, m.patchRemove); err != nil {
+				return fmt.Errorf("from: %w", err)
+			}
+		}
+		return m.atPointer(v, path, func(c reflect.Value, tok string) error {
+			return m.patchSet(c, tok, val, true, false)
+		})
+	}
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// checkWritable returns an error wrapping ErrReadOnly, if the path goes
+// through a read-only field of the struct type t.
+func (m Mapper) checkWritable(t reflect.Type, path []string) error {
+	if ro, ok := m.readOnlyPath(t, path); ok {
+		return fmt.Errorf("%w: /%s", ErrReadOnly, ro)
+	}
+	return nil
+}
+
+// pointerUnescaper unescapes the JSON pointer tokens.
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// parsePointer parses the JSON pointer p into the tokens.  The root pointer
+// "" is not supported, as the struct can not be replaced.
+func parsePointer(p string) ([]string, error) {
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", p)
+	}
+	toks := strings.Split(p[1:], "/")
+	for i, tok := range toks {
+		toks[i] = pointerUnescaper.Replace(tok)
+	}
+	return toks, nil
+}
+
+// atPointer calls fn with the container value, that holds the target of the
+// path, and the last token of the path.  The container is settable.  The
+// values of maps and interfaces are copied, and set back after fn returns
+// without an error.
+func (m Mapper) atPointer(v reflect.Value, path []string, fn func(c reflect.Value, tok string) error) error {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return fmt.Errorf("%w: %q is nil", ErrFieldNotFound, path[0])
+		}
+		if v.Kind() == reflect.Ptr {
+			return m.atPointer(v.Elem(), path, fn)
+		}
+		inner := reflect.New(v.Elem().Type()).Elem()
+		inner.Set(v.Elem())
+		if err := m.atPointer(inner, path, fn); err != nil {
+			return err
+		}
+		v.Set(inner)
+		return nil
+	}
+	if len(path) == 1 {
+		return fn(v, path[0])
+	}
+	child, err := m.patchGet(v, path[0])
+	if err != nil {
+		return err
+	}
+	if v.Kind() != reflect.Map {
+		return m.atPointer(child, path[1:], fn)
+	}
+	elem := reflect.New(child.Type()).Elem()
+	elem.Set(child)
+	if err := m.atPointer(elem, path[1:], fn); err != nil {
+		return err
+	}
+	v.SetMapIndex(reflect.ValueOf(path[0]).Convert(v.Type().Key()), elem)
+	return nil
+}
+
+// patchGet returns the value at the token tok of the container c.
+func (m Mapper) patchGet(c reflect.Value, tok string) (reflect.Value, error) {
+	switch c.Kind() {
+	case reflect.Struct:
+		fv, ok := m.fieldByKey(c, tok)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("%w: %q", ErrFieldNotFound, tok)
+		}
+		return fv, nil
+	case reflect.Slice, reflect.Array:
+		i, err := patchIndex(c, tok, false)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return c.Index(i), nil
+	case reflect.Map:
+		if c.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnsupportedKind, c.Type())
+		}
+		ev := c.MapIndex(reflect.ValueOf(tok).Convert(c.Type().Key()))
+		if !ev.IsValid() {
+			return reflect.Value{}, fmt.Errorf("%w: %q", ErrFieldNotFound, tok)
+		}
+		return ev, nil
+	}
+	return reflect.Value{}, fmt.Errorf("%w: %q: %s has no members", ErrFieldNotFound, tok, c.Type())
+}
+
+// patchIndex returns the index tok of the slice or array c.  If insert is
+// true, the index may be equal to the length, and "-" is the length.
+func patchIndex(c reflect.Value, tok string, insert bool) (int, error) {
+	n := c.Len()
+	if insert && tok == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (tok != "0" && strings.HasPrefix(tok, "0")) {
+		return 0, fmt.Errorf("invalid index %q", tok)
+	}
+	if i > n || (i == n && !insert) {
+		return 0, fmt.Errorf("index %d out of range", i)
+	}
+	return i, nil
+}
+
+// patchSet sets the value at the token tok of the container c to val.  If
+// insert is true, the slice element is inserted, and the map key may be
+// absent.  If decode is true, val is the patch value, and the tag options
+// and the decryption of the struct field apply to it, as in FromMap,
+// otherwise it is the Go value of the copied or moved field.
+func (m Mapper) patchSet(c reflect.Value, tok string, val any, insert, decode bool) error {
+	switch c.Kind() {
+	case reflect.Slice:
+		i, err := patchIndex(c, tok, insert)
+		if err != nil {
+			return err
+		}
+		if !insert {
+			return m.patchValue(c.Index(i), val, tok, nil)
+		}
+		elem := reflect.New(c.Type().Elem()).Elem()
+		if err := m.patchValue(elem, val, tok, nil); err != nil {
+			return err
+		}
+		out := reflect.MakeSlice(c.Type(), 0, c.Len()+1)
+		out = reflect.AppendSlice(out, c.Slice(0, i))
+		out = reflect.Append(out, elem)
+		c.Set(reflect.AppendSlice(out, c.Slice(i, c.Len())))
+		return nil
+	case reflect.Map:
+		if !insert {
+			if _, err := m.patchGet(c, tok); err != nil {
+				return err
+			}
+		}
+		if c.IsNil() {
+			c.Set(reflect.MakeMap(c.Type()))
+		}
+		elem := reflect.New(c.Type().Elem()).Elem()
+		if err := m.patchValue(elem, val, tok, nil); err != nil {
+			return err
+		}
+		c.SetMapIndex(reflect.ValueOf(tok).Convert(c.Type().Key()), elem)
+		return nil
+	}
+	fv, err := m.patchGet(c, tok)
+	if err != nil {
+		return err
+	}
+	var fi *FieldInfo
+	if decode {
+		fi = m.patchField(c, tok)
+	}
+	return m.patchValue(fv, val, tok, fi)
+}
+
+// patchField returns the struct field at the token tok of the container c,
+// or nil, if c is not a struct.
+func (m Mapper) patchField(c reflect.Value, tok string) *FieldInfo {
+	if c.Kind() != reflect.Struct {
+		return nil
+	}
+	f, ok := m.lookupField(c.Type(), tok)
+	if !ok {
+		return nil
+	}
+	return &f.fi
+}
+
+// patchRemove removes the value at the token tok of the container c.
+func (m Mapper) patchRemove(c reflect.Value, tok string) error {
+	fv, err := m.patchGet(c, tok)
+	if err != nil {
+		return err
+	}
+	switch c.Kind() {
+	case reflect.Slice:
+		i, _ := patchIndex(c, tok, false)
+		out := reflect.MakeSlice(c.Type(), 0, c.Len()-1)
+		out = reflect.AppendSlice(out, c.Slice(0, i))
+		c.Set(reflect.AppendSlice(out, c.Slice(i+1, c.Len())))
+	case reflect.Map:
+		c.SetMapIndex(reflect.ValueOf(tok).Convert(c.Type().Key()), reflect.Value{})
+	case reflect.Array:
+		return fmt.Errorf("can not remove the element of %s", c.Type())
+	default:
+		fv.SetZero()
+	}
+	return nil
+}
+
+// patchValue sets fv to the value val, converted as in FromMap.  If fi is not
+// nil, fv is the struct field fi, and val is decrypted, normalized and
+// converted with the field tag options.  The nested structs are replaced, not
+// merged.
+func (m Mapper) patchValue(fv reflect.Value, val any, path string, fi *FieldInfo) error {
+	st := newState(context.Background())
+	var opts []string
+	if fi != nil {
+		var err error
+		if val, err = m.decryptField(fi.Field, fi.Key, val); err != nil {
+			return err
+		}
+		if val, err = normalize(fi.Field, val); err != nil {
+			return err
+		}
+		opts = fi.Options
+	}
+	tmp := reflect.New(fv.Type()).Elem()
+	nested, isMap := val.(map[string]any)
+	switch {
+	case fi != nil && isOptional(tmp.Type()):
+		m.optionalIn(st, tmp, val, opts, path)
+	case isMap && isNested(tmp.Type()):
+		m.fromMap(st, nested, tmp, path)
+	case isMap && isNestedPtr(tmp.Type()):
+		tmp.Set(reflect.New(tmp.Type().Elem()))
+		m.fromMap(st, nested, tmp.Elem(), path)
+	default:
+		if ok, _ := m.fromCollection(st, tmp, val, path); !ok {
+			if err := m.assign(st.ctx, tmp, val, opts); err != nil {
+				return err
+			}
+		}
+	}
+	if err := st.err(m.collectErrors); err != nil {
+		return err
+	}
+	fv.Set(tmp)
+	return nil
+}
+
+// deepCopy returns the copy of v, that does not share the maps, slices and
+// pointers with it.  Unexported struct fields are copied as is.
+func deepCopy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			out.Set(reflect.New(v.Type().Elem()))
+			out.Elem().Set(deepCopy(v.Elem()))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			out.Set(deepCopy(v.Elem()))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			out.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			for it := v.MapRange(); it.Next(); {
+				out.SetMapIndex(it.Key(), deepCopy(it.Value()))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := range v.Len() {
+				out.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Struct:
+		out.Set(v)
+		for i := range v.NumField() {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i)))
+			}
+		}
+	default:
+		out.Set(v)
+	}
+	return out
+}
@@ -0,0 +1,192 @@
+package tagops
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jpAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type jpUser struct {
+	Name    string            `json:"name"`
+	Age     int               `json:"age"`
+	Nick    *string           `json:"nick"`
+	Tags    []string          `json:"tags"`
+	Address jpAddress         `json:"address"`
+	Homes   []jpAddress       `json:"homes"`
+	Labels  map[string]string `json:"labels"`
+	Extra   map[string]any    `json:"extra"`
+	Weird   string            `json:"a/b~c"`
+}
+
+func newJPUser() jpUser {
+	return jpUser{
+		Name:    "Bob",
+		Age:     42,
+		Tags:    []string{"a", "b"},
+		Address: jpAddress{City: "Paris", Zip: "75001"},
+		Homes:   []jpAddress{{City: "Lyon"}},
+		Labels:  map[string]string{"env": "prod"},
+		Extra:   map[string]any{"list": []any{1.0, 2.0}, "obj": map[string]any{"k": "v"}},
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	nick := "bobby"
+	tests := []struct {
+		name   string
+		patch  string
+		modify func(u *jpUser)
+	}{
+		{
+			name:   "replace field",
+			patch:  `[{"op":"replace","path":"/name","value":"Alice"},{"op":"add","path":"/age","value":7}]`,
+			modify: func(u *jpUser) { u.Name = "Alice"; u.Age = 7 },
+		},
+		{
+			name:   "pointer field",
+			patch:  `[{"op":"add","path":"/nick","value":"bobby"}]`,
+			modify: func(u *jpUser) { u.Nick = &nick },
+		},
+		{
+			name:   "nested field",
+			patch:  `[{"op":"replace","path":"/address/city","value":"Nice"}]`,
+			modify: func(u *jpUser) { u.Address.City = "Nice" },
+		},
+		{
+			name:   "replace nested struct",
+			patch:  `[{"op":"replace","path":"/address","value":{"zip":"06000"}}]`,
+			modify: func(u *jpUser) { u.Address = jpAddress{Zip: "06000"} },
+		},
+		{
+			name:   "remove field",
+			patch:  `[{"op":"remove","path":"/tags"}]`,
+			modify: func(u *jpUser) { u.Tags = nil },
+		},
+		{
+			name:   "slice insert, append and remove",
+			patch:  `[{"op":"add","path":"/tags/0","value":"z"},{"op":"add","path":"/tags/-","value":"y"},{"op":"remove","path":"/tags/1"}]`,
+			modify: func(u *jpUser) { u.Tags = []string{"z", "b", "y"} },
+		},
+		{
+			name:   "slice of structs",
+			patch:  `[{"op":"replace","path":"/homes/0/zip","value":"69000"},{"op":"add","path":"/homes/-","value":{"city":"Nice"}}]`,
+			modify: func(u *jpUser) { u.Homes = []jpAddress{{City: "Lyon", Zip: "69000"}, {City: "Nice"}} },
+		},
+		{
+			name:   "map keys",
+			patch:  `[{"op":"add","path":"/labels/team","value":"x"},{"op":"remove","path":"/labels/env"}]`,
+			modify: func(u *jpUser) { u.Labels = map[string]string{"team": "x"} },
+		},
+		{
+			name:  "dynamic values",
+			patch: `[{"op":"add","path":"/extra/list/1","value":1.5},{"op":"replace","path":"/extra/obj/k","value":"w"}]`,
+			modify: func(u *jpUser) {
+				u.Extra = map[string]any{"list": []any{1.0, 1.5, 2.0}, "obj": map[string]any{"k": "w"}}
+			},
+		},
+		{
+			name:   "move and copy",
+			patch:  `[{"op":"copy","from":"/address/city","path":"/name"},{"op":"move","from":"/address/zip","path":"/labels/zip"}]`,
+			modify: func(u *jpUser) { u.Name = "Paris"; u.Address.Zip = ""; u.Labels["zip"] = "75001" },
+		},
+		{
+			name:   "test",
+			patch:  `[{"op":"test","path":"/age","value":42},{"op":"test","path":"/address","value":{"city":"Paris","zip":"75001"}},{"op":"replace","path":"/age","value":43}]`,
+			modify: func(u *jpUser) { u.Age = 43 },
+		},
+		{
+			name:   "escaped token",
+			patch:  `[{"op":"add","path":"/a~1b~0c","value":"x"}]`,
+			modify: func(u *jpUser) { u.Weird = "x" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []Op
+			require.NoError(t, json.Unmarshal([]byte(tt.patch), &ops))
+			got, want := newJPUser(), newJPUser()
+			tt.modify(&want)
+			require.NoError(t, ApplyJSONPatch(&got, ops))
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestApplyJSONPatch_fieldOptions(t *testing.T) {
+	type S struct {
+		At    time.Time `json:"at,unix"`
+		Email string    `json:"email" norm:"trim,lower"`
+		Card  string    `json:"card" secure:"encrypt"`
+	}
+	m := New(Encryption(seal, unseal))
+	var s S
+	require.NoError(t, m.ApplyJSONPatch(&s, []Op{
+		{Op: "replace", Path: "/at", Value: 1700000000.0},
+		{Op: "replace", Path: "/email", Value: " Bob@Example.COM "},
+		{Op: "replace", Path: "/card", Value: sealed{"4111"}},
+		{Op: "test", Path: "/at", Value: 1700000000.0},
+		{Op: "test", Path: "/card", Value: sealed{"4111"}},
+	}))
+	assert.Equal(t, S{At: time.Unix(1700000000, 0), Email: "bob@example.com", Card: "4111"}, s)
+
+	err := m.ApplyJSONPatch(&s, []Op{{Op: "replace", Path: "/card", Value: "plain"}})
+	assert.ErrorContains(t, err, "decrypt")
+	assert.Equal(t, "4111", s.Card)
+}
+
+func TestApplyJSONPatch_copyIsDeep(t *testing.T) {
+	u := newJPUser()
+	require.NoError(t, ApplyJSONPatch(&u, []Op{
+		{Op: "copy", From: "/extra/obj", Path: "/extra/obj2"},
+		{Op: "copy", From: "/extra/list", Path: "/extra/list2"},
+		{Op: "replace", Path: "/extra/obj2/k", Value: "w"},
+		{Op: "replace", Path: "/extra/list2/0", Value: 3.0},
+	}))
+	assert.Equal(t, map[string]any{"k": "v"}, u.Extra["obj"])
+	assert.Equal(t, map[string]any{"k": "w"}, u.Extra["obj2"])
+	assert.Equal(t, []any{1.0, 2.0}, u.Extra["list"])
+	assert.Equal(t, []any{3.0, 2.0}, u.Extra["list2"])
+}
+
+func TestApplyJSONPatch_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     []Op
+		wantErr error
+	}{
+		{"test failed", []Op{{Op: "test", Path: "/age", Value: 41}}, ErrTestFailed},
+		{"test type", []Op{{Op: "test", Path: "/age", Value: "x"}}, ErrTestFailed},
+		{"missing field", []Op{{Op: "replace", Path: "/missing", Value: 1}}, ErrFieldNotFound},
+		{"missing map key", []Op{{Op: "replace", Path: "/labels/x", Value: "y"}}, ErrFieldNotFound},
+		{"remove missing key", []Op{{Op: "remove", Path: "/labels/x"}}, ErrFieldNotFound},
+		{"nil pointer", []Op{{Op: "add", Path: "/nick/x", Value: 1}}, ErrFieldNotFound},
+		{"leaf has no members", []Op{{Op: "add", Path: "/name/x", Value: 1}}, ErrFieldNotFound},
+		{"out of range", []Op{{Op: "replace", Path: "/tags/2", Value: "x"}}, nil},
+		{"bad index", []Op{{Op: "add", Path: "/tags/01", Value: "x"}}, nil},
+		{"bad value", []Op{{Op: "replace", Path: "/age", Value: "x"}}, nil},
+		{"bad pointer", []Op{{Op: "replace", Path: "age", Value: 1}}, nil},
+		{"root", []Op{{Op: "replace", Path: "", Value: 1}}, nil},
+		{"unknown op", []Op{{Op: "frobnicate", Path: "/age"}}, nil},
+		{"move into child", []Op{{Op: "move", From: "/address", Path: "/address/city"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newJPUser()
+			err := ApplyJSONPatch(&u, tt.ops)
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+	u := newJPUser()
+	assert.ErrorIs(t, ApplyJSONPatch(u, nil), ErrNotStruct)
+}
@@ -0,0 +1,97 @@
+package tagops
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// The methods of the streaming interfaces of encoding/json/v2, that are
+// detected by the signature, so that the package does not depend on json/v2.
+const (
+	// marshalJSONTo is the method of json.MarshalerTo:
+	// MarshalJSONTo(*jsontext.Encoder) error.
+	marshalJSONTo = "MarshalJSONTo"
+	// unmarshalJSONFrom is the method of json.UnmarshalerFrom:
+	// UnmarshalJSONFrom(*jsontext.Decoder) error.
+	unmarshalJSONFrom = "UnmarshalJSONFrom"
+)
+
+var errorType = reflect.TypeFor[error]()
+
+// jsonV2Types caches the json/v2 interfaces implemented by the types.
+var jsonV2Types sync.Map // map[reflect.Type]jsonV2
+
+// jsonV2 is the set of the json/v2 interfaces implemented by the type.
+type jsonV2 struct {
+	marshaler, unmarshaler bool
+}
+
+// jsonV2Of returns the json/v2 interfaces implemented by t or *t.
+func jsonV2Of(t reflect.Type) jsonV2 {
+	if v, ok := jsonV2Types.Load(t); ok {
+		return v.(jsonV2)
+	}
+	v := jsonV2{
+		marshaler:   hasJSONTextMethod(t, marshalJSONTo, "Encoder"),
+		unmarshaler: hasJSONTextMethod(t, unmarshalJSONFrom, "Decoder"),
+	}
+	jsonV2Types.Store(t, v)
+	return v
+}
+
+// isJSONv2 returns true if t or *t implements json.MarshalerTo or
+// json.UnmarshalerFrom of encoding/json/v2.  Such types are leaf values:
+// ToMap keeps the value as is, so that the json/v2 encoder calls the method,
+// and FromMap decodes it, see JSONv2Unmarshal.
+func isJSONv2(t reflect.Type) bool {
+	v := jsonV2Of(t)
+	return v.marshaler || v.unmarshaler
+}
+
+// hasJSONTextMethod returns true if t or *t has the method name with the
+// signature func(*jsontext.<arg>) error.  Any package named jsontext
+// matches, i.e. encoding/json/jsontext or
+// github.com/go-json-experiment/json/jsontext.
+func hasJSONTextMethod(t reflect.Type, name, arg string) bool {
+	for _, t := range []reflect.Type{t, reflect.PointerTo(t)} {
+		m, ok := t.MethodByName(name)
+		if !ok {
+			continue
+		}
+		ft := m.Type // the receiver is the first argument.
+		if ft.NumIn() != 2 || ft.NumOut() != 1 || ft.Out(0) != errorType {
+			continue
+		}
+		in := ft.In(1)
+		if in.Kind() == reflect.Ptr && in.Elem().Name() == arg &&
+			(in.Elem().PkgPath() == "jsontext" || strings.HasSuffix(in.Elem().PkgPath(), "/jsontext")) {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONv2Unmarshal returns an Option that sets the function fn, that FromMap
+// uses to populate the fields of the types that implement json.UnmarshalerFrom
+// of encoding/json/v2, i.e. json.Unmarshal of json/v2.  The map value is
+// encoded to JSON with encoding/json, and passed to fn with the pointer to the
+// field.  Without it, such fields are populated only if the type also
+// implements json.Unmarshaler of encoding/json, or the value can be assigned
+// directly.
+func JSONv2Unmarshal(fn func(data []byte, v any) error) Option {
+	return func(m *Mapper) {
+		m.jsonV2Unmarshal = fn
+	}
+}
+
+// unmarshalJSONv2 encodes sv to JSON and decodes it into the addressable
+// value fv with the json/v2 unmarshal function fn.
+func unmarshalJSONv2(fv reflect.Value, sv any, fn func([]byte, any) error) error {
+	data, err := json.Marshal(sv)
+	if err != nil {
+		return err
+	}
+	return fn(data, fv.Addr().Interface())
+}
@@ -0,0 +1,77 @@
+package tagops
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/rusq/tagops/internal/jsontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// v2Money implements the json/v2 streaming interfaces.
+type v2Money struct {
+	Units int64  `json:"units"`
+	Cur   string `json:"cur"`
+}
+
+func (m v2Money) MarshalJSONTo(enc *jsontext.Encoder) error {
+	enc.Out = append(enc.Out, `"v2Money"`...)
+	return nil
+}
+
+func (m *v2Money) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	return nil
+}
+
+type notJSONv2 struct{}
+
+func (notJSONv2) MarshalJSONTo(enc *json.Encoder) error { return nil }
+
+func Test_isJSONv2(t *testing.T) {
+	assert.True(t, isJSONv2(reflect.TypeFor[v2Money]()))
+	assert.Equal(t, jsonV2{marshaler: true, unmarshaler: true}, jsonV2Of(reflect.TypeFor[v2Money]()))
+	assert.False(t, isJSONv2(reflect.TypeFor[notJSONv2]()), "wrong argument type")
+	assert.False(t, isJSONv2(reflect.TypeFor[struct{ A int }]()))
+}
+
+func TestJSONv2(t *testing.T) {
+	type order struct {
+		ID    int      `json:"id"`
+		Price v2Money  `json:"price"`
+		Tax   *v2Money `json:"tax"`
+	}
+	o := order{ID: 1, Price: v2Money{100, "EUR"}}
+	t.Run("ToMap keeps the value", func(t *testing.T) {
+		assert.Equal(t, map[string]any{"id": 1, "price": v2Money{100, "EUR"}, "tax": (*v2Money)(nil)}, New().ToMap(o))
+	})
+	t.Run("FromMap assigns the value", func(t *testing.T) {
+		var got order
+		require.NoError(t, New().FromMap(map[string]any{"price": v2Money{5, "USD"}}, &got))
+		assert.Equal(t, v2Money{5, "USD"}, got.Price)
+	})
+	t.Run("FromMap without the unmarshal function", func(t *testing.T) {
+		var got order
+		err := New().FromMap(map[string]any{"price": map[string]any{"units": 5}}, &got)
+		assert.Error(t, err)
+	})
+	t.Run("FromMap with the unmarshal function", func(t *testing.T) {
+		var calls int
+		m := New(JSONv2Unmarshal(func(data []byte, v any) error {
+			calls++
+			assert.IsType(t, &v2Money{}, v)
+			// stands in for json/v2, that would call UnmarshalJSONFrom.
+			type plain v2Money
+			return json.Unmarshal(data, (*plain)(v.(*v2Money)))
+		}))
+		var got order
+		require.NoError(t, m.FromMap(map[string]any{
+			"price": map[string]any{"units": 5, "cur": "USD"},
+			"tax":   map[string]any{"units": 1, "cur": "USD"},
+		}, &got))
+		assert.Equal(t, v2Money{5, "USD"}, got.Price)
+		assert.Equal(t, &v2Money{1, "USD"}, got.Tax)
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,149 @@
+package tagops
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidLabel is returned when the key or the value does not satisfy the
+// Kubernetes label syntax.
+var ErrInvalidLabel = errors.New("invalid label")
+
+// Limits of the Kubernetes label syntax.
+const (
+	labelNameMax   = 63
+	labelPrefixMax = 253
+)
+
+// k8sMode is the configuration of ToStringMap for Kubernetes labels and
+// annotations.
+type k8sMode struct {
+	// prefix is prepended to the keys with "/", if not empty.
+	prefix string
+	// values enables the validation of the values, that is only required for
+	// labels.
+	values bool
+}
+
+// K8sLabels returns an Option that makes ToStringMap produce Kubernetes
+// labels.  The keys, and the values, are checked against the label syntax:
+// at most 63 characters, alphanumeric at both ends, with "-", "_" and "."
+// inside.  The keys are prefixed with prefix and "/", if prefix is not empty,
+// and the prefix must be a DNS subdomain, i.e. "example.com".
+//
+// Invalid keys and values are normalized: the invalid characters are
+// replaced with "-", they are truncated to 63 characters, and the
+// non-alphanumeric characters are trimmed from both ends.  ToStringMap
+// returns the normalized map, and every change as the FieldError with
+// ErrInvalidLabel, joined with errors.Join, so the caller may either accept
+// the normalized labels or reject the input.  Keys that normalize to an empty
+// string, or to the key that already exists, are dropped.
+func K8sLabels(prefix string) Option {
+	return func(m *Mapper) {
+		m.k8s = &k8sMode{prefix: prefix, values: true}
+	}
+}
+
+// K8sAnnotations returns an Option that makes ToStringMap produce Kubernetes
+// annotations.  It is like K8sLabels, but the values are not restricted.
+func K8sAnnotations(prefix string) Option {
+	return func(m *Mapper) {
+		m.k8s = &k8sMode{prefix: prefix}
+	}
+}
+
+// apply returns the map kv with the keys, and the values if enabled,
+// normalized to the label syntax, and the violations found.
+func (k *k8sMode) apply(kv map[string]string) (map[string]string, error) {
+	if err := validPrefix(k.prefix); err != nil {
+		return nil, fmt.Errorf("%w: prefix %q: %w", ErrInvalidLabel, k.prefix, err)
+	}
+	var errs []error
+	out := make(map[string]string, len(kv))
+	// The valid keys go first, so that they win over the normalized ones.
+	keys := slices.Sorted(maps.Keys(kv))
+	slices.SortStableFunc(keys, func(a, b string) int {
+		_, errA := normalizeLabel(a)
+		_, errB := normalizeLabel(b)
+		return boolInt(errA != nil) - boolInt(errB != nil)
+	})
+	for _, key := range keys {
+		val := kv[key]
+		name, err := normalizeLabel(key)
+		if err != nil {
+			errs = append(errs, &FieldError{Path: key, Err: fmt.Errorf("%w: key: %w", ErrInvalidLabel, err)})
+		}
+		if name == "" {
+			continue
+		}
+		if k.prefix != "" {
+			name = k.prefix + "/" + name
+		}
+		if _, ok := out[name]; ok {
+			errs = append(errs, &FieldError{Path: key, Err: fmt.Errorf("%w: %q: %w", ErrKeyConflict, name, ErrInvalidLabel)})
+			continue
+		}
+		if k.values && val != "" {
+			if val, err = normalizeLabel(val); err != nil {
+				errs = append(errs, &FieldError{Path: key, Err: fmt.Errorf("%w: value: %w", ErrInvalidLabel, err)})
+			}
+		}
+		out[name] = val
+	}
+	return out, errors.Join(errs...)
+}
+
+// normalizeLabel returns s normalized to the label name syntax, and the
+// error describing the violation, if s was changed.
+func normalizeLabel(s string) (string, error) {
+	var reasons []string
+	norm := strings.Map(func(r rune) rune {
+		if isAlnum(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, s)
+	if norm != s {
+		reasons = append(reasons, "invalid characters")
+	}
+	if len(norm) > labelNameMax {
+		norm = norm[:labelNameMax]
+		reasons = append(reasons, fmt.Sprintf("longer than %d characters", labelNameMax))
+	}
+	if trimmed := strings.TrimFunc(norm, func(r rune) bool { return !isAlnum(r) }); trimmed != norm {
+		norm = trimmed
+		reasons = append(reasons, "must start and end with an alphanumeric character")
+	}
+	if len(reasons) == 0 {
+		return s, nil
+	}
+	return norm, fmt.Errorf("%q: %s", s, strings.Join(reasons, ", "))
+}
+
+// validPrefix checks that the prefix is empty or a DNS subdomain.
+func validPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if len(prefix) > labelPrefixMax {
+		return fmt.Errorf("longer than %d characters", labelPrefixMax)
+	}
+	for _, part := range strings.Split(prefix, ".") {
+		if part == "" || part[0] == '-' || part[len(part)-1] == '-' {
+			return errors.New("not a DNS subdomain")
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return errors.New("not a DNS subdomain")
+			}
+		}
+	}
+	return nil
+}
+
+func isAlnum(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
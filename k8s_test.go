@@ -0,0 +1,78 @@
+package tagops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type k8sTeam struct {
+	Name string `json:"name"`
+}
+
+type k8sConfig struct {
+	App     string  `json:"app"`
+	Version string  `json:"version"`
+	Team    k8sTeam `json:"team"`
+}
+
+func TestK8sLabels(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		got, err := New(K8sLabels("example.com")).ToStringMap(k8sConfig{App: "api", Version: "v1.2.3", Team: k8sTeam{Name: "core_infra"}})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"example.com/app":       "api",
+			"example.com/version":   "v1.2.3",
+			"example.com/team.name": "core_infra",
+		}, got)
+	})
+	t.Run("normalized", func(t *testing.T) {
+		type cfg struct {
+			App   string `json:"app name"`
+			Build string `json:"_build"`
+			Desc  string `json:"desc"`
+			Dup   string `json:"app-name"`
+			Blank string `json:"@"`
+		}
+		got, err := New(K8sLabels("")).ToStringMap(cfg{
+			App:   "my api",
+			Build: "-123-",
+			Desc:  strings.Repeat("x", 70),
+			Dup:   "other",
+			Blank: "v",
+		})
+		assert.Equal(t, map[string]string{
+			"app-name": "other",
+			"build":    "123",
+			"desc":     strings.Repeat("x", 63),
+		}, got)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidLabel)
+		assert.ErrorIs(t, err, ErrKeyConflict)
+		msg := err.Error()
+		for _, want := range []string{
+			`app name: invalid label: key: "app name": invalid characters`,
+			`_build: invalid label: key: "_build": must start and end`,
+			`_build: invalid label: value: "-123-"`,
+			`desc: invalid label: value:`,
+			"longer than 63 characters",
+			`@: invalid label: key: "@"`,
+		} {
+			assert.Contains(t, msg, want)
+		}
+	})
+	t.Run("annotations", func(t *testing.T) {
+		got, err := New(K8sAnnotations("example.com")).ToStringMap(k8sConfig{App: "any value, really!"})
+		require.NoError(t, err)
+		assert.Equal(t, "any value, really!", got["example.com/app"])
+	})
+	t.Run("invalid prefix", func(t *testing.T) {
+		for _, prefix := range []string{"Example.com", "-a.com", "a..com", strings.Repeat("a", 254)} {
+			got, err := New(K8sLabels(prefix)).ToStringMap(k8sConfig{})
+			assert.ErrorIs(t, err, ErrInvalidLabel, prefix)
+			assert.Nil(t, got)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package tagops
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ToStringMap converts the struct a to the flat map of string values, i.e.
+// for labels, annotations or metadata maps of SDKs.  See Mapper.ToStringMap.
+func ToStringMap(a any) (map[string]string, error) {
+	return New().ToStringMap(a)
+}
+
+// StringFunc returns an Option that sets the function that formats the
+// values for the string outputs: ToStringMap, ToKV and the INI and properties
+// encoders.  It is called for each non-nil leaf value, after the formatting
+// options, such as FormatTime, are applied.  If fn returns false, the value
+// is formatted as described in ToKV.
+func StringFunc(fn func(v any) (s string, ok bool)) Option {
+	return func(m *Mapper) {
+		m.stringFunc = fn
+	}
+}
+
+// ToKV converts the struct a to the flat map of key paths to string values,
+// suitable for key/value stores, such as Vault, Consul or etcd.  See
+// Mapper.ToKV for details.  Conversion errors are ignored.
+func ToKV(a any, prefix, sep string) map[string]string {
+	kv, _ := New().ToKV(a, prefix, sep)
+	return kv
+}
+
+// FromKV populates the struct pointed to by a from the map kv, produced by
+// ToKV with the same prefix and separator.
+func FromKV(kv map[string]string, prefix, sep string, a any) error {
+	return New().FromKV(kv, prefix, sep, a)
+}
+
+// ToKV converts the struct a to the flat map of key paths to string values.
+// The key path is the prefix, if not empty, and the keys of nested structs
+// joined with sep, i.e. "app/db/password" for the prefix "app" and sep "/".
+// Keys must not contain sep.
+//
+// Strings are used as is, []byte values are encoded with base64, time.Time
+// values are formatted as RFC 3339, time.Duration values as "1h30m", and
+// slices, arrays, maps and leaf structs are encoded as JSON.  Nil values,
+// including nil slices and maps, are omitted.
+func (m Mapper) ToKV(a any, prefix, sep string) (map[string]string, error) {
+	mp, err := m.ToMapE(a)
+	if mp == nil {
+		return nil, err
+	}
+	kv := make(map[string]string)
+	if kvErr := m.toKV(kv, mp, prefix, sep); kvErr != nil {
+		return kv, kvErr
+	}
+	return kv, err
+}
+
+// ToStringMap converts the struct a to the flat map of string values.  It is
+// ToKV without the prefix, and with the keys of nested structs joined with
+// ".", so flat structs map to the same keys as in ToMap.  Set StringFunc to
+// change how the values are formatted, and K8sLabels or K8sAnnotations to
+// produce the Kubernetes metadata.
+func (m Mapper) ToStringMap(a any) (map[string]string, error) {
+	kv, err := m.ToKV(a, "", ".")
+	if m.k8s == nil || kv == nil {
+		return kv, err
+	}
+	out, k8sErr := m.k8s.apply(kv)
+	return out, errors.Join(err, k8sErr)
+}
+
+// kvString returns the string representation of v, formatted with the
+// string function, if set, see StringFunc.
+func (m Mapper) kvString(v any) (string, bool, error) {
+	if m.stringFunc != nil && v != nil && !isNilValue(reflect.ValueOf(v)) {
+		if s, ok := m.stringFunc(v); ok {
+			return s, true, nil
+		}
+	}
+	return kvString(v)
+}
+
+// toKV adds the values of the map mp to kv, with keys prefixed with prefix.
+func (m Mapper) toKV(kv map[string]string, mp map[string]any, prefix, sep string) error {
+	for key, val := range mp {
+		if prefix != "" {
+			key = prefix + sep + key
+		}
+		if nested, ok := val.(map[string]any); ok {
+			if err := m.toKV(kv, nested, key, sep); err != nil {
+				return err
+			}
+			continue
+		}
+		s, ok, err := m.kvString(val)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if ok {
+			kv[key] = s
+		}
+	}
+	return nil
+}
+
+// kvString returns the string representation of v.  It returns false if the
+// value is nil.
+func kvString(v any) (string, bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, true, nil
+	case []byte:
+		if v == nil {
+			return "", false, nil
+		}
+		return base64.StdEncoding.EncodeToString(v), true, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), true, nil
+	case time.Duration:
+		return v.String(), true, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return "", false, nil
+		}
+	}
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return kvString(rv.Elem().Interface())
+	case reflect.String:
+		return rv.String(), true, nil
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false, err
+		}
+		return string(data), true, nil
+	}
+	return fmt.Sprint(v), true, nil
+}
+
+// FromKV populates the struct pointed to by a from the map kv, produced by
+// ToKV with the same prefix, separator and Mapper configuration.  Keys
+// without the prefix are ignored.  The string values are parsed into the
+// field types, see ParseStrings.
+func (m Mapper) FromKV(kv map[string]string, prefix, sep string, a any) error {
+	src := make(map[string]any)
+	for key, val := range kv {
+		if prefix != "" {
+			var ok bool
+			if key, ok = strings.CutPrefix(key, prefix+sep); !ok {
+				continue
+			}
+		}
+		// The Mapper prefix may contain sep, so it is kept on the top-level key.
+		key, ok := strings.CutPrefix(key, m.prefix)
+		if !ok {
+			continue
+		}
+		path := strings.Split(key, sep)
+		path[0] = m.prefix + path[0]
+		setPath(src, path, val)
+	}
+	return m.With(ParseStrings()).FromMap(src, a)
+}
+
+// setPath sets the value at the path of keys in the nested map mp, creating
+// the intermediate maps.
+func setPath(mp map[string]any, path []string, val any) {
+	for _, k := range path[:len(path)-1] {
+		next, ok := mp[k].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			mp[k] = next
+		}
+		mp = next
+	}
+	mp[path[len(path)-1]] = val
+}
@@ -0,0 +1,162 @@
+package tagops
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type kvDB struct {
+	Host     string        `json:"host"`
+	Port     uint16        `json:"port"`
+	Password []byte        `json:"password"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+type kvApp struct {
+	Name    string            `json:"name"`
+	Debug   bool              `json:"debug"`
+	Ratio   float64           `json:"ratio"`
+	Started time.Time         `json:"started"`
+	Level   level             `json:"level"`
+	Hosts   []string          `json:"hosts"`
+	Labels  map[string]string `json:"labels"`
+	Limit   *int              `json:"limit"`
+	DB      kvDB              `json:"db"`
+}
+
+func TestToKV(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	limit := 10
+	app := kvApp{
+		Name:    "api",
+		Debug:   true,
+		Ratio:   0.5,
+		Started: started,
+		Level:   info,
+		Hosts:   []string{"a", "b"},
+		Labels:  map[string]string{"env": "prod"},
+		Limit:   &limit,
+		DB:      kvDB{"localhost", 5432, []byte("secret"), 3 * time.Second},
+	}
+	want := map[string]string{
+		"app/name":        "api",
+		"app/debug":       "true",
+		"app/ratio":       "0.5",
+		"app/started":     "2024-01-02T03:04:05Z",
+		"app/level":       "2",
+		"app/hosts":       `["a","b"]`,
+		"app/labels":      `{"env":"prod"}`,
+		"app/limit":       "10",
+		"app/db/host":     "localhost",
+		"app/db/port":     "5432",
+		"app/db/password": "c2VjcmV0",
+		"app/db/timeout":  "3s",
+	}
+	kv := ToKV(app, "app", "/")
+	assert.Equal(t, want, kv)
+
+	t.Run("round trip", func(t *testing.T) {
+		kv["other/name"] = "ignored"
+		var got kvApp
+		require.NoError(t, FromKV(kv, "app", "/", &got))
+		assert.Equal(t, app, got)
+	})
+	t.Run("no prefix", func(t *testing.T) {
+		kv := ToKV(kvDB{Host: "h"}, "", ".")
+		assert.Equal(t, "h", kv["host"])
+		var got kvDB
+		require.NoError(t, FromKV(kv, "", ".", &got))
+		assert.Equal(t, kvDB{Host: "h"}, got)
+	})
+	t.Run("formats", func(t *testing.T) {
+		m := New(FormatTime(TimeUnix), FormatDuration(DurationSeconds), EnumStrings())
+		kv, err := m.ToKV(app, "", "_")
+		require.NoError(t, err)
+		assert.Equal(t, "1704164645", kv["started"])
+		assert.Equal(t, "3", kv["db_timeout"])
+		assert.Equal(t, "info", kv["level"])
+		var got kvApp
+		require.NoError(t, m.FromKV(kv, "", "_", &got))
+		assert.True(t, started.Equal(got.Started))
+		assert.Equal(t, app.DB, got.DB)
+		assert.Equal(t, info, got.Level)
+	})
+	t.Run("parse error", func(t *testing.T) {
+		var got kvApp
+		err := FromKV(map[string]string{"db/port": "99999"}, "", "/", &got)
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "DB.Port", fe.Path)
+	})
+}
+
+func TestToStringMap(t *testing.T) {
+	type meta struct {
+		App     string   `json:"app"`
+		Version int      `json:"version"`
+		Canary  bool     `json:"canary"`
+		Zones   []string `json:"zones"`
+		Owner   *string  `json:"owner"`
+		DB      kvDB     `json:"db"`
+	}
+	v := meta{App: "api", Version: 3, Canary: true, Zones: []string{"a", "b"}, DB: kvDB{Host: "h", Timeout: time.Second}}
+	t.Run("default", func(t *testing.T) {
+		got, err := ToStringMap(v)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"app":        "api",
+			"version":    "3",
+			"canary":     "true",
+			"zones":      `["a","b"]`,
+			"db.host":    "h",
+			"db.port":    "0",
+			"db.timeout": "1s",
+		}, got)
+	})
+	t.Run("string func", func(t *testing.T) {
+		m := New(Flatten(), StringFunc(func(v any) (string, bool) {
+			switch v := v.(type) {
+			case []string:
+				return strings.Join(v, ","), true
+			case bool:
+				return map[bool]string{true: "yes", false: "no"}[v], true
+			}
+			return "", false
+		}))
+		got, err := m.ToStringMap(v)
+		require.NoError(t, err)
+		assert.Equal(t, "a,b", got["zones"])
+		assert.Equal(t, "yes", got["canary"])
+		assert.Equal(t, "3", got["version"])
+		assert.Equal(t, "h", got["host"])
+		assert.NotContains(t, got, "owner")
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := ToStringMap(42)
+		assert.ErrorIs(t, err, ErrNotStruct)
+	})
+}
+
+func TestParseStrings(t *testing.T) {
+	type S struct {
+		N   int8           `json:"n"`
+		C   complex128     `json:"c"`
+		Arr [2]int         `json:"arr"`
+		P   *bool          `json:"p"`
+		M   map[string]int `json:"m"`
+		S   string         `json:"s"`
+	}
+	var got S
+	err := New(ParseStrings()).FromMap(map[string]any{
+		"n": "-5", "c": "1+2i", "arr": "[1,2]", "p": "true", "m": `{"a":1}`, "s": "x",
+	}, &got)
+	require.NoError(t, err)
+	yes := true
+	assert.Equal(t, S{N: -5, C: 1 + 2i, Arr: [2]int{1, 2}, P: &yes, M: map[string]int{"a": 1}, S: "x"}, got)
+
+	assert.Error(t, New().FromMap(map[string]any{"n": "5"}, &got), "strings are not parsed by default")
+}
@@ -1,9 +1,11 @@
 package tagops
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"maps"
+	"math"
 	"reflect"
 	"slices"
 	"sort"
@@ -16,14 +18,89 @@ import (
 // Mapper is a struct to map struct fields to map key/values.  The struct
 // fields are mapped to map keys using the Tag. No tag value leads to undefined
 // behavior.  The Mapper can be configured with options.
+//
+// A Mapper has value semantics and is not modified by any of its methods, so
+// it is safe for concurrent use, i.e. as a package-level variable, provided
+// that the exported fields are not changed after it is shared.  Use With to
+// derive a Mapper with a different configuration.  Package-level registries
+// (converters, enums, options) are safe for concurrent use.  Hooks may be
+// called concurrently, and must be safe for concurrent use themselves.
 type Mapper struct {
 	// Tag is the tag name.
 	Tag string
 	// Omitempty omits empty fields.
 	Omitempty bool
-	// Flatten flattens named nested structs (anonymous structs are always
-	// flattened).
+	// Flatten flattens named nested structs (anonymous structs without a
+	// name in the tag are flattened unless NoFlattenAnonymous is set).
 	Flatten bool
+
+	// beforeField is called before each field is mapped.
+	beforeField func(context.Context, FieldInfo, reflect.Value) bool
+	// afterStruct is called after each struct is mapped.
+	afterStruct func(context.Context, map[string]any)
+	// collectErrors enables the error accumulation mode.
+	collectErrors bool
+	// unsupported is the policy for fields of unsupported kinds.
+	unsupported UnsupportedPolicy
+	// keyFunc derives the key from the name for fields without a tag name
+	// and for keys of map inputs.
+	keyFunc func(string) string
+	// timeFormat is the format of time.Time values.
+	timeFormat TimeFormat
+	// timeLoc is the location of time.Time values.
+	timeLoc *time.Location
+	// durFormat is the format of time.Duration values.
+	durFormat DurationFormat
+	// floatPrec is the number of decimal places of float values, if set.
+	floatPrec *int
+	// enumStrings enables emitting enums as names.
+	enumStrings bool
+	// bytesFmt is the format of byte slices and arrays.
+	bytesFmt BytesFormat
+	// strictConflicts enables reporting key conflicts.
+	strictConflicts bool
+	// noFlattenAnon disables flattening of anonymous structs.
+	noFlattenAnon bool
+	// encoderSafe restricts values to the types supported by binary
+	// encoders.
+	encoderSafe bool
+	// parseStrings enables parsing of string values in FromMap.
+	parseStrings bool
+	// encrypt and decrypt are applied to the secure fields.
+	encrypt, decrypt EncryptFunc
+	// checksum is the checksum key settings, if set.
+	checksum *checksum
+	// fieldOrder makes MarshalJSON write keys in the field order.
+	fieldOrder bool
+	// untagged is the policy for the fields without a name in the tag.
+	untagged UntaggedPolicy
+	// defaults are the values for the keys absent in the FromMap source.
+	defaults map[string]any
+	// mergeMaps makes FromMap merge the nested maps into the map fields,
+	// instead of replacing them, see MergePatch.
+	mergeMaps bool
+	// stringFunc formats the values for the string outputs, i.e. ToKV.
+	stringFunc func(any) (string, bool)
+	// k8s makes ToStringMap produce Kubernetes labels or annotations.
+	k8s *k8sMode
+	// prefix is prepended to the top-level keys.
+	prefix string
+	// version is the version-qualified tag, that overrides the Tag on the
+	// fields that have it, see Version.
+	version string
+	// onDeprecated is called when FromMap populates a deprecated field.
+	onDeprecated func(FieldInfo, string)
+	// computed are the derived keys of the root map, see WithComputed.
+	computed []computedField
+	// include and exclude select the keys of the root map.
+	include, exclude mask
+	// traceFn receives the trace events, see WithTrace.
+	traceFn func(TraceEvent)
+	// metrics receives the counters and timings, see WithMetrics.
+	metrics *Metrics
+	// jsonV2Unmarshal decodes the json/v2 unmarshalers, see
+	// JSONv2Unmarshal.
+	jsonV2Unmarshal func([]byte, any) error
 }
 
 // New returns a new Mapper with options opts.
@@ -35,6 +112,16 @@ func New(opts ...Option) Mapper {
 	return m
 }
 
+// With returns a copy of the Mapper with options opts applied on top of its
+// configuration.  The receiver is not modified, so a shared Mapper can be
+// adjusted per call, i.e. m.With(Flatten()).ToMap(v).
+func (m Mapper) With(opts ...Option) Mapper {
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return m
+}
+
 // Option is a functional option for Mapper.
 type Option func(*Mapper)
 
@@ -59,83 +146,493 @@ func Omitempty() Option {
 	}
 }
 
+// NoFlattenAnonymous returns an Option that disables flattening of anonymous
+// (embedded) structs.  Embedded structs are then mapped to nested maps under
+// the key from the tag, or the type name, if there's no tag name.  It does not
+// affect named nested structs, see Flatten.
+func NoFlattenAnonymous() Option {
+	return func(o *Mapper) {
+		o.noFlattenAnon = true
+	}
+}
+
+// KeyFunc returns an Option that sets the function fn, that derives the map
+// key from the Go field name for fields that have no name in the tag.  It is
+// also applied to the keys of map inputs, see ToMap.  The keys returned by fn
+// are cached, so fn must return the same key for the same name.
+func KeyFunc(fn func(name string) string) Option {
+	fn = internKeys(fn)
+	return func(o *Mapper) {
+		o.keyFunc = fn
+	}
+}
+
+// UntaggedPolicy defines how the fields that have no name in the tag are
+// mapped.
+type UntaggedPolicy int
+
+const (
+	// UseKeyFunc maps the fields under the Go field name, transformed with
+	// the KeyFunc, if set.  This is the default.
+	UseKeyFunc UntaggedPolicy = iota
+	// UseFieldName maps the fields under the Go field name, the KeyFunc is
+	// not applied.
+	UseFieldName
+	// SkipUntagged skips the fields, so that only the fields with the name
+	// in the tag are mapped.  Untagged embedded structs are still flattened,
+	// unless NoFlattenAnonymous is set.
+	SkipUntagged
+)
+
+// Untagged returns an Option that sets the policy p for the fields that have
+// no name in the tag, i.e. no tag at all, or `json:",omitempty"`.  Strict
+// schemas may use SkipUntagged to refuse such fields, instead of exporting
+// them under the Go field names.  The KeyFunc is still applied to the keys of
+// map inputs.
+func Untagged(p UntaggedPolicy) Option {
+	return func(m *Mapper) {
+		m.untagged = p
+	}
+}
+
+// Prefix returns an Option that prepends the namespace p to every top-level
+// key of the output maps, i.e. "db." or "APP_", so that several structs can
+// be combined into one flat map.  It applies to ToMap and to everything built
+// on it, such as ToKV, so the keys passed to the other methods of the Mapper,
+// i.e. Pluck, must include the prefix.  FromMap strips the prefix, and
+// ignores the keys without it.  Nested keys are not prefixed.
+func Prefix(p string) Option {
+	return func(m *Mapper) {
+		m.prefix = p
+	}
+}
+
+// Version returns a copy of the Mapper that maps the fields with the
+// version-qualified tag version, i.e. `v2:"username"`, so that one struct can
+// serve several API or file format versions.  Fields without the version tag
+// are mapped with the Tag, and `v2:"-"` skips the field in that version.
+//
+//	type User struct {
+//		ID   int    `json:"id"`
+//		Name string `json:"user_name" v2:"username"`
+//	}
+//
+// m.Version("v2").ToMap(u) returns the keys "id" and "username".
+func (m Mapper) Version(version string) Mapper {
+	m.version = version
+	return m
+}
+
+// tagFor returns the tag that maps the field: the version tag, if set and
+// present on the field, or the Tag.
+func (m Mapper) tagFor(field reflect.StructField) string {
+	if m.version != "" {
+		if _, ok := field.Tag.Lookup(m.version); ok {
+			return m.version
+		}
+	}
+	return m.Tag
+}
+
+// ToMap converts the struct a to a map[tag]value.  See package-level ToMap
+// for details.  Conversion errors are ignored, use ToMapE to get them.
+//
+// The value a may also be a map with string keys, i.e. decoded JSON.  Such a
+// map is passed through with the KeyFunc applied to the keys.  Nested maps
+// and structs are flattened if Flatten is set, and if Omitempty is set, all
+// empty values are omitted, as maps have no tag options.
 func (m Mapper) ToMap(a any) map[string]any {
-	out := make(map[string]any)
+	mp, _ := m.ToMapE(a)
+	return mp
+}
+
+// ToMapE is like ToMap, but returns the conversion errors.  The returned map
+// contains all fields, fields that failed to convert have their original
+// values.  By default, the first error is returned, see CollectErrors.
+func (m Mapper) ToMapE(a any) (map[string]any, error) {
+	return m.ToMapCtx(context.Background(), a)
+}
 
+// ToMapCtx is like ToMapE, but passes the context ctx to converters and hooks.
+// The conversion stops if ctx is cancelled, and the context error is returned.
+func (m Mapper) ToMapCtx(ctx context.Context, a any) (_ map[string]any, err error) {
+	if m.metrics != nil {
+		defer func(start time.Time) { m.metrics.conversion("ToMap", start, err) }(time.Now())
+	}
+	if p, ok := a.(MapProvider); ok {
+		return m.addPrefix(p.TagOpsMap(m.Tag)), nil
+	}
 	v := reflect.ValueOf(a)
 	if v.Kind() == reflect.Ptr {
 		v = v.Elem()
 	}
+	st := newState(ctx)
+	var mp map[string]any
+	switch {
+	case v.Kind() == reflect.Struct:
+		mp = m.toMap(st, v, "")
+	case v.IsValid() && isStringMap(v.Type()):
+		mp = m.mapToMap(st, v, "")
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrNotStruct, a)
+	}
+	if st.aborted != nil {
+		return nil, st.aborted
+	}
+	m.addComputed(st, a, mp)
+	if m.include != nil {
+		mp = m.include.apply(mp)
+	}
+	if m.exclude != nil {
+		m.exclude.remove(mp)
+	}
+	mp = m.addPrefix(mp)
+	if m.checksum != nil {
+		m.checksum.add(st, mp, m.prefix+m.checksum.key)
+	}
+	return mp, st.err(m.collectErrors)
+}
+
+// addPrefix returns the map mp with the prefix prepended to the keys, if
+// set.
+func (m Mapper) addPrefix(mp map[string]any) map[string]any {
+	if m.prefix == "" || mp == nil {
+		return mp
+	}
+	out := make(map[string]any, len(mp))
+	for k, v := range mp {
+		out[m.prefix+k] = v
+	}
+	return out
+}
+
+// trimPrefix returns the map mp with the keys that have the prefix, with the
+// prefix removed.  Other keys are dropped.
+func (m Mapper) trimPrefix(mp map[string]any) map[string]any {
+	if m.prefix == "" {
+		return mp
+	}
+	out := make(map[string]any, len(mp))
+	for k, v := range mp {
+		if k, ok := strings.CutPrefix(k, m.prefix); ok {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// toMap converts the struct value v to a map.  path is the path of v from the
+// root struct.  Keys in conflict are omitted, see StrictConflicts.
+func (m Mapper) toMap(st *state, v reflect.Value, path string) map[string]any {
+	es := m.walk(st, v, path)
+	if m.strictConflicts {
+		for _, err := range es.conflicts() {
+			st.fail(path, err)
+		}
+	}
+	return es.materialize()
+}
+
+// walk converts the struct value v to entries.  path is the path of v from
+// the root struct.
+func (m Mapper) walk(st *state, v reflect.Value, path string) entries {
+	out := make(entries, v.NumField())
 
 	typ := v.Type()
-	for i := range v.NumField() {
-		field := typ.Field(i)
+	m = m.withProfile(typ)
+	m.trace(TraceEvent{Kind: TraceEnterStruct, Op: "ToMap", Path: path, Type: typ})
+	plan := m.plan(typ)
+	for i := range plan.fields {
+		if st.done() {
+			return out
+		}
+		fp := &plan.fields[i]
+		field := fp.field
+		if !field.IsExported() {
+			m.traceSkip("ToMap", field, joinPath(path, field.Name), "", "unexported")
+			continue
+		}
+		fv := v.Field(i)
+		tag := fp.tag
+		provider, isProvider := asMapProvider(fv)
+		nested := isProvider || isNested(field.Type)
+		flatten := nested && m.flattens(field, tag)
 
-		if field.Type.Kind() == reflect.Struct && v.Field(i).Type() != reflect.TypeOf(time.Time{}) {
-			nested := ToMap(v.Field(i).Interface(), m.Tag, m.Omitempty, m.Flatten)
-			if field.Anonymous || m.Flatten {
-				// flatten nested structs
-				for key, val := range nested {
-					out[key] = val
+		key, err := m.fieldKey(fp, fv, m.Omitempty)
+		if errors.Is(err, ErrInvalidFieldName) {
+			st.fail(joinPath(path, field.Name), err)
+			continue
+		}
+		if errors.Is(err, ErrSkip) && !flatten {
+			if m.tracing() {
+				m.traceSkip("ToMap", field, joinPath(path, field.Name), "", m.skipReason(field, tag))
+			}
+			continue
+		}
+		fi := FieldInfo{Field: field, Key: key, Path: joinPath(path, field.Name), Options: fp.opts}
+		if fp.has(fWriteOnly) {
+			m.traceSkip("ToMap", field, fi.Path, key, "write-only")
+			continue
+		}
+		if omit, err := m.omitIf(v, field); err != nil {
+			st.fail(fi.Path, err)
+		} else if omit {
+			if m.tracing() {
+				m.traceSkip("ToMap", field, fi.Path, key, fmt.Sprintf("omitif condition %q holds", field.Tag.Get(omitIfTag)))
+			}
+			continue
+		}
+		if m.beforeField != nil && m.beforeField(st.ctx, fi, fv) {
+			m.traceSkip("ToMap", field, fi.Path, key, "BeforeField hook")
+			continue
+		}
+		m.trace(TraceEvent{Kind: TraceFieldResolved, Op: "ToMap", Path: fi.Path, Type: field.Type, Key: key})
+
+		tagged := fp.tagged()
+		if flatten {
+			if isProvider {
+				for key, val := range provider.TagOpsMap(m.Tag) {
+					out.add(key, &entry{depth: 1, cands: []candidate{{val: val, path: fi.Path}}})
 				}
 			} else {
-				// nested maps are not flattened
-				key, err := tagName(field, v.Field(i), m.Tag, m.Omitempty)
-				if errors.Is(err, errSkip) {
-					continue
-				}
-				out[key] = nested
+				// flatten nested structs
+				out.merge(m.walk(st, fv, fi.Path))
 			}
-		} else {
-			key, err := tagName(field, v.Field(i), m.Tag, m.Omitempty)
-			if errors.Is(err, errSkip) {
-				continue
+			continue
+		}
+		var (
+			val  any
+			ok   = true
+			leaf bool
+			n    = len(st.errs)
+		)
+		switch {
+		case isOptional(field.Type):
+			val, ok = m.optionalOut(st, fv.Interface().(optional), fi.Options, fi.Path)
+		case isProvider:
+			val = provider.TagOpsMap(m.Tag)
+		case nested:
+			// nested maps are not flattened
+			val = m.toMap(st, fv, fi.Path)
+		default:
+			leaf = true
+			val, ok = m.fastValue(fp, fv)
+			if !ok {
+				val, ok = m.leaf(st, fv, fi.Options, fi.Path)
 			}
-			out[key] = v.Field(i).Interface()
 		}
+		if ok {
+			val, ok = m.encryptField(st, field, key, val, fi.Path)
+		}
+		if !ok {
+			m.traceSkip("ToMap", field, fi.Path, key, "omitted by the conversion")
+			continue
+		}
+		if leaf {
+			m.trace(TraceEvent{Kind: TraceValueConverted, Op: "ToMap", Path: fi.Path, Type: field.Type, Key: key, Value: val, Err: st.lastErr(n)})
+		}
+		out.add(key, newEntry(val, tagged, fi.Path))
+	}
+	if m.afterStruct != nil {
+		mp := out.materialize()
+		m.afterStruct(st.ctx, mp)
+		out.update(mp)
 	}
 	return out
 }
 
-// Tags returns a sorted list of names in tags, given a struct object.  The
-// empty fields are included and the map is flattened.
+// flattens returns true if the nested struct field should be flattened into
+// the parent map for the tag.  As in encoding/json, an anonymous field with a
+// name in the tag is treated as a named field, and the field with the name
+// "-" is ignored, not flattened.  Secure fields are never flattened, as they
+// are encrypted as a whole, see Encryption.
+func (m Mapper) flattens(field reflect.StructField, tag string) bool {
+	if m.secure(field) || isIgnored(field, tag) {
+		return false
+	}
+	if field.Anonymous && !isTagged(field, tag) {
+		return !m.noFlattenAnon
+	}
+	return m.Flatten
+}
+
+// leaf returns the map value for the leaf value fv at path, opts are the tag
+// options.  It returns false if the value should be omitted.
+func (m Mapper) leaf(st *state, fv reflect.Value, opts []string, path string) (any, bool) {
+	val, err := m.value(st.ctx, fv, opts)
+	if errors.Is(err, ErrSkip) {
+		return nil, false
+	}
+	if errors.Is(err, ErrUnsupportedKind) {
+		st.fail(path, err)
+		return nil, false
+	}
+	if err != nil {
+		// conversion errors leave the value unconverted.
+		st.fail(path, err)
+	} else if isNonFinite(val) {
+		st.fail(path, ErrNonFinite)
+	}
+	return val, true
+}
+
+// isNonFinite returns true if v is a NaN or infinite float.
+func isNonFinite(v any) bool {
+	switch f := v.(type) {
+	case float64:
+		return math.IsNaN(f) || math.IsInf(f, 0)
+	case float32:
+		return math.IsNaN(float64(f)) || math.IsInf(float64(f), 0)
+	case nil:
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	return rv.CanFloat() && (math.IsNaN(rv.Float()) || math.IsInf(rv.Float(), 0))
+}
+
+// isNested returns true if the type t is a struct that should be descended
+// into, i.e. it is not time.Time, a big number or an Optional, and has no
+// converter registered.
+func isNested(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType || isBig(t) || isOptional(t) || isJSONv2(t) {
+		return false
+	}
+	_, hasConv := outConverter(t)
+	return !hasConv
+}
+
+// Tags returns a sorted list of names in tags, given a struct object.  It
+// honors the Mapper configuration, so that the returned tags are the keys of
+// the map returned by ToMap, and correspond to the values returned by Values.
+// The returned slice is never nil, it is empty if a has no fields or is not
+// a struct.
+//
+// The position of the tag may be pinned with the col tag, i.e. `col:"1"` for
+// the first column, then the other tags fill the remaining positions in the
+// alphabetical order.
 func (m Mapper) Tags(a any) []string {
-	return Keys(ToMap(a, m.Tag, m.Omitempty, m.Flatten))
+	return columns(m.ToMap(a), m.columnPins(a))
 }
 
-// Values returns values for the struct object a, given a tag.  The empty
-// fields are included and the map is flattened.  The values are returned in
-// the alphabetical order of tags.
+// Values returns values for the struct object a.  It honors the Mapper
+// configuration, and the values are returned in the alphabetical order of
+// tags, or the pinned order, as returned by Tags for the same object.  If
+// Omitempty is set, the number of values may vary between objects.
 func (m Mapper) Values(a any) ([]any, error) {
-	mp := ToMap(a, m.Tag, false, true)
-	var ret = make([]any, 0, len(mp))
-	if err := MapValues(&ret, mp, m.Tags(a)); err != nil {
+	if p, ok := a.(ValuesProvider); ok {
+		return p.TagOpsValues(m.Tag), nil
+	}
+	mp, err := m.ToMapE(a)
+	if err != nil {
+		return nil, err
+	}
+	return ValuesOf(mp, columns(mp, m.columnPins(a)))
+}
+
+// ValuesFor returns values for the struct object a in the order of tags
+// specified by order.  Tags that are missing in the object have nil values.
+// It is useful when the order of columns is decided once, i.e. for CSV
+// headers, and all rows must follow it.
+func (m Mapper) ValuesFor(a any, order []string) ([]any, error) {
+	mp, err := m.ToMapE(a)
+	if err != nil {
+		return nil, err
+	}
+	var ret = make([]any, len(order))
+	if err := MapValues(&ret, mp, order); err != nil {
 		return nil, err
 	}
 	return ret, nil
 }
 
-// Keys returns a sorted list of keys for the map m.
+// Keys returns a sorted list of keys for the map m.  It returns nil if the
+// map is empty, see KeysOrEmpty.
 func Keys(m map[string]any) []string {
 	kk := slices.Collect(maps.Keys(m))
 	sort.Strings(kk)
 	return kk
 }
 
-// MapValues populates slice out with values from map m in the key order
-// specified by order.  The size of out slice will be adjusted to order size
-// to accomodate for all values.
-func MapValues(out *[]any, m map[string]any, order []string) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			e, ok := r.(error)
-			if ok {
-				err = e
-			} else {
-				err = fmt.Errorf("panic: %v", r)
+// KeysOrEmpty is like Keys, but returns an empty, non-nil slice if the map
+// is empty, so that it's encoded as [] and not null.
+func KeysOrEmpty(m map[string]any) []string {
+	kk := Keys(m)
+	if kk == nil {
+		return []string{}
+	}
+	return kk
+}
+
+// UnionTags returns a sorted list of keys that appear in any of the maps in
+// rows, i.e. to build the CSV header for records with optional fields.  See
+// Mapper.UnionTags for slices of structs.  The returned slice is never nil.
+func UnionTags(rows []map[string]any) []string {
+	seen := make(map[string]any)
+	for _, row := range rows {
+		for k := range row {
+			seen[k] = nil
+		}
+	}
+	return KeysOrEmpty(seen)
+}
+
+// Normalize adds the missing keys to the maps in rows with the value def, so
+// that every map has all the keys, i.e. for tabular exports.  If keys is nil,
+// the keys returned by UnionTags are used.  The maps are modified in place,
+// and nil maps are skipped.
+func Normalize(rows []map[string]any, keys []string, def any) {
+	if keys == nil {
+		keys = UnionTags(rows)
+	}
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		for _, k := range keys {
+			if _, ok := row[k]; !ok {
+				row[k] = def
 			}
 		}
-	}()
+	}
+}
+
+// DeepKeys returns a sorted list of the leaf key paths of the nested map m,
+// with the keys of the nested maps joined with sep, i.e. "address.city" for
+// sep ".".  Empty nested maps have no leaves, and are not listed.  It returns
+// nil if there are no leaf keys.
+func DeepKeys(m map[string]any, sep string) []string {
+	var kk []string
+	deepKeys(&kk, m, "", sep)
+	sort.Strings(kk)
+	return kk
+}
+
+// deepKeys appends the leaf key paths of the nested map m to kk, unsorted.
+// prefix is the key path of m, and sep is the path separator, see DeepKeys.
+func deepKeys(kk *[]string, m map[string]any, prefix, sep string) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + sep + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			deepKeys(kk, nested, k, sep)
+			continue
+		}
+		*kk = append(*kk, k)
+	}
+}
+
+// MapValues populates slice out with values from map m in the key order
+// specified by order.  The size of out slice will be adjusted to order size
+// to accomodate for all values, and keys missing from m have nil values.  It
+// returns an error if out or m is nil.
+func MapValues(out *[]any, m map[string]any, order []string) error {
+	if out == nil {
+		return errors.New("MapValues: nil out slice pointer")
+	}
+	if m == nil {
+		return errors.New("MapValues: nil map")
+	}
 	if len(*out) != len(order) {
 		resize(out, len(order))
 	}
@@ -145,33 +642,35 @@ func MapValues(out *[]any, m map[string]any, order []string) (err error) {
 	return nil
 }
 
-// errSkip is returned by tagName to indicate that the field should be skipped.
-var errSkip = errors.New("skip")
+// ValuesOf returns a new slice with values from map m in the key order
+// specified by order.  Keys missing from m have nil values.  It returns an
+// error if m is nil.  Use MapValues to reuse the slice between calls.
+func ValuesOf(m map[string]any, order []string) ([]any, error) {
+	if m == nil {
+		return nil, errors.New("ValuesOf: nil map")
+	}
+	out := make([]any, len(order))
+	for i, col := range order {
+		out[i] = m[col]
+	}
+	return out, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
 
-// tagName returns a tag name for the field, or an errSkip error if the field
+// tagName is like the package-level tagName, but applies the untagged field
+// policy and the key function, if set, to fields that have no name in the
+// tag.
+func (m Mapper) tagName(fld reflect.StructField, val reflect.Value, tag string, omitempty bool) (string, error) {
+	fp := newFieldPlan(fld, tag)
+	return m.fieldKey(&fp, val, omitempty)
+}
+
+// tagName returns a tag name for the field, or an ErrSkip error if the field
 // should be skipped.
 func tagName(fld reflect.StructField, val reflect.Value, tag string, omitempty bool) (string, error) {
-	if !isExported(fld.Name) {
-		return "", errSkip
-	}
-	tagValue := strings.SplitN(fld.Tag.Get(tag), tagsep, 2)
-	if len(tagValue) == 0 {
-		return fld.Name, nil
-	}
-	if strings.EqualFold(tagValue[0], "-") {
-		return "", errSkip
-	}
-	if tagValue[0] == "" {
-		tagValue[0] = fld.Name
-	}
-	if omitempty {
-		// if there's a tag option and that tag option is omitempty
-		// and field is empty.
-		if len(tagValue) > 1 && (tagValue[1] == fOmitEmpty && isEmpty(val)) {
-			return "", errSkip
-		}
-	}
-	return tagValue[0], nil
+	fp := newFieldPlan(fld, tag)
+	return fp.key(val, omitempty)
 }
 
 // isEmpty knows about some empty values.
@@ -200,11 +699,14 @@ func isEmpty(v reflect.Value) bool {
 	return false
 }
 
-// isExported returns true if the field is exported.
+// isExported returns true if the field is exported.  It never panics, empty
+// and malformed names are reported as not exported, like
+// go/token.IsExported.  The mapping reports such fields with
+// ErrInvalidFieldName, see fieldPlan.key.
 func isExported(fieldName string) bool {
 	firstRune, _ := utf8.DecodeRuneInString(fieldName)
 	if firstRune == utf8.RuneError {
-		panic(fmt.Sprintf("isExported: unsupported field: %q", fieldName))
+		return false
 	}
 	return unicode.In(firstRune, unicode.Lu)
 }
@@ -2,12 +2,15 @@ package tagops
 
 import (
 	"encoding/json"
+	"maps"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_resize(t *testing.T) {
@@ -324,7 +327,7 @@ func ExampleToMap_nested() {
 }
 
 func ExampleToMap_anonymous() {
-	// Anonymous structures are always flattened.
+	// Anonymous structures are flattened by default.
 	type Person struct {
 		Name string `json:"name,omitempty"`
 		Age  int    `json:"age,omitempty"`
@@ -363,28 +366,22 @@ func Test_isExported(t *testing.T) {
 		fieldName string
 	}
 	tests := []struct {
-		name      string
-		args      args
-		want      bool
-		wantpanic bool
+		name string
+		args args
+		want bool
 	}{
-		{"exported", args{"Name"}, true, false},
-		{"unexported", args{"name"}, false, false},
-		{"unexported", args{"_name"}, false, false},
-		{"unexported", args{"_Name"}, false, false},
-		{"unexported", args{"_"}, false, false},
-		{"empty", args{""}, false, true},
-		{"funky name", args{"🥐"}, false, false},
+		{"exported", args{"Name"}, true},
+		{"exported non-ascii", args{"Ärger"}, true},
+		{"unexported", args{"name"}, false},
+		{"unexported", args{"_name"}, false},
+		{"unexported", args{"_Name"}, false},
+		{"unexported", args{"_"}, false},
+		{"empty", args{""}, false},
+		{"invalid utf-8", args{"\xffName"}, false},
+		{"funky name", args{"🥐"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			defer func() {
-				if r := recover(); r != nil {
-					assert.True(t, tt.wantpanic, "should not have panicked, but did")
-				} else {
-					assert.False(t, tt.wantpanic, "should have panicked, but did not")
-				}
-			}()
 			if got := isExported(tt.args.fieldName); got != tt.want {
 				t.Errorf("isExported() = %v, want %v", got, tt.want)
 			}
@@ -421,6 +418,68 @@ func TestKeys(t *testing.T) {
 	}
 }
 
+func TestKeysOrEmpty(t *testing.T) {
+	assert.Equal(t, []string{}, KeysOrEmpty(nil))
+	assert.Equal(t, []string{}, KeysOrEmpty(map[string]any{}))
+	assert.Equal(t, []string{"a", "b"}, KeysOrEmpty(map[string]any{"b": 2, "a": 1}))
+}
+
+func TestUnionTags(t *testing.T) {
+	rows := []map[string]any{
+		{"id": 1},
+		{"id": 2, "phone": "555"},
+		{"id": 3, "email": "a@b"},
+	}
+	assert.Equal(t, []string{"email", "id", "phone"}, UnionTags(rows))
+	assert.Equal(t, []string{}, UnionTags(nil))
+}
+
+func TestNormalize_records(t *testing.T) {
+	rows := []map[string]any{
+		{"id": 1},
+		{"id": 2, "phone": "555"},
+		nil,
+	}
+	Normalize(rows, nil, "")
+	assert.Equal(t, []map[string]any{
+		{"id": 1, "phone": ""},
+		{"id": 2, "phone": "555"},
+		nil,
+	}, rows)
+
+	Normalize(rows, []string{"id", "email"}, nil)
+	assert.Equal(t, []map[string]any{
+		{"id": 1, "phone": "", "email": nil},
+		{"id": 2, "phone": "555", "email": nil},
+		nil,
+	}, rows)
+}
+
+func TestDeepKeys(t *testing.T) {
+	mp := map[string]any{
+		"name": "Bob",
+		"address": map[string]any{
+			"city": "Paris",
+			"geo":  map[string]any{"lat": 1.0, "lon": 2.0},
+		},
+		"tags":  []string{"a"},
+		"empty": map[string]any{},
+	}
+	assert.Equal(t, []string{"address.city", "address.geo.lat", "address.geo.lon", "name", "tags"}, DeepKeys(mp, "."))
+	assert.Equal(t, []string{"address_city", "address_geo_lat", "address_geo_lon", "name", "tags"}, DeepKeys(mp, "_"))
+	assert.Nil(t, DeepKeys(nil, "."))
+}
+
+func TestTags_nonNil(t *testing.T) {
+	type empty struct{}
+	type skipped struct {
+		Name string `json:"name,omitempty"`
+	}
+	assert.Equal(t, []string{}, Tags(empty{}, "json"))
+	assert.Equal(t, []string{}, Tags(42, "json"))
+	assert.Equal(t, []string{}, New(Omitempty()).Tags(skipped{}))
+}
+
 func TestMapValues(t *testing.T) {
 	type args struct {
 		m     map[string]any
@@ -483,6 +542,16 @@ func TestMapValues(t *testing.T) {
 			wantOut:    []any{26, 2},
 			wantErr:    false,
 		},
+		{
+			name: "nil map",
+			args: args{
+				m:     nil,
+				order: []string{"a"},
+			},
+			initialLen: 0,
+			wantOut:    []any{},
+			wantErr:    true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -493,6 +562,53 @@ func TestMapValues(t *testing.T) {
 			assert.Equal(t, tt.wantOut, out)
 		})
 	}
+	t.Run("nil out", func(t *testing.T) {
+		assert.Error(t, MapValues(nil, map[string]any{}, []string{"a"}))
+	})
+}
+
+func TestValuesOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       map[string]any
+		order   []string
+		want    []any
+		wantErr bool
+	}{
+		{
+			name:  "order is honored",
+			m:     map[string]any{"z": 26, "a": 1, "b": 2},
+			order: []string{"z", "a", "b"},
+			want:  []any{26, 1, 2},
+		},
+		{
+			name:  "missing keys are nil",
+			m:     map[string]any{"a": 1},
+			order: []string{"a", "b"},
+			want:  []any{1, nil},
+		},
+		{
+			name:  "empty order",
+			m:     map[string]any{"a": 1},
+			order: nil,
+			want:  []any{},
+		},
+		{
+			name:    "nil map",
+			m:       nil,
+			order:   []string{"a"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValuesOf(tt.m, tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValuesOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
 }
 
 func TestValues(t *testing.T) {
@@ -762,6 +878,17 @@ func Test_tagName(t *testing.T) {
 			want:    "created_at",
 			wantErr: false,
 		},
+		{
+			name: "malformed field without a name",
+			args: args{
+				fld:       reflect.StructField{Tag: `json:"name"`},
+				val:       reflect.ValueOf(""),
+				tag:       "json",
+				omitempty: false,
+			},
+			want:    "",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -800,3 +927,398 @@ func fieldValue(t *testing.T, a any, n int) (reflect.StructField, reflect.Value)
 	val := v.Field(n)
 	return fld, val
 }
+
+func TestMapper_TagsValues_consistent(t *testing.T) {
+	type Address struct {
+		Street string `json:"street,omitempty"`
+		ZIP    int    `json:"zip"`
+	}
+	type Person struct {
+		Name    string  `json:"name,omitempty"`
+		Age     int     `json:"age,omitempty"`
+		Address Address `json:"address"`
+	}
+	p := Person{Name: "John", Address: Address{ZIP: 12345}}
+
+	tests := []struct {
+		name       string
+		m          Mapper
+		wantTags   []string
+		wantValues []any
+	}{
+		{
+			name:       "omitempty, flatten",
+			m:          New(Omitempty(), Flatten()),
+			wantTags:   []string{"name", "zip"},
+			wantValues: []any{"John", 12345},
+		},
+		{
+			name:       "flatten",
+			m:          New(Flatten()),
+			wantTags:   []string{"age", "name", "street", "zip"},
+			wantValues: []any{0, "John", "", 12345},
+		},
+		{
+			name:       "omitempty, nested",
+			m:          New(Omitempty()),
+			wantTags:   []string{"address", "name"},
+			wantValues: []any{map[string]any{"zip": 12345}, "John"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := tt.m.Tags(p)
+			values, err := tt.m.Values(p)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTags, tags)
+			assert.Equal(t, tt.wantValues, values)
+		})
+	}
+}
+
+func TestMapper_ValuesFor(t *testing.T) {
+	type Person struct {
+		Name string `db:"name"`
+		Age  int    `db:"age,omitempty"`
+		City string `db:"city"`
+	}
+	type args struct {
+		a     any
+		order []string
+	}
+	tests := []struct {
+		name    string
+		m       Mapper
+		args    args
+		want    []any
+		wantErr bool
+	}{
+		{
+			name: "order is honored",
+			m:    New(Tag("db")),
+			args: args{Person{"John", 42, "Anytown"}, []string{"city", "name", "age"}},
+			want: []any{"Anytown", "John", 42},
+		},
+		{
+			name: "omitted and unknown tags are nil",
+			m:    New(Tag("db"), Omitempty()),
+			args: args{Person{Name: "John"}, []string{"name", "age", "unknown"}},
+			want: []any{"John", nil, nil},
+		},
+		{
+			name: "empty order",
+			m:    New(Tag("db")),
+			args: args{Person{}, nil},
+			want: []any{},
+		},
+		{
+			name:    "not a struct",
+			m:       New(Tag("db")),
+			args:    args{42, []string{"name"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.ValuesFor(tt.args.a, tt.args.order)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Mapper.ValuesFor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMapper_NoFlattenAnonymous(t *testing.T) {
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Meta struct {
+		Note string `json:"note"`
+	}
+	type Doc struct {
+		Base
+		Meta  `json:"meta"`
+		Title string `json:"title"`
+	}
+	type Named struct {
+		Base
+		Meta Meta `json:"meta"`
+	}
+	tests := []struct {
+		name string
+		m    Mapper
+		a    any
+		want map[string]any
+	}{
+		{
+			name: "default flattens",
+			m:    New(),
+			a:    Doc{Base{1}, Meta{"x"}, "t"},
+			want: map[string]any{"id": 1, "meta": map[string]any{"note": "x"}, "title": "t"},
+		},
+		{
+			name: "type name and tag name keys",
+			m:    New(NoFlattenAnonymous()),
+			a:    Doc{Base{1}, Meta{"x"}, "t"},
+			want: map[string]any{
+				"Base":  map[string]any{"id": 1},
+				"meta":  map[string]any{"note": "x"},
+				"title": "t",
+			},
+		},
+		{
+			name: "named structs are still flattened",
+			m:    New(NoFlattenAnonymous(), Flatten()),
+			a:    Named{Base{1}, Meta{"x"}},
+			want: map[string]any{"Base": map[string]any{"id": 1}, "note": "x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.ToMapE(tt.a)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			back := reflect.New(reflect.TypeOf(tt.a))
+			require.NoError(t, tt.m.FromMap(got, back.Interface()))
+			assert.Equal(t, tt.a, back.Elem().Interface())
+		})
+	}
+}
+
+func TestMapper_keyedEmbedding(t *testing.T) {
+	type Person struct {
+		Name string `json:"name" db:"name"`
+	}
+	type Audit struct {
+		By string `json:"by" db:"by"`
+	}
+	type Secret struct {
+		Token string `json:"token" db:"token"`
+	}
+	type Employee struct {
+		Person `json:"person"`
+		Audit  `json:",omitempty" db:"audit"`
+		Secret `json:"-"`
+		Title  string `json:"title" db:"title"`
+	}
+	e := Employee{Person{"Bob"}, Audit{"admin"}, Secret{"s3cr3t"}, "CTO"}
+
+	t.Run("matches encoding/json", func(t *testing.T) {
+		data, err := json.Marshal(e)
+		require.NoError(t, err)
+
+		got, err := New().ToMapE(e)
+		require.NoError(t, err)
+		gotData, err := json.Marshal(got)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(data), string(gotData))
+	})
+	t.Run("round trip", func(t *testing.T) {
+		m := New()
+		var got Employee
+		require.NoError(t, m.FromMap(m.ToMap(e), &got))
+		want := e
+		want.Secret = Secret{}
+		assert.Equal(t, want, got)
+	})
+	t.Run("ignored embedding is not populated", func(t *testing.T) {
+		var got Employee
+		require.NoError(t, New().FromMap(map[string]any{"token": "x"}, &got))
+		assert.Empty(t, got.Token)
+	})
+	t.Run("multimap", func(t *testing.T) {
+		got := New().ToMultiMap(e, "json", "db")
+		assert.Equal(t, map[string]map[string]any{
+			"json": {"person": map[string]any{"name": "Bob"}, "by": "admin", "title": "CTO"},
+			"db":   {"name": "Bob", "audit": map[string]any{"by": "admin"}, "token": "s3cr3t", "title": "CTO"},
+		}, got)
+	})
+}
+
+func TestMapper_With(t *testing.T) {
+	type Address struct {
+		City string `db:"city"`
+	}
+	type Person struct {
+		Name    string  `db:"name"`
+		Age     int     `db:"age,omitempty"`
+		Address Address `db:"address"`
+	}
+	p := Person{Name: "John", Address: Address{City: "Anytown"}}
+
+	base := New(Tag("db"))
+	derived := base.With(Flatten(), Omitempty())
+	assert.Equal(t, map[string]any{"name": "John", "city": "Anytown"}, derived.ToMap(p))
+	assert.Equal(t, map[string]any{
+		"name":    "John",
+		"age":     0,
+		"address": map[string]any{"city": "Anytown"},
+	}, base.ToMap(p), "base mapper must not be modified")
+	assert.Equal(t, base, base.With())
+}
+
+func TestMapper_Untagged(t *testing.T) {
+	type Base struct {
+		ID      int `json:"id"`
+		Created int
+	}
+	type S struct {
+		Base
+		Name     string `json:"name"`
+		Internal string
+		Note     string `json:",omitempty"`
+		Skipped  string `json:"-"`
+	}
+	s := S{Base: Base{ID: 1, Created: 2}, Name: "n", Internal: "i", Note: "x"}
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]any
+	}{
+		{
+			name: "default",
+			want: map[string]any{"id": 1, "Created": 2, "name": "n", "Internal": "i", "Note": "x"},
+		},
+		{
+			name: "key func",
+			opts: []Option{KeyFunc(strings.ToLower)},
+			want: map[string]any{"id": 1, "created": 2, "name": "n", "internal": "i", "note": "x"},
+		},
+		{
+			name: "field name",
+			opts: []Option{KeyFunc(strings.ToLower), Untagged(UseFieldName)},
+			want: map[string]any{"id": 1, "Created": 2, "name": "n", "Internal": "i", "Note": "x"},
+		},
+		{
+			name: "skip",
+			opts: []Option{Untagged(SkipUntagged)},
+			want: map[string]any{"id": 1, "name": "n"},
+		},
+		{
+			name: "skip, embedded not flattened",
+			opts: []Option{Untagged(SkipUntagged), NoFlattenAnonymous()},
+			want: map[string]any{"name": "n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.opts...)
+			got, err := m.ToMapE(s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			var back S
+			require.NoError(t, m.FromMap(got, &back))
+			assert.Equal(t, got, m.ToMap(back), "round trip")
+		})
+	}
+	t.Run("map keys", func(t *testing.T) {
+		got := New(KeyFunc(strings.ToLower), Untagged(SkipUntagged)).ToMap(map[string]any{"Key": 1})
+		assert.Equal(t, map[string]any{"key": 1}, got)
+	})
+}
+
+func TestMapper_Prefix(t *testing.T) {
+	type db struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type cfg struct {
+		Name string `json:"name"`
+		DB   db     `json:"db"`
+	}
+	v := cfg{Name: "api", DB: db{Host: "h", Port: 1}}
+	t.Run("ToMap", func(t *testing.T) {
+		m := New(Prefix("app."))
+		assert.Equal(t, map[string]any{
+			"app.name": "api",
+			"app.db":   map[string]any{"host": "h", "port": 1},
+		}, m.ToMap(v))
+		var got cfg
+		require.NoError(t, m.FromMap(map[string]any{
+			"app.name": "api",
+			"app.db":   map[string]any{"host": "h", "port": 1},
+			"name":     "ignored",
+		}, &got))
+		assert.Equal(t, v, got)
+	})
+	t.Run("ToKV", func(t *testing.T) {
+		m := New(Prefix("APP_"))
+		kv, err := m.ToKV(v, "", "_")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"APP_name": "api", "APP_db_host": "h", "APP_db_port": "1"}, kv)
+		var got cfg
+		require.NoError(t, m.FromKV(kv, "", "_", &got))
+		assert.Equal(t, v, got)
+	})
+	t.Run("combine", func(t *testing.T) {
+		out := New(Prefix("a.")).ToMap(db{Host: "x"})
+		maps.Copy(out, New(Prefix("b.")).ToMap(db{Port: 2}))
+		assert.Equal(t, map[string]any{"a.host": "x", "a.port": 0, "b.host": "", "b.port": 2}, out)
+	})
+	t.Run("TopLevel", func(t *testing.T) {
+		m := New(Prefix("app_"))
+		var keys []string
+		for k := range m.TopLevel(v) {
+			keys = append(keys, k)
+		}
+		assert.ElementsMatch(t, m.Tags(v), keys)
+	})
+	t.Run("defaults", func(t *testing.T) {
+		m := New(Prefix("app."), WithDefaults(map[string]any{"name": "def", "db": map[string]any{"port": 7}}))
+		var got cfg
+		require.NoError(t, m.FromMap(map[string]any{"app.db": map[string]any{"host": "h"}}, &got))
+		assert.Equal(t, cfg{Name: "def", DB: db{Host: "h", Port: 7}}, got)
+	})
+}
+
+func TestMapper_Version(t *testing.T) {
+	type profile struct {
+		Bio string `json:"bio" v2:"about"`
+	}
+	type user struct {
+		ID      int     `json:"id"`
+		Name    string  `json:"user_name" v1:"user_name" v2:"username"`
+		Legacy  string  `json:"legacy" v2:"-"`
+		Email   string  `json:"email,omitempty" v2:"email_address,omitempty"`
+		Profile profile `json:"profile"`
+	}
+	u := user{ID: 1, Name: "ann", Legacy: "x", Profile: profile{Bio: "hi"}}
+	tests := []struct {
+		name    string
+		version string
+		want    map[string]any
+	}{
+		{"no version", "", map[string]any{"id": 1, "user_name": "ann", "legacy": "x", "email": "", "profile": map[string]any{"bio": "hi"}}},
+		{"v1", "v1", map[string]any{"id": 1, "user_name": "ann", "legacy": "x", "email": "", "profile": map[string]any{"bio": "hi"}}},
+		{"v2", "v2", map[string]any{"id": 1, "username": "ann", "email_address": "", "profile": map[string]any{"about": "hi"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New().Version(tt.version)
+			got, err := m.ToMapE(u)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			var back user
+			require.NoError(t, m.FromMap(got, &back))
+			want := u
+			if tt.version == "v2" {
+				want.Legacy = ""
+			}
+			assert.Equal(t, want, back)
+		})
+	}
+	t.Run("omitempty", func(t *testing.T) {
+		got := New(Omitempty()).Version("v2").ToMap(user{ID: 1})
+		assert.NotContains(t, got, "email_address")
+	})
+	t.Run("does not modify the receiver", func(t *testing.T) {
+		m := New()
+		_ = m.Version("v2")
+		assert.Contains(t, m.ToMap(u), "user_name")
+	})
+}
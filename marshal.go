@@ -0,0 +1,156 @@
+package tagops
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// FieldOrder returns an Option that makes MarshalJSON write the keys in the
+// order of the struct field declaration, instead of the alphabetical order.
+// Flattened fields are written in place of the struct field, and keys that do
+// not belong to a field, i.e. added by the AfterStruct hook, are written
+// after the fields, in the alphabetical order.
+func FieldOrder() Option {
+	return func(m *Mapper) {
+		m.fieldOrder = true
+	}
+}
+
+// MarshalJSON returns the JSON encoding of the map of the struct a, converted
+// with the Mapper with options opts.  The keys are written in the
+// alphabetical order, or in the field order, if FieldOrder is set, so that
+// the output is the same for the same input, i.e. for hashing or golden
+// tests.  There is no insignificant whitespace.
+func MarshalJSON(a any, opts ...Option) ([]byte, error) {
+	m := New(opts...)
+	mp, err := m.ToMapE(a)
+	if err != nil {
+		return nil, err
+	}
+	if !m.fieldOrder {
+		return json.Marshal(mp)
+	}
+	var ord *keyOrder
+	if v, err := derefStruct(reflect.ValueOf(a)); err == nil {
+		ord = m.keyOrder(v.Type())
+	}
+	var buf bytes.Buffer
+	if err := writeOrdered(&buf, mp, ord); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// keyOrder is the order of the keys of the map of the struct type.
+type keyOrder struct {
+	keys   []string
+	nested map[string]*keyOrder
+}
+
+// keyOrder returns the order of the keys of the map of the struct type t.
+func (m Mapper) keyOrder(t reflect.Type) *keyOrder {
+	return m.typeKeyOrder(t, make(map[reflect.Type]*keyOrder))
+}
+
+// typeKeyOrder is keyOrder, that reuses the orders of the types in seen, so
+// that the recursive types, i.e. linked through pointers, terminate.
+func (m Mapper) typeKeyOrder(t reflect.Type, seen map[reflect.Type]*keyOrder) *keyOrder {
+	if ord, ok := seen[t]; ok {
+		return ord
+	}
+	ord := &keyOrder{nested: make(map[string]*keyOrder)}
+	seen[t] = ord
+	m.addKeyOrder(ord, t, seen)
+	return ord
+}
+
+func (m Mapper) addKeyOrder(ord *keyOrder, t reflect.Type, seen map[reflect.Type]*keyOrder) {
+	m = m.withProfile(t)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		nested := isNested(field.Type)
+		if nested && m.flattens(field, m.tagFor(field)) {
+			m.addKeyOrder(ord, field.Type, seen)
+			continue
+		}
+		key, err := m.tagName(field, reflect.Value{}, m.tagFor(field), false)
+		if err != nil || slices.Contains(ord.keys, key) {
+			continue
+		}
+		ord.keys = append(ord.keys, key)
+		switch et, ok := structElem(field.Type); {
+		case nested:
+			ord.nested[key] = m.typeKeyOrder(field.Type, seen)
+		case isNestedPtr(field.Type):
+			ord.nested[key] = m.typeKeyOrder(field.Type.Elem(), seen)
+		case ok && field.Type.Kind() != reflect.Map:
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			ord.nested[key] = m.typeKeyOrder(et, seen)
+		}
+	}
+}
+
+// sorted returns the keys of mp in order ord, if not nil.  The keys missing
+// in ord are returned after, in the alphabetical order.
+func (ord *keyOrder) sorted(mp map[string]any) []string {
+	var keys []string
+	if ord != nil {
+		for _, k := range ord.keys {
+			if _, ok := mp[k]; ok {
+				keys = append(keys, k)
+			}
+		}
+	}
+	for _, k := range Keys(mp) {
+		if ord == nil || !slices.Contains(ord.keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// sub returns the order of the nested map with the key k, or nil.
+func (ord *keyOrder) sub(k string) *keyOrder {
+	if ord == nil {
+		return nil
+	}
+	return ord.nested[k]
+}
+
+// writeOrdered writes the JSON encoding of mp to buf, with the keys in order
+// ord, if not nil.  The keys missing in ord are written after, in the
+// alphabetical order.
+func writeOrdered(buf *bytes.Buffer, mp map[string]any, ord *keyOrder) error {
+	keys := ord.sorted(mp)
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := json.Marshal(k)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte(':')
+		if nested, ok := mp[k].(map[string]any); ok {
+			if err := writeOrdered(buf, nested, ord.sub(k)); err != nil {
+				return err
+			}
+			continue
+		}
+		if data, err = json.Marshal(mp[k]); err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return nil
+}
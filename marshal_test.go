@@ -0,0 +1,112 @@
+package tagops
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type marshalAddr struct {
+	Zip  string `json:"zip"`
+	City string `json:"city"`
+}
+
+type MarshalBase struct {
+	ID int `json:"id"`
+}
+
+type marshalUser struct {
+	Name string `json:"name"`
+	MarshalBase
+	Email   string      `json:"email,omitempty"`
+	Address marshalAddr `json:"address"`
+	Age     int         `json:"age"`
+}
+
+type marshalNode struct {
+	Name string       `json:"name"`
+	Addr *marshalAddr `json:"addr,omitempty"`
+	Next *marshalNode `json:"next,omitempty"`
+}
+
+var marshalSample = marshalUser{
+	Name:        "Bob",
+	MarshalBase: MarshalBase{ID: 7},
+	Address:     marshalAddr{Zip: "75001", City: "Paris"},
+	Age:         42,
+}
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       any
+		opts    []Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "alphabetical",
+			a:    marshalSample,
+			want: `{"address":{"city":"Paris","zip":"75001"},"age":42,"email":"","id":7,"name":"Bob"}`,
+		},
+		{
+			name: "field order",
+			a:    &marshalSample,
+			opts: []Option{FieldOrder(), Omitempty()},
+			want: `{"name":"Bob","id":7,"address":{"zip":"75001","city":"Paris"},"age":42}`,
+		},
+		{
+			name: "field order flattened",
+			a:    marshalSample,
+			opts: []Option{FieldOrder(), Flatten()},
+			want: `{"name":"Bob","id":7,"email":"","zip":"75001","city":"Paris","age":42}`,
+		},
+		{
+			name: "extra keys go last",
+			a:    marshalSample,
+			opts: []Option{FieldOrder(), NoFlattenAnonymous(), AfterStruct(func(mp map[string]any) {
+				if _, ok := mp["name"]; ok {
+					mp["b_extra"] = true
+					mp["a_extra"] = true
+				}
+			})},
+			want: `{"name":"Bob","MarshalBase":{"id":7},"email":"","address":{"zip":"75001","city":"Paris"},"age":42,"a_extra":true,"b_extra":true}`,
+		},
+		{
+			name: "field order of pointers",
+			a: marshalNode{Name: "a", Addr: &marshalAddr{Zip: "75001", City: "Paris"},
+				Next: &marshalNode{Name: "b"}},
+			opts: []Option{FieldOrder(), Omitempty()},
+			want: `{"name":"a","addr":{"zip":"75001","city":"Paris"},"next":{"name":"b"}}`,
+		},
+		{
+			name:    "not a struct",
+			a:       42,
+			opts:    []Option{FieldOrder()},
+			wantErr: ErrNotStruct,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalJSON(tt.a, tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+			assert.True(t, json.Valid(got))
+		})
+	}
+}
+
+func TestMarshalJSON_stable(t *testing.T) {
+	first, err := MarshalJSON(marshalSample, FieldOrder())
+	assert.NoError(t, err)
+	for range 20 {
+		got, err := MarshalJSON(marshalSample, FieldOrder())
+		assert.NoError(t, err)
+		assert.Equal(t, first, got)
+	}
+}
@@ -0,0 +1,196 @@
+package tagops
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidMask is returned when the field mask can not be parsed.
+var ErrInvalidMask = errors.New("invalid field mask")
+
+// mask is the parsed field mask.  The nil mask selects the whole value.
+type mask map[string]mask
+
+// ToMapMasked returns the map of the fields of a selected by the field mask,
+// see Mapper.ToMapMasked.
+func ToMapMasked(a any, fieldMask string) (map[string]any, error) {
+	return New().ToMapMasked(a, fieldMask)
+}
+
+// ToMapMasked is like ToMapE, but returns only the fields selected by the
+// field mask, i.e. for sparse responses with ?fields= parameter.  The mask is
+// the comma-separated list of key paths, i.e. "name,address.city".  The
+// nested keys may be separated with "." or "/", and several nested keys may
+// be selected with parentheses, i.e. "address(city,zip)".  A path to a nested
+// map selects all of it.  Paths that are not in the map are ignored.
+func (m Mapper) ToMapMasked(a any, fieldMask string) (map[string]any, error) {
+	mk, err := parseMask(fieldMask)
+	if err != nil {
+		return nil, err
+	}
+	mp, err := m.ToMapE(a)
+	if mp == nil {
+		return nil, err
+	}
+	return mk.apply(mp), err
+}
+
+// Include returns an Option that limits the maps returned by ToMap to the
+// dot-separated key paths, i.e. "name" or "address.city".  A path to a nested
+// map selects all of it.  It is applied to the root map, after the computed
+// keys are added, see WithComputed.
+func Include(paths ...string) Option {
+	return func(m *Mapper) {
+		m.include = maskOf(paths)
+	}
+}
+
+// Exclude returns an Option that removes the dot-separated key paths from
+// the maps returned by ToMap, i.e. "password" or "address.zip".  It is
+// applied after Include.
+func Exclude(paths ...string) Option {
+	return func(m *Mapper) {
+		m.exclude = maskOf(paths)
+	}
+}
+
+// maskOf returns the mask of the dot-separated key paths.
+func maskOf(paths []string) mask {
+	if len(paths) == 0 {
+		return nil
+	}
+	mk := make(mask)
+	for _, p := range paths {
+		cur := mk
+		keys := strings.Split(p, ".")
+		for i, key := range keys {
+			sub, ok := cur[key]
+			if ok && sub == nil {
+				break // the whole value is selected already
+			}
+			if i == len(keys)-1 {
+				cur[key] = nil
+				break
+			}
+			if !ok {
+				sub = make(mask)
+				cur[key] = sub
+			}
+			cur = sub
+		}
+	}
+	return mk
+}
+
+// remove removes the keys selected by the mask from mp.
+func (mk mask) remove(mp map[string]any) {
+	for key, sub := range mk {
+		if sub == nil {
+			delete(mp, key)
+			continue
+		}
+		if nested, ok := mp[key].(map[string]any); ok {
+			sub.remove(nested)
+		}
+	}
+}
+
+// apply returns the map with the keys of mp selected by the mask.
+func (mk mask) apply(mp map[string]any) map[string]any {
+	out := make(map[string]any, len(mk))
+	for key, sub := range mk {
+		val, ok := mp[key]
+		if !ok {
+			continue
+		}
+		if nested, isMap := val.(map[string]any); isMap && sub != nil {
+			val = sub.apply(nested)
+		}
+		out[key] = val
+	}
+	return out
+}
+
+// parseMask parses the field mask s.
+func parseMask(s string) (mask, error) {
+	p := maskParser{s: s}
+	mk := make(mask)
+	if err := p.list(mk); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return mk, nil
+}
+
+// maskParser is the recursive descent parser of the field masks.
+type maskParser struct {
+	s   string
+	pos int
+}
+
+func (p *maskParser) errorf(format string, a ...any) error {
+	return fmt.Errorf("%w: %q at %d: %s", ErrInvalidMask, p.s, p.pos, fmt.Sprintf(format, a...))
+}
+
+// list parses the comma-separated list of items into mk.
+func (p *maskParser) list(mk mask) error {
+	for {
+		if err := p.item(mk); err != nil {
+			return err
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != ',' {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+// item parses the key with optional nested selection into mk.
+func (p *maskParser) item(mk mask) error {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(",./()", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	key := strings.TrimSpace(p.s[start:p.pos])
+	if key == "" {
+		return p.errorf("empty key")
+	}
+	sub, exists := mk[key]
+	if exists && sub == nil {
+		// the whole value is already selected, parse and discard.
+		sub = make(mask)
+		defer func() { mk[key] = nil }()
+	}
+	if p.pos >= len(p.s) {
+		mk[key] = nil
+		return nil
+	}
+	switch p.s[p.pos] {
+	case '.', '/':
+		p.pos++
+		if sub == nil {
+			sub = make(mask)
+		}
+		mk[key] = sub
+		return p.item(sub)
+	case '(':
+		p.pos++
+		if sub == nil {
+			sub = make(mask)
+		}
+		mk[key] = sub
+		if err := p.list(sub); err != nil {
+			return err
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return p.errorf("missing )")
+		}
+		p.pos++
+		return nil
+	}
+	mk[key] = nil
+	return nil
+}
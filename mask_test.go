@@ -0,0 +1,73 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMapMasked(t *testing.T) {
+	type address struct {
+		City   string `json:"city"`
+		Street string `json:"street"`
+		Zip    string `json:"zip"`
+	}
+	type person struct {
+		Name    string  `json:"name"`
+		Age     int     `json:"age"`
+		Address address `json:"address"`
+	}
+	p := person{Name: "Bob", Age: 42, Address: address{City: "Paris", Street: "Rue", Zip: "75001"}}
+	tests := []struct {
+		name    string
+		mask    string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name: "top level and nested",
+			mask: "name,address.city",
+			want: map[string]any{"name": "Bob", "address": map[string]any{"city": "Paris"}},
+		},
+		{
+			name: "slash separator",
+			mask: "address/zip",
+			want: map[string]any{"address": map[string]any{"zip": "75001"}},
+		},
+		{
+			name: "parentheses",
+			mask: "age,address(city,zip)",
+			want: map[string]any{"age": 42, "address": map[string]any{"city": "Paris", "zip": "75001"}},
+		},
+		{
+			name: "whole nested map wins",
+			mask: "address.city,address",
+			want: map[string]any{"address": map[string]any{"city": "Paris", "street": "Rue", "zip": "75001"}},
+		},
+		{
+			name: "whole nested map first",
+			mask: "address,address.city",
+			want: map[string]any{"address": map[string]any{"city": "Paris", "street": "Rue", "zip": "75001"}},
+		},
+		{
+			name: "unknown paths are ignored",
+			mask: "name,phone,name.first",
+			want: map[string]any{"name": "Bob"},
+		},
+		{name: "empty mask", mask: "", wantErr: true},
+		{name: "empty key", mask: "name,,age", wantErr: true},
+		{name: "unclosed parenthesis", mask: "address(city", wantErr: true},
+		{name: "trailing parenthesis", mask: "name)", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToMapMasked(p, tt.mask)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidMask)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package tagops
+
+import (
+	"fmt"
+)
+
+// CollisionPolicy defines how MergeToMap resolves the keys that are present
+// in several maps.
+type CollisionPolicy int
+
+const (
+	// LastWins keeps the value of the last struct, so that the later structs
+	// override the earlier ones.  This is the default.
+	LastWins CollisionPolicy = iota
+	// FirstWins keeps the value of the first struct, so that the later
+	// structs only fill in the missing keys.
+	FirstWins
+	// FailOnCollision returns an error wrapping ErrKeyConflict.
+	FailOnCollision
+)
+
+// MergeToMap converts the structs vs to maps and merges them into one map,
+// see Mapper.MergeToMap.
+func MergeToMap(policy CollisionPolicy, vs ...any) (map[string]any, error) {
+	return New().MergeToMap(policy, vs...)
+}
+
+// MergeToMap converts the structs vs to maps and merges them into one map,
+// in order, i.e. for the base config, the overrides and the computed fields.
+// The nested maps are merged key by key, and the keys present in several
+// maps, that are not nested maps in all of them, are resolved with the
+// policy.  Use Omitempty, so that the empty fields of the overrides, tagged
+// with omitempty, do not replace the base values.
+//
+// The conversion errors are returned with the index of the value.  The
+// collisions are returned as FieldError with the key path.
+func (m Mapper) MergeToMap(policy CollisionPolicy, vs ...any) (map[string]any, error) {
+	out := make(map[string]any)
+	for i, v := range vs {
+		mp, err := m.ToMapE(v)
+		if err != nil {
+			return nil, fmt.Errorf("value %d: %w", i, err)
+		}
+		if err := mergeInto(out, mp, "", policy); err != nil {
+			return nil, fmt.Errorf("value %d: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// mergeInto merges src into dst with the policy.  path is the path of dst.
+func mergeInto(dst, src map[string]any, path string, policy CollisionPolicy) error {
+	for _, k := range Keys(src) {
+		sv, kpath := src[k], joinKey(path, k)
+		dv, ok := dst[k]
+		if !ok {
+			dst[k] = sv
+			continue
+		}
+		dm, dOK := dv.(map[string]any)
+		sm, sOK := sv.(map[string]any)
+		if dOK && sOK {
+			if err := mergeInto(dm, sm, kpath, policy); err != nil {
+				return err
+			}
+			continue
+		}
+		switch policy {
+		case LastWins:
+			dst[k] = sv
+		case FirstWins:
+		case FailOnCollision:
+			return &FieldError{Path: kpath, Err: ErrKeyConflict}
+		default:
+			return fmt.Errorf("unknown collision policy: %d", policy)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeDB struct {
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+}
+
+type mergeBase struct {
+	Name string  `json:"name,omitempty"`
+	Env  string  `json:"env,omitempty"`
+	DB   mergeDB `json:"db"`
+}
+
+type mergeComputed struct {
+	Version string `json:"version"`
+}
+
+func TestMergeToMap(t *testing.T) {
+	base := mergeBase{Name: "api", Env: "dev", DB: mergeDB{Host: "localhost", Port: 5432}}
+	override := mergeBase{Env: "prod", DB: mergeDB{Host: "db.prod"}}
+	computed := mergeComputed{Version: "1.2.3"}
+
+	tests := []struct {
+		name    string
+		m       Mapper
+		policy  CollisionPolicy
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name:   "last wins",
+			m:      New(Omitempty()),
+			policy: LastWins,
+			want: map[string]any{
+				"name":    "api",
+				"env":     "prod",
+				"db":      map[string]any{"host": "db.prod", "port": 5432},
+				"version": "1.2.3",
+			},
+		},
+		{
+			name:   "first wins",
+			m:      New(Omitempty()),
+			policy: FirstWins,
+			want: map[string]any{
+				"name":    "api",
+				"env":     "dev",
+				"db":      map[string]any{"host": "localhost", "port": 5432},
+				"version": "1.2.3",
+			},
+		},
+		{
+			name:    "fail on collision",
+			m:       New(Omitempty()),
+			policy:  FailOnCollision,
+			wantErr: "value 1: db.host: key conflict",
+		},
+		{
+			name:   "without omitempty",
+			m:      New(),
+			policy: LastWins,
+			want: map[string]any{
+				"name":    "",
+				"env":     "prod",
+				"db":      map[string]any{"host": "db.prod", "port": 0},
+				"version": "1.2.3",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.MergeToMap(tt.policy, base, &override, computed)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, ErrKeyConflict)
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("nested and leaf", func(t *testing.T) {
+		type flat struct {
+			DB string `json:"db"`
+		}
+		got, err := MergeToMap(LastWins, base, flat{DB: "dsn"})
+		require.NoError(t, err)
+		assert.Equal(t, "dsn", got["db"])
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		_, err := MergeToMap(LastWins, base, 42)
+		assert.ErrorIs(t, err, ErrNotStruct)
+		assert.ErrorContains(t, err, "value 1:")
+	})
+	t.Run("empty", func(t *testing.T) {
+		got, err := MergeToMap(LastWins)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
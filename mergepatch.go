@@ -0,0 +1,138 @@
+package tagops
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// MergePatch applies the JSON merge patch to the struct pointed to by dst,
+// see Mapper.MergePatch.
+func MergePatch(dst any, patch any) error {
+	return New().MergePatch(dst, patch)
+}
+
+// MergePatch applies the JSON merge patch (RFC 7386) to the struct pointed to
+// by dst.  The patch is either the JSON document as []byte or
+// json.RawMessage, or the decoded map[string]any.  The keys are matched to
+// the fields by tag, as in FromMap:
+//   - the fields, which keys are absent in the patch, are not changed;
+//   - null resets the field to the zero value, i.e. nil pointer;
+//   - the objects are merged into the nested structs, pointers to structs, and
+//     maps, recursively, null in the nested object deletes the map key;
+//   - other values replace the field value.
+func (m Mapper) MergePatch(dst any, patch any) error {
+	var src map[string]any
+	switch p := patch.(type) {
+	case map[string]any:
+		src = p
+	case []byte:
+		var v any
+		if err := json.Unmarshal(p, &v); err != nil {
+			return fmt.Errorf("merge patch: %w", err)
+		}
+		src, _ = v.(map[string]any)
+	case json.RawMessage:
+		return m.MergePatch(dst, []byte(p))
+	default:
+		return fmt.Errorf("merge patch: unsupported patch type %T", patch)
+	}
+	if src == nil {
+		// the patch that is not an object replaces the whole target, which
+		// is not possible for a struct.
+		return fmt.Errorf("merge patch: %w: patch is not an object", ErrNotStruct)
+	}
+	m.mergeMaps = true
+	return m.FromMap(src, dst)
+}
+
+// mergeMap merges the patch into the map field fv.  It returns false if the
+// conversion should stop.
+func (m Mapper) mergeMap(st *state, fv reflect.Value, patch map[string]any, path string) bool {
+	if fv.IsNil() {
+		fv.Set(reflect.MakeMapWithSize(fv.Type(), len(patch)))
+	}
+	kt, et := fv.Type().Key(), fv.Type().Elem()
+	for _, k := range Keys(patch) {
+		pv := patch[k]
+		key := reflect.ValueOf(k).Convert(kt)
+		if pv == nil {
+			fv.SetMapIndex(key, reflect.Value{})
+			continue
+		}
+		epath := fmt.Sprintf("%s[%s]", path, k)
+		elem := reflect.New(et).Elem()
+		if old := fv.MapIndex(key); old.IsValid() {
+			elem.Set(old)
+		}
+		var ok bool
+		if nested, isMap := pv.(map[string]any); isMap {
+			ok = m.mergeElem(st, elem, nested, epath)
+		} else {
+			ok = m.fromElemValue(st, elem, pv, epath)
+		}
+		if !ok {
+			return false
+		}
+		fv.SetMapIndex(key, elem)
+	}
+	return true
+}
+
+// mergeElem merges the object patch into the map element elem.
+func (m Mapper) mergeElem(st *state, elem reflect.Value, patch map[string]any, path string) bool {
+	if elem.Kind() == reflect.Interface {
+		// i.e. the element of map[string]any.
+		prev, _ := elem.Interface().(map[string]any)
+		elem.Set(reflect.ValueOf(mergeAny(prev, patch)))
+		return true
+	}
+	switch {
+	case elem.Kind() == reflect.Map && elem.Type().Key().Kind() == reflect.String:
+		return m.mergeMap(st, elem, patch, path)
+	case isNested(elem.Type()):
+		return m.fromMap(st, patch, elem, path)
+	case isNestedPtr(elem.Type()):
+		if elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		} else {
+			// do not modify the struct shared with the original map value.
+			cp := reflect.New(elem.Type().Elem())
+			cp.Elem().Set(elem.Elem())
+			elem.Set(cp)
+		}
+		return m.fromMap(st, patch, elem.Elem(), path)
+	}
+	return m.fromElemValue(st, elem, patch, path)
+}
+
+// fromElemValue assigns the value sv to the map element elem.
+func (m Mapper) fromElemValue(st *state, elem reflect.Value, sv any, path string) bool {
+	if err := m.assign(st.ctx, elem, sv, nil); err != nil {
+		st.fail(path, err)
+		return m.collectErrors
+	}
+	return true
+}
+
+// mergeAny returns the result of merging the patch into the decoded JSON
+// object target.  The target is not modified.
+func mergeAny(target, patch map[string]any) map[string]any {
+	out := make(map[string]any, len(target)+len(patch))
+	for k, v := range target {
+		out[k] = v
+	}
+	for k, pv := range patch {
+		if pv == nil {
+			delete(out, k)
+			continue
+		}
+		if pm, ok := pv.(map[string]any); ok {
+			tm, _ := out[k].(map[string]any)
+			out[k] = mergeAny(tm, pm)
+			continue
+		}
+		out[k] = pv
+	}
+	return out
+}
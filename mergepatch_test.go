@@ -0,0 +1,97 @@
+package tagops
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mpAuthor struct {
+	GivenName  string  `json:"givenName"`
+	FamilyName *string `json:"familyName"`
+}
+
+type mpDoc struct {
+	Title   string              `json:"title"`
+	Author  mpAuthor            `json:"author"`
+	Editor  *mpAuthor           `json:"editor"`
+	Tags    []string            `json:"tags"`
+	Content string              `json:"content"`
+	Phone   *string             `json:"phoneNumber"`
+	Labels  map[string]string   `json:"labels"`
+	Meta    map[string]any      `json:"meta"`
+	Authors map[string]mpAuthor `json:"authors"`
+}
+
+func TestMergePatch(t *testing.T) {
+	smith := "Smith"
+	newDoc := func() mpDoc {
+		return mpDoc{
+			Title:   "Goodbye!",
+			Author:  mpAuthor{GivenName: "John", FamilyName: &smith},
+			Editor:  &mpAuthor{GivenName: "Jane"},
+			Tags:    []string{"example", "sample"},
+			Content: "This will be unchanged",
+			Labels:  map[string]string{"a": "1", "b": "2"},
+			Meta:    map[string]any{"x": map[string]any{"y": 1.0, "z": 2.0}, "w": true},
+			Authors: map[string]mpAuthor{"js": {GivenName: "John"}},
+		}
+	}
+	// the example from RFC 7386, section 3, extended with maps.
+	patch := []byte(`{
+		"title": "Hello!",
+		"phoneNumber": "+01-123-456-7890",
+		"author": {"familyName": null},
+		"editor": {"familyName": "Doe"},
+		"tags": ["example"],
+		"labels": {"a": null, "c": "3"},
+		"meta": {"x": {"y": null, "v": 3}, "w": null, "n": {"k": null}},
+		"authors": {"js": {"familyName": "Smith"}, "jd": {"givenName": "Jane"}}
+	}`)
+	doc := newDoc()
+	editor := doc.Editor
+	require.NoError(t, MergePatch(&doc, patch))
+
+	phone := "+01-123-456-7890"
+	doe := "Doe"
+	assert.Equal(t, mpDoc{
+		Title:   "Hello!",
+		Author:  mpAuthor{GivenName: "John"},
+		Editor:  &mpAuthor{GivenName: "Jane", FamilyName: &doe},
+		Tags:    []string{"example"},
+		Content: "This will be unchanged",
+		Phone:   &phone,
+		Labels:  map[string]string{"b": "2", "c": "3"},
+		Meta:    map[string]any{"x": map[string]any{"z": 2.0, "v": 3.0}, "n": map[string]any{}},
+		Authors: map[string]mpAuthor{"js": {GivenName: "John", FamilyName: &smith}, "jd": {GivenName: "Jane"}},
+	}, doc)
+	assert.Same(t, editor, doc.Editor, "nested pointer is merged in place")
+
+	t.Run("map patch and null pointer", func(t *testing.T) {
+		doc := newDoc()
+		require.NoError(t, New().MergePatch(&doc, map[string]any{"editor": nil, "labels": nil}))
+		assert.Nil(t, doc.Editor)
+		assert.Nil(t, doc.Labels)
+		assert.Equal(t, "Goodbye!", doc.Title)
+	})
+	t.Run("raw message", func(t *testing.T) {
+		doc := newDoc()
+		require.NoError(t, MergePatch(&doc, json.RawMessage(`{"content":"x"}`)))
+		assert.Equal(t, "x", doc.Content)
+	})
+	t.Run("errors", func(t *testing.T) {
+		doc := newDoc()
+		assert.Error(t, MergePatch(&doc, []byte(`{`)))
+		assert.ErrorIs(t, MergePatch(&doc, []byte(`[1]`)), ErrNotStruct)
+		assert.ErrorIs(t, MergePatch(&doc, []byte(`null`)), ErrNotStruct)
+		assert.Error(t, MergePatch(&doc, "{}"))
+		assert.ErrorIs(t, MergePatch(doc, []byte(`{}`)), ErrNotStruct)
+
+		err := MergePatch(&doc, []byte(`{"labels": {"a": 1}}`))
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "Labels[a]", fe.Path)
+	})
+}
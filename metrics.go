@@ -0,0 +1,67 @@
+package tagops
+
+import "time"
+
+// Metrics is the set of callbacks that receive the counters and timings of
+// the Mapper, see WithMetrics.  Any of the callbacks may be nil.  The
+// callbacks are called synchronously, and must be safe for concurrent use, if
+// the Mapper is shared.  The op argument is "ToMap" or "FromMap".
+type Metrics struct {
+	// Conversion is called after each ToMap or FromMap call with the
+	// duration of the call, and the returned error.
+	Conversion func(op string, d time.Duration, err error)
+	// FieldConverted is called for each converted leaf field.
+	FieldConverted func(op string)
+	// FieldSkipped is called for each skipped field, with the reason, as
+	// reported in TraceEvent.
+	FieldSkipped func(op string, reason string)
+	// PlanCacheHit and PlanCacheMiss are called for each lookup of the
+	// struct plan, that holds the parsed tags of the struct type, in the
+	// plan cache, see CacheStats.  The miss means that the tags were parsed.
+	PlanCacheHit  func()
+	PlanCacheMiss func()
+}
+
+// WithMetrics returns an Option that reports the conversions performed, the
+// fields converted and skipped, the plan cache lookups, and the durations of
+// the ToMap and FromMap calls to the callbacks in mt, so that the overhead of
+// the Mapper can be watched in production without a dependency on a metrics
+// library.
+func WithMetrics(mt Metrics) Option {
+	return func(m *Mapper) {
+		m.metrics = &mt
+	}
+}
+
+// conversion reports the conversion op, started at start, with the error
+// err.
+func (mt *Metrics) conversion(op string, start time.Time, err error) {
+	if mt.Conversion != nil {
+		mt.Conversion(op, time.Since(start), err)
+	}
+}
+
+// planLookup reports the plan cache lookup, hit is true if the plan was
+// cached.
+func (mt *Metrics) planLookup(hit bool) {
+	switch {
+	case hit && mt.PlanCacheHit != nil:
+		mt.PlanCacheHit()
+	case !hit && mt.PlanCacheMiss != nil:
+		mt.PlanCacheMiss()
+	}
+}
+
+// event updates the field counters from the trace event ev.
+func (mt *Metrics) event(ev TraceEvent) {
+	switch ev.Kind {
+	case TraceFieldSkipped:
+		if mt.FieldSkipped != nil {
+			mt.FieldSkipped(ev.Op, ev.Reason)
+		}
+	case TraceValueConverted:
+		if mt.FieldConverted != nil {
+			mt.FieldConverted(ev.Op)
+		}
+	}
+}
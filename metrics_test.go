@@ -0,0 +1,104 @@
+package tagops
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricsRecorder counts the metrics callbacks.
+type metricsRecorder struct {
+	mu        sync.Mutex
+	calls     map[string]int
+	errors    map[string]int
+	converted map[string]int
+	skipped   map[string]int
+}
+
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{
+		calls:     map[string]int{},
+		errors:    map[string]int{},
+		converted: map[string]int{},
+		skipped:   map[string]int{},
+	}
+}
+
+func (r *metricsRecorder) metrics() Metrics {
+	return Metrics{
+		Conversion: func(op string, d time.Duration, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.calls[op]++
+			if err != nil {
+				r.errors[op]++
+			}
+		},
+		FieldConverted: func(op string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.converted[op]++
+		},
+		FieldSkipped: func(op string, reason string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.skipped[op+": "+reason]++
+		},
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	r := newMetricsRecorder()
+	m := New(Omitempty(), WithMetrics(r.metrics()))
+
+	_, err := m.ToMapE(traceUser{Name: "ann", Age: 42})
+	require.NoError(t, err)
+	_, err = m.ToMapE(42)
+	require.Error(t, err)
+	var u traceUser
+	require.NoError(t, m.FromMap(map[string]any{"name": "bob"}, &u))
+
+	assert.Equal(t, map[string]int{"ToMap": 2, "FromMap": 1}, r.calls)
+	assert.Equal(t, map[string]int{"ToMap": 1}, r.errors)
+	assert.Equal(t, map[string]int{"ToMap": 2, "FromMap": 1}, r.converted)
+	assert.Equal(t, map[string]int{
+		"ToMap: unexported":             1,
+		"ToMap: empty, with omitempty":  1,
+		"ToMap: write-only":             1,
+		"FromMap: unexported":           1,
+		"FromMap: absent in the source": 3,
+	}, r.skipped)
+}
+
+func TestWithMetrics_partial(t *testing.T) {
+	var n int
+	m := New(WithMetrics(Metrics{FieldConverted: func(string) { n++ }}))
+	_, err := m.ToMapE(traceUser{Name: "ann"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestWithMetrics_planCache(t *testing.T) {
+	type S struct {
+		A int `json:"a"`
+	}
+	var hits, misses int
+	m := New(WithMetrics(Metrics{
+		PlanCacheHit:  func() { hits++ },
+		PlanCacheMiss: func() { misses++ },
+	}))
+	_, err := m.ToMapE(S{A: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 1, misses, "the plan of the new type is built")
+	before := hits
+
+	_, err = m.ToMapE(S{A: 2})
+	require.NoError(t, err)
+	var s S
+	require.NoError(t, m.FromMap(map[string]any{"a": 3}, &s))
+	assert.Equal(t, 1, misses)
+	assert.GreaterOrEqual(t, hits-before, 2, "the plan is cached")
+}
@@ -0,0 +1,150 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ToMultiMap converts the struct a to maps for each of the tags in one pass.
+// The returned map is keyed by tag, and each value is the map that ToMap
+// would return for that tag.  The field values are converted once and shared
+// between the views.  Hooks are not called.  Conversion and encryption
+// errors are ignored, use ToMultiMapE to get them.
+func (m Mapper) ToMultiMap(a any, tags ...string) map[string]map[string]any {
+	out, _ := m.ToMultiMapE(a, tags...)
+	return out
+}
+
+// ToMultiMapE is like ToMultiMap, but returns the conversion and encryption
+// errors.  Fields that failed to convert have their original values, and
+// fields that failed to encrypt are omitted, as in ToMapE.  It returns
+// ErrNotStruct if a is not a struct.
+func (m Mapper) ToMultiMapE(a any, tags ...string) (map[string]map[string]any, error) {
+	if p, ok := a.(MapProvider); ok {
+		out := make(map[string]map[string]any, len(tags))
+		for _, tag := range tags {
+			out[tag] = p.TagOpsMap(tag)
+		}
+		return out, nil
+	}
+	v := reflect.ValueOf(a)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrNotStruct, a)
+	}
+	st := newState(context.Background())
+	return m.toMultiMap(st, v, tags), st.err(m.collectErrors)
+}
+
+// toMultiMap converts the struct value v to maps for each of the tags.
+func (m Mapper) toMultiMap(st *state, v reflect.Value, tags []string) map[string]map[string]any {
+	es := m.walkMulti(st, v, tags, "")
+	out := make(map[string]map[string]any, len(tags))
+	for _, tag := range tags {
+		out[tag] = es[tag].materialize()
+	}
+	return out
+}
+
+// walkMulti converts the struct value v to entries for each of the tags.
+// path is the path of v from the root struct.
+func (m Mapper) walkMulti(st *state, v reflect.Value, tags []string, path string) map[string]entries {
+	out := make(map[string]entries, len(tags))
+	for _, tag := range tags {
+		out[tag] = make(entries)
+	}
+
+	typ := v.Type()
+	for i := range v.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		fpath := joinPath(path, field.Name)
+
+		provider, isProvider := asMapProvider(fv)
+		if isProvider {
+			for _, tag := range tags {
+				mp := provider.TagOpsMap(tag)
+				if m.flattens(field, tag) {
+					for key, val := range mp {
+						out[tag].add(key, &entry{depth: 1, cands: []candidate{{val: val, path: fpath}}})
+					}
+					continue
+				}
+				key, err := m.tagName(field, fv, tag, m.Omitempty)
+				if errors.Is(err, ErrSkip) {
+					continue
+				}
+				if val, ok := m.encryptField(st, field, key, mp, fpath); ok {
+					out[tag].add(key, newEntry(val, isTagged(field, tag), fpath))
+				}
+			}
+			continue
+		}
+		if isNested(field.Type) {
+			// the field may be flattened for some of the tags only, i.e.
+			// an anonymous field with a name in one of them.
+			var flat, named []string
+			for _, tag := range tags {
+				if m.flattens(field, tag) {
+					flat = append(flat, tag)
+				} else {
+					named = append(named, tag)
+				}
+			}
+			if len(flat) > 0 {
+				for tag, es := range m.walkMulti(st, fv, flat, fpath) {
+					out[tag].merge(es)
+				}
+			}
+			if len(named) == 0 {
+				continue
+			}
+			nested := m.walkMulti(st, fv, named, fpath)
+			for _, tag := range named {
+				key, err := m.tagName(field, fv, tag, m.Omitempty)
+				if errors.Is(err, ErrSkip) {
+					continue
+				}
+				if val, ok := m.encryptField(st, field, key, nested[tag].materialize(), fpath); ok {
+					out[tag].add(key, newEntry(val, isTagged(field, tag), fpath))
+				}
+			}
+			continue
+		}
+
+		// values are converted once for each distinct set of tag
+		// options, usually once per field.
+		values := make(map[string]any, 1)
+		for _, tag := range tags {
+			key, err := m.tagName(field, fv, tag, m.Omitempty)
+			if errors.Is(err, ErrSkip) {
+				continue
+			}
+			opts := tagOptions(field, tag)
+			optKey := strings.Join(opts, tagsep)
+			val, ok := values[optKey]
+			if !ok {
+				val, err = m.value(st.ctx, fv, opts)
+				if errors.Is(err, ErrSkip) || errors.Is(err, ErrUnsupportedKind) {
+					continue
+				}
+				if err != nil {
+					st.fail(fpath, err)
+				}
+				values[optKey] = val
+			}
+			if val, ok := m.encryptField(st, field, key, val, fpath); ok {
+				out[tag].add(key, newEntry(val, isTagged(field, tag), fpath))
+			}
+		}
+	}
+	return out
+}
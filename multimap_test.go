@@ -0,0 +1,63 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMultiMap(t *testing.T) {
+	type (
+		Address struct {
+			City string `json:"city" db:"city_name"`
+		}
+		Base struct {
+			ID int `json:"id" db:"id"`
+		}
+		Person struct {
+			Base
+			Name     string  `json:"name" db:"full_name" yaml:"name"`
+			Password string  `json:"-" db:"password"`
+			Note     string  `json:"note,omitempty" db:"note"`
+			Address  Address `json:"address" db:"address"`
+		}
+	)
+	p := Person{
+		Base:     Base{ID: 1},
+		Name:     "John",
+		Password: "qwerty",
+		Address:  Address{City: "Anytown"},
+	}
+
+	t.Run("package level", func(t *testing.T) {
+		got := ToMultiMap(p, "json", "db")
+		want := map[string]map[string]any{
+			"json": {
+				"id":      1,
+				"name":    "John",
+				"note":    "",
+				"address": map[string]any{"city": "Anytown"},
+			},
+			"db": {
+				"id":        1,
+				"full_name": "John",
+				"password":  "qwerty",
+				"note":      "",
+				"address":   map[string]any{"city_name": "Anytown"},
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+	t.Run("matches ToMap", func(t *testing.T) {
+		for _, m := range []Mapper{New(), New(Omitempty(), Flatten())} {
+			got := m.ToMultiMap(&p, "json", "db", "yaml")
+			for _, tag := range []string{"json", "db", "yaml"} {
+				want := m.With(Tag(tag)).ToMap(p)
+				assert.Equal(t, want, got[tag], tag)
+			}
+		}
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		assert.Nil(t, ToMultiMap(42, "json"))
+	})
+}
@@ -0,0 +1,62 @@
+package tagops
+
+import (
+	"strings"
+	"unicode"
+)
+
+// initialisms are the common initialisms, that DBName keeps as words in the
+// plural form, i.e. "UserIDs" is "user_ids".
+var initialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true,
+	"EOF": true, "GUID": true, "HTML": true, "HTTP": true, "HTTPS": true,
+	"ID": true, "IP": true, "JSON": true, "QPS": true, "RAM": true,
+	"RPC": true, "SLA": true, "SMTP": true, "SQL": true, "SSH": true,
+	"TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "UUID": true, "URI": true, "URL": true, "VM": true,
+	"XML": true, "XSRF": true, "XSS": true,
+}
+
+// DBName returns the database column name for the Go field name, following
+// the conventions of GORM and sqlboiler: snake case, with the acronyms kept
+// as words, i.e. "ID" is "id", "UserIDs" is "user_ids", "HTTPServer" is
+// "http_server", and "Address2Line" is "address2_line".  Use it with
+// KeyFunc, to map the fields without the tag to the existing database
+// schema:
+//
+//	m := tagops.New(tagops.Tag("db"), tagops.KeyFunc(tagops.DBName))
+func DBName(name string) string {
+	rs := []rune(name)
+	var ws []string
+	for i := 0; i < len(rs); {
+		if rs[i] == '_' || rs[i] == '-' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(rs) && unicode.IsUpper(rs[j]) {
+			j++
+		}
+		if j-i > 1 && j < len(rs) && unicode.IsLower(rs[j]) {
+			if rs[j] == 's' && (j+1 == len(rs) || !unicode.IsLower(rs[j+1])) && initialisms[string(rs[i:j])] {
+				// the plural of the initialism, i.e. "IDs".
+				j++
+			} else {
+				// the last capital starts the next word, i.e.
+				// "HTTPServer".
+				j--
+			}
+		} else {
+			for j < len(rs) && (unicode.IsLower(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+		}
+		if j == i {
+			// not a letter or digit.
+			j++
+		}
+		ws = append(ws, strings.ToLower(string(rs[i:j])))
+		i = j
+	}
+	return strings.Join(ws, "_")
+}
@@ -0,0 +1,45 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"ID", "id"},
+		{"UserID", "user_id"},
+		{"UserIDs", "user_ids"},
+		{"IDsCount", "ids_count"},
+		{"ABCs", "ab_cs"},
+		{"HTTPServer", "http_server"},
+		{"APIKey", "api_key"},
+		{"HTTPSProxyURL", "https_proxy_url"},
+		{"OAuthToken", "o_auth_token"},
+		{"Address2Line", "address2_line"},
+		{"first_name", "first_name"},
+		{"CreatedAt", "created_at"},
+		{"X", "x"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DBName(tt.in), tt.in)
+	}
+}
+
+func TestKeyFunc_DBName(t *testing.T) {
+	type Order struct {
+		OrderID    int64
+		CustomerID int64  `db:"customer"`
+		ShipToURL  string `db:",omitempty"`
+	}
+	m := New(Tag("db"), KeyFunc(DBName))
+	assert.Equal(t, map[string]any{"order_id": int64(1), "customer": int64(2), "ship_to_url": "x"}, m.ToMap(Order{1, 2, "x"}))
+
+	spec, err := NewFromSpec(MapperSpec{Tag: "db", KeyFunc: "db"})
+	assert.NoError(t, err)
+	assert.Equal(t, m.ToMap(Order{1, 2, "x"}), spec.ToMap(Order{1, 2, "x"}))
+}
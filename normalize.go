@@ -0,0 +1,59 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// normTag is the tag with the list of normalizers.
+const normTag = "norm"
+
+// normalizers holds the registered normalizers, keyed by name.
+var normalizers sync.Map // map[string]func(string) string
+
+func init() {
+	RegisterNormalizer("trim", strings.TrimSpace)
+	RegisterNormalizer("lower", strings.ToLower)
+	RegisterNormalizer("upper", strings.ToUpper)
+	RegisterNormalizer("collapse", func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	})
+}
+
+// RegisterNormalizer registers the string normalizer fn with the name, that
+// can be used in the "norm" tag.  FromMap applies the normalizers listed in
+// the tag to the string values of the field, in order, before assigning
+// them, i.e. `norm:"trim,lower"` for e-mail addresses.  Built-in normalizers
+// are:
+//
+//   - trim: removes leading and trailing white space;
+//   - lower, upper: change the case;
+//   - collapse: replaces runs of white space with a single space, and trims.
+//
+// Registering a normalizer with the name of an existing one replaces it.
+func RegisterNormalizer(name string, fn func(string) string) {
+	if name == "" || fn == nil {
+		panic("tagops: RegisterNormalizer: empty name or nil function")
+	}
+	normalizers.Store(name, fn)
+}
+
+// normalize applies the normalizers from the "norm" tag of the field to the
+// string value sv.  Other values are returned as is.
+func normalize(field reflect.StructField, sv any) (any, error) {
+	s, ok := sv.(string)
+	tag := field.Tag.Get(normTag)
+	if !ok || tag == "" {
+		return sv, nil
+	}
+	for _, name := range strings.Split(tag, tagsep) {
+		fn, ok := normalizers.Load(strings.TrimSpace(name))
+		if !ok {
+			return sv, fmt.Errorf("unknown normalizer %q", name)
+		}
+		s = fn.(func(string) string)(s)
+	}
+	return s, nil
+}
@@ -0,0 +1,43 @@
+package tagops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	RegisterNormalizer("nodash", func(s string) string { return strings.ReplaceAll(s, "-", "") })
+	type Signup struct {
+		Email string `json:"email" norm:"trim,lower"`
+		Name  string `json:"name" norm:"collapse"`
+		Code  string `json:"code" norm:"upper, nodash"`
+		Age   int    `json:"age" norm:"trim"`
+		Raw   string `json:"raw"`
+	}
+	var got Signup
+	err := New(ParseStrings()).FromMap(map[string]any{
+		"email": "  John@Example.COM ",
+		"name":  " John \t  Smith ",
+		"code":  "ab-12-cd",
+		"age":   " 42 ",
+		"raw":   " as is ",
+	}, &got)
+	require.NoError(t, err)
+	assert.Equal(t, Signup{Email: "john@example.com", Name: "John Smith", Code: "AB12CD", Age: 42, Raw: " as is "}, got)
+
+	t.Run("unknown normalizer", func(t *testing.T) {
+		type S struct {
+			Name string `json:"name" norm:"nope"`
+		}
+		var s S
+		err := New().FromMap(map[string]any{"name": "x"}, &s)
+		assert.EqualError(t, err, `Name: unknown normalizer "nope"`)
+	})
+	t.Run("invalid registration", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterNormalizer("", strings.ToLower) })
+		assert.Panics(t, func() { RegisterNormalizer("x", nil) })
+	})
+}
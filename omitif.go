@@ -0,0 +1,78 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// omitIfTag is the tag with the condition on the sibling field, that omits
+// the field from ToMap, i.e. `omitif:"status==draft"`.
+const omitIfTag = "omitif"
+
+// ErrInvalidCondition is returned when the omitif condition can not be
+// parsed or evaluated.
+var ErrInvalidCondition = errors.New("invalid omitif condition")
+
+// omitIf returns true if the field of the struct value v should be omitted
+// according to its omitif condition.
+//
+// The condition is "key==value" or "key!=value", where key is the key of the
+// sibling field in the same struct, and value is parsed into the type of the
+// sibling field, as in ParseStrings, and compared to its value.  The value may
+// be quoted with single or double quotes, and "nil" is the nil pointer, slice
+// or map, i.e.
+//
+//	Discount  float64 `json:"discount" omitif:"kind!=sale"`
+//	ShippedAt *time.Time `json:"shipped_at" omitif:"status=='pending'"`
+//	Items     []Item  `json:"items" omitif:"items==nil"`
+func (m Mapper) omitIf(v reflect.Value, field reflect.StructField) (bool, error) {
+	cond, ok := field.Tag.Lookup(omitIfTag)
+	if !ok {
+		return false, nil
+	}
+	key, op, lit, err := parseCondition(cond)
+	if err != nil {
+		return false, err
+	}
+	sv, ok := m.fieldByKey(v, key)
+	if !ok {
+		return false, fmt.Errorf("%w: %q: %w: %q", ErrInvalidCondition, cond, ErrFieldNotFound, key)
+	}
+	want := reflect.New(sv.Type()).Elem()
+	if !(lit == "nil" && isNillable(sv.Kind())) {
+		if err := recordMapper.assign(context.Background(), want, lit, nil); err != nil {
+			return false, fmt.Errorf("%w: %q: %w", ErrInvalidCondition, cond, err)
+		}
+	}
+	eq := reflect.DeepEqual(sv.Interface(), want.Interface())
+	return eq == (op == "=="), nil
+}
+
+// parseCondition splits the omitif condition into the key, the operator and
+// the unquoted literal.
+func parseCondition(cond string) (key, op, lit string, err error) {
+	for _, op = range []string{"==", "!="} {
+		if k, l, ok := strings.Cut(cond, op); ok {
+			key, lit = strings.TrimSpace(k), strings.TrimSpace(l)
+			break
+		}
+	}
+	if key == "" {
+		return "", "", "", fmt.Errorf("%w: %q: expected key==value or key!=value", ErrInvalidCondition, cond)
+	}
+	if len(lit) >= 2 && (lit[0] == '\'' || lit[0] == '"') && lit[len(lit)-1] == lit[0] {
+		lit = lit[1 : len(lit)-1]
+	}
+	return key, op, lit, nil
+}
+
+func isNillable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
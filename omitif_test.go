@@ -0,0 +1,70 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type omitIfOrder struct {
+	Status   string   `json:"status"`
+	Kind     string   `json:"kind"`
+	Total    int      `json:"total"`
+	Discount float64  `json:"discount" omitif:"kind != sale"`
+	Tracking string   `json:"tracking" omitif:"status=='pending'"`
+	Refund   *int     `json:"refund" omitif:"refund==nil"`
+	Items    []string `json:"items" omitif:"total==0"`
+}
+
+func TestOmitIf(t *testing.T) {
+	refund := 5
+	tests := []struct {
+		name  string
+		order omitIfOrder
+		want  []string
+	}{
+		{
+			name:  "all omitted",
+			order: omitIfOrder{Status: "pending", Kind: "regular"},
+			want:  []string{"kind", "status", "total"},
+		},
+		{
+			name:  "none omitted",
+			order: omitIfOrder{Status: "shipped", Kind: "sale", Total: 1, Refund: &refund},
+			want:  []string{"discount", "items", "kind", "refund", "status", "total", "tracking"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New().ToMapE(tt.order)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Keys(got))
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name string
+			v    any
+			want string
+		}{
+			{"syntax", struct {
+				A int `json:"a" omitif:"b"`
+			}{}, `A: invalid omitif condition: "b": expected key==value or key!=value`},
+			{"unknown key", struct {
+				A int `json:"a" omitif:"b==1"`
+			}{}, `A: invalid omitif condition: "b==1": field not found: "b"`},
+			{"bad value", struct {
+				A int `json:"a" omitif:"a==x"`
+			}{}, `A: invalid omitif condition: "a==x": strconv.ParseInt: parsing "x": invalid syntax`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := New().ToMapE(tt.v)
+				assert.ErrorIs(t, err, ErrInvalidCondition)
+				assert.EqualError(t, err, tt.want)
+				assert.Contains(t, got, "a", "the field is kept")
+			})
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package tagops
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// presence is the state of the Optional value.
+type presence uint8
+
+const (
+	absent presence = iota
+	null
+	set
+)
+
+// Optional is a value that records, whether the key was absent, null, or set
+// in the source map, so that PATCH handlers can distinguish "not changed"
+// from "cleared".  The zero value is absent.
+//
+// FromMap leaves the Optional field absent, if the key is not in the map,
+// marks it null if the map value is nil, and sets it otherwise.  ToMap omits
+// the absent fields, and emits nil for the null ones.  Optional also
+// implements json.Unmarshaler with the same semantics, and json.Marshaler,
+// that encodes absent values as null.
+type Optional[T any] struct {
+	value T
+	state presence
+}
+
+// Some returns the Optional that is set to v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, state: set}
+}
+
+// Null returns the Optional that is null.
+func Null[T any]() Optional[T] {
+	return Optional[T]{state: null}
+}
+
+// IsAbsent returns true if the value was not present.
+func (o Optional[T]) IsAbsent() bool { return o.state == absent }
+
+// IsNull returns true if the value was present, and was null.
+func (o Optional[T]) IsNull() bool { return o.state == null }
+
+// IsSet returns true if the value was present, and was not null.
+func (o Optional[T]) IsSet() bool { return o.state == set }
+
+// Get returns the value and true, if it is set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.state == set
+}
+
+// GetOr returns the value, if it is set, or dflt otherwise.
+func (o Optional[T]) GetOr(dflt T) T {
+	if o.state != set {
+		return dflt
+	}
+	return o.value
+}
+
+// MarshalJSON implements json.Marshaler.  Absent and null values are encoded
+// as null.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.state != set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = Null[T]()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Some(v)
+	return nil
+}
+
+// IsZero returns true if the value is absent, for the omitzero json option.
+func (o Optional[T]) IsZero() bool { return o.state == absent }
+
+// optional is implemented by Optional.
+type optional interface {
+	optionalState() presence
+	// optionalValue returns the value.
+	optionalValue() reflect.Value
+}
+
+// optionalSetter is implemented by *Optional.
+type optionalSetter interface {
+	optional
+	// optionalElem returns the addressable value, and marks the Optional
+	// state as st.
+	optionalElem(st presence) reflect.Value
+}
+
+func (o Optional[T]) optionalState() presence { return o.state }
+
+func (o Optional[T]) optionalValue() reflect.Value { return reflect.ValueOf(&o.value).Elem() }
+
+func (o *Optional[T]) optionalElem(st presence) reflect.Value {
+	o.state = st
+	var zero T
+	o.value = zero
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+var optionalType = reflect.TypeOf((*optional)(nil)).Elem()
+
+// isOptional returns true if t is an Optional.
+func isOptional(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Implements(optionalType)
+}
+
+// optionalOut returns the map value of the Optional o.  It returns false if
+// the value is absent, and should be omitted.
+func (m Mapper) optionalOut(st *state, o optional, opts []string, path string) (any, bool) {
+	switch o.optionalState() {
+	case absent:
+		return nil, false
+	case null:
+		return nil, true
+	}
+	fv := o.optionalValue()
+	if isNested(fv.Type()) {
+		return m.toMap(st, fv, path), true
+	}
+	return m.leaf(st, fv, opts, path)
+}
+
+// optionalIn populates the Optional field fv from the map value sv.  It
+// returns false if the conversion should stop.
+func (m Mapper) optionalIn(st *state, fv reflect.Value, sv any, opts []string, path string) bool {
+	o := fv.Addr().Interface().(optionalSetter)
+	if sv == nil {
+		o.optionalElem(null)
+		return true
+	}
+	elem := o.optionalElem(set)
+	if nested, ok := sv.(map[string]any); ok && isNested(elem.Type()) {
+		return m.fromMap(st, nested, elem, path)
+	}
+	if err := m.assign(st.ctx, elem, sv, opts); err != nil {
+		o.optionalElem(absent)
+		st.fail(path, err)
+		return m.collectErrors
+	}
+	return true
+}
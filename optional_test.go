@@ -0,0 +1,91 @@
+package tagops
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type optAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type optPatch struct {
+	Name    Optional[string]     `json:"name"`
+	Age     Optional[int]        `json:"age"`
+	Email   Optional[string]     `json:"email"`
+	Address Optional[optAddress] `json:"address"`
+}
+
+func TestOptional_FromMap(t *testing.T) {
+	src := map[string]any{
+		"name":    "Bob",
+		"age":     nil,
+		"address": map[string]any{"city": "Paris"},
+	}
+	var p optPatch
+	require.NoError(t, New().FromMap(src, &p))
+
+	assert.True(t, p.Name.IsSet())
+	name, ok := p.Name.Get()
+	assert.True(t, ok)
+	assert.Equal(t, "Bob", name)
+
+	assert.True(t, p.Age.IsNull())
+	assert.Equal(t, 18, p.Age.GetOr(18))
+
+	assert.True(t, p.Email.IsAbsent())
+	_, ok = p.Email.Get()
+	assert.False(t, ok)
+
+	assert.Equal(t, optAddress{City: "Paris"}, p.Address.GetOr(optAddress{}))
+
+	t.Run("Flatten does not flatten", func(t *testing.T) {
+		var p optPatch
+		require.NoError(t, New(Flatten()).FromMap(map[string]any{"address": map[string]any{"zip": "1"}}, &p))
+		assert.Equal(t, Some(optAddress{Zip: "1"}), p.Address)
+	})
+	t.Run("error", func(t *testing.T) {
+		var p optPatch
+		err := New().FromMap(map[string]any{"age": "x"}, &p)
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "Age", fe.Path)
+		assert.True(t, p.Age.IsAbsent())
+	})
+}
+
+func TestOptional_ToMap(t *testing.T) {
+	p := optPatch{
+		Name:    Some("Bob"),
+		Age:     Null[int](),
+		Address: Some(optAddress{City: "Paris"}),
+	}
+	got, err := New().ToMapE(p)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name":    "Bob",
+		"age":     nil,
+		"address": map[string]any{"city": "Paris", "zip": ""},
+	}, got)
+
+	var back optPatch
+	require.NoError(t, New().FromMap(got, &back))
+	assert.Equal(t, p, back)
+}
+
+func TestOptional_JSON(t *testing.T) {
+	var p optPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"Bob","age":null}`), &p))
+	assert.Equal(t, optPatch{Name: Some("Bob"), Age: Null[int]()}, p)
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Bob","age":null,"email":null,"address":null}`, string(data))
+
+	assert.True(t, Optional[int]{}.IsZero())
+	assert.False(t, Null[int]().IsZero())
+}
@@ -0,0 +1,93 @@
+package tagops
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// ParseStrings returns an Option that makes FromMap parse string map values
+// into fields of other types, i.e. "42" into int, "true" into bool, or
+// "1h30m" into time.Duration.  Byte slices are decoded from base64, and
+// slices, arrays, maps and structs from JSON.  It is useful for sources where
+// all values are strings, such as environment variables, key/value stores or
+// form values.
+func ParseStrings() Option {
+	return func(m *Mapper) {
+		m.parseStrings = true
+	}
+}
+
+// parseString parses the string s into fv.  It returns false if fv is a
+// string.
+func parseString(fv reflect.Value, s string) (bool, error) {
+	switch fv.Type() {
+	case timeType:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err == nil {
+			fv.Set(reflect.ValueOf(t))
+		}
+		return true, err
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err == nil {
+			fv.SetInt(int64(d))
+		}
+		return true, err
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return false, nil
+	case reflect.Ptr:
+		ptr := reflect.New(fv.Type().Elem())
+		ok, err := parseString(ptr.Elem(), s)
+		if ok && err == nil {
+			fv.Set(ptr)
+		}
+		return ok, err
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err == nil {
+			fv.SetBool(b)
+		}
+		return true, err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err == nil {
+			fv.SetInt(n)
+		}
+		return true, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err == nil {
+			fv.SetUint(n)
+		}
+		return true, err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err == nil {
+			fv.SetFloat(f)
+		}
+		return true, err
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(s, fv.Type().Bits())
+		if err == nil {
+			fv.SetComplex(c)
+		}
+		return true, err
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err == nil {
+				fv.SetBytes(b)
+			}
+			return true, err
+		}
+		return true, json.Unmarshal([]byte(s), fv.Addr().Interface())
+	case reflect.Array, reflect.Map, reflect.Struct:
+		return true, json.Unmarshal([]byte(s), fv.Addr().Interface())
+	}
+	return false, nil
+}
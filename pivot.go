@@ -0,0 +1,89 @@
+package tagops
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Pivot reshapes the rows from the long to the wide form.  The rows that have
+// the same values in all columns except keyCol and valCol are merged into one
+// row, that has a column named after each keyCol value, with the valCol value,
+// i.e. the rows
+//
+//	{"id": 1, "attr": "color", "value": "red"}
+//	{"id": 1, "attr": "size", "value": 42}
+//
+// pivoted by "attr" and "value" become
+//
+//	{"id": 1, "color": "red", "size": 42}
+//
+// The keyCol values that are not strings are formatted with fmt.Sprint.  If
+// several rows have the same key, the last value wins.  The rows are returned
+// in the order of the first appearance.  It returns an error if a row does not
+// have keyCol or valCol.  The input rows are not modified.
+func Pivot(rows []map[string]any, keyCol, valCol string) ([]map[string]any, error) {
+	var out []map[string]any
+	groups := make(map[string]map[string]any)
+	for i, row := range rows {
+		key, ok := row[keyCol]
+		if !ok {
+			return nil, fmt.Errorf("row %d: %w: %q", i, ErrFieldNotFound, keyCol)
+		}
+		val, ok := row[valCol]
+		if !ok {
+			return nil, fmt.Errorf("row %d: %w: %q", i, ErrFieldNotFound, valCol)
+		}
+		ident := maps.Clone(row)
+		delete(ident, keyCol)
+		delete(ident, valCol)
+		id := groupID(ident)
+		grp, ok := groups[id]
+		if !ok {
+			grp = ident
+			groups[id] = grp
+			out = append(out, grp)
+		}
+		name, ok := key.(string)
+		if !ok {
+			name = fmt.Sprint(key)
+		}
+		grp[name] = val
+	}
+	return out, nil
+}
+
+// groupID returns the string that identifies the values of the map mp.
+func groupID(mp map[string]any) string {
+	var id []byte
+	for _, k := range Keys(mp) {
+		id = fmt.Appendf(id, "%q=%#v;", k, mp[k])
+	}
+	return string(id)
+}
+
+// Unpivot reshapes the rows from the wide to the long form, it is the reverse
+// of Pivot.  Each column of the row, except the idCols, becomes a separate row
+// with the idCols, the column name in keyCol, and the column value in valCol.
+// The columns are unpivoted in the alphabetical order.  The id columns that
+// are missing in a row are missing in the rows produced from it.
+func Unpivot(rows []map[string]any, keyCol, valCol string, idCols ...string) []map[string]any {
+	var out []map[string]any
+	for _, row := range rows {
+		ident := make(map[string]any, len(idCols))
+		for _, col := range idCols {
+			if v, ok := row[col]; ok {
+				ident[col] = v
+			}
+		}
+		for _, col := range Keys(row) {
+			if _, ok := ident[col]; ok {
+				continue
+			}
+			r := maps.Clone(ident)
+			r[keyCol] = col
+			r[valCol] = row[col]
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPivot(t *testing.T) {
+	long := []map[string]any{
+		{"id": 1, "attr": "color", "value": "red"},
+		{"id": 2, "attr": "color", "value": "blue"},
+		{"id": 1, "attr": "size", "value": 42},
+		{"id": 2, "attr": 7, "value": true},
+	}
+	got, err := Pivot(long, "attr", "value")
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"id": 1, "color": "red", "size": 42},
+		{"id": 2, "color": "blue", "7": true},
+	}, got)
+	assert.Len(t, long[0], 3, "input rows are not modified")
+
+	t.Run("types are distinguished", func(t *testing.T) {
+		got, err := Pivot([]map[string]any{
+			{"id": 1, "k": "a", "v": 1},
+			{"id": "1", "k": "a", "v": 2},
+		}, "k", "v")
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+	})
+	t.Run("missing columns", func(t *testing.T) {
+		_, err := Pivot([]map[string]any{{"id": 1, "value": 1}}, "attr", "value")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+		_, err = Pivot([]map[string]any{{"id": 1, "attr": "a"}}, "attr", "value")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+	})
+}
+
+func TestUnpivot(t *testing.T) {
+	wide := []map[string]any{
+		{"id": 1, "color": "red", "size": 42},
+		{"id": 2, "color": "blue"},
+	}
+	want := []map[string]any{
+		{"id": 1, "attr": "color", "value": "red"},
+		{"id": 1, "attr": "size", "value": 42},
+		{"id": 2, "attr": "color", "value": "blue"},
+	}
+	got := Unpivot(wide, "attr", "value", "id")
+	assert.Equal(t, want, got)
+
+	back, err := Pivot(got, "attr", "value")
+	assert.NoError(t, err)
+	assert.Equal(t, wide, back)
+
+	assert.Nil(t, Unpivot(nil, "attr", "value"))
+}
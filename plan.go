@@ -0,0 +1,194 @@
+package tagops
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// structPlan is the description of the fields of the struct type with the
+// parsed tags, so that the tags are parsed once per type, and not on every
+// conversion.  Plans are cached, see SetCacheLimit.
+type structPlan struct {
+	fields []fieldPlan
+	// pinned is true if any field of the struct, or of the nested structs,
+	// has the column position, see Mapper.columnPins.
+	pinned bool
+}
+
+// fieldPlan is the struct field with the parsed tag.
+type fieldPlan struct {
+	field reflect.StructField
+	// tag is the tag that applies to the field, see Mapper.tagFor.
+	tag string
+	// name is the name in the tag, it is empty if the field is untagged.
+	name string
+	// opts are the tag options.
+	opts []string
+	// fast is true if the field is of the predeclared scalar type, and has
+	// no tag options that affect the conversion, see Mapper.fastValue.
+	fast bool
+}
+
+// fastOptions are the tag options that don't affect the conversion of the
+// leaf value.
+var fastOptions = []string{fOmitEmpty, fRequired, fReadOnly, fWriteOnly}
+
+// newFieldPlan parses the tag of the field.
+func newFieldPlan(field reflect.StructField, tag string) fieldPlan {
+	name, rest, ok := strings.Cut(tagValue(field, tag), tagsep)
+	var opts []string
+	if ok {
+		opts = slices.Clip(strings.Split(rest, tagsep))
+	}
+	return fieldPlan{field: field, tag: tag, name: name, opts: opts, fast: isFast(field.Type, opts)}
+}
+
+// isFast returns true if the values of the type t with the tag options opts
+// can be converted by Mapper.fastValue.  The predeclared types have no
+// ToMap converters, see RegisterConverter.
+func isFast(t reflect.Type, opts []string) bool {
+	if t == nil || !isPredeclared(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		return false
+	}
+	for _, opt := range opts {
+		if !slices.Contains(fastOptions, opt) {
+			return false
+		}
+	}
+	return true
+}
+
+// fastValue returns the map value of the leaf field fp with the value fv,
+// extracted by kind, without going through the converters and formatters,
+// and without reflect.Value.Interface, that allocates the copy of the value
+// of the addressable field.  It returns false if the field needs the full
+// conversion, see Mapper.leaf.
+func (m Mapper) fastValue(fp *fieldPlan, fv reflect.Value) (any, bool) {
+	if !fp.fast || m.encoderSafe {
+		return nil, false
+	}
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := fv.Float(); m.floatPrec != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, false
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if m.enumStrings {
+			return nil, false
+		}
+	}
+	if !fv.CanAddr() {
+		// the value of the non-addressable field is not copied.
+		return fv.Interface(), true
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), true
+	case reflect.Bool:
+		return fv.Bool(), true
+	case reflect.Float64:
+		return fv.Float(), true
+	case reflect.Float32:
+		return float32(fv.Float()), true
+	case reflect.Int:
+		return int(fv.Int()), true
+	case reflect.Int8:
+		return int8(fv.Int()), true
+	case reflect.Int16:
+		return int16(fv.Int()), true
+	case reflect.Int32:
+		return int32(fv.Int()), true
+	case reflect.Int64:
+		return fv.Int(), true
+	case reflect.Uint:
+		return uint(fv.Uint()), true
+	case reflect.Uint8:
+		return uint8(fv.Uint()), true
+	case reflect.Uint16:
+		return uint16(fv.Uint()), true
+	case reflect.Uint32:
+		return uint32(fv.Uint()), true
+	case reflect.Uint64:
+		return fv.Uint(), true
+	}
+	return nil, false
+}
+
+// key returns the key of the field with the value val, or an ErrSkip error,
+// if the field should be skipped.  See tagName.  It returns an
+// ErrInvalidFieldName error, if the field name is empty or malformed.
+func (fp *fieldPlan) key(val reflect.Value, omitempty bool) (string, error) {
+	if r, _ := utf8.DecodeRuneInString(fp.field.Name); r == utf8.RuneError {
+		return "", fmt.Errorf("%w: %q", ErrInvalidFieldName, fp.field.Name)
+	}
+	if !isExported(fp.field.Name) || fp.name == "-" {
+		return "", ErrSkip
+	}
+	if omitempty && fp.has(fOmitEmpty) && isEmpty(val) {
+		return "", ErrSkip
+	}
+	if fp.name == "" {
+		return fp.field.Name, nil
+	}
+	return fp.name, nil
+}
+
+// has returns true if the field tag has the option opt.
+func (fp *fieldPlan) has(opt string) bool {
+	return slices.Contains(fp.opts, opt)
+}
+
+// tagged returns true if the field has a name in the tag, see isTagged.
+func (fp *fieldPlan) tagged() bool {
+	return fp.name != "" && fp.name != "-"
+}
+
+// plan returns the plan of the struct type typ for the Mapper tags.
+func (m Mapper) plan(typ reflect.Type) *structPlan {
+	id := planID{typ: typ, tag: m.Tag, version: m.version}
+	p, hit := plans.get(id, func() *structPlan {
+		p := &structPlan{fields: make([]fieldPlan, typ.NumField())}
+		for i := range p.fields {
+			field := typ.Field(i)
+			p.fields[i] = newFieldPlan(field, m.tagFor(field))
+			if _, ok := colPosition(field); ok || isNested(field.Type) && m.plan(field.Type).pinned {
+				p.pinned = true
+			}
+		}
+		return p
+	})
+	if m.metrics != nil {
+		m.metrics.planLookup(hit)
+	}
+	return p
+}
+
+// fieldKey is like Mapper.tagName for the field plan fp.
+func (m Mapper) fieldKey(fp *fieldPlan, val reflect.Value, omitempty bool) (string, error) {
+	name, err := fp.key(val, omitempty)
+	if err != nil {
+		return name, err
+	}
+	if fp.name == "" {
+		switch {
+		case m.untagged == SkipUntagged:
+			return "", ErrSkip
+		case m.untagged == UseKeyFunc && m.keyFunc != nil:
+			name = m.keyFunc(name)
+		}
+	}
+	return name, nil
+}
@@ -0,0 +1,73 @@
+package tagops
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type planKinds struct {
+	B   bool    `json:"b"`
+	S   string  `json:"s"`
+	I   int     `json:"i"`
+	I8  int8    `json:"i8"`
+	I16 int16   `json:"i16"`
+	I32 int32   `json:"i32"`
+	I64 int64   `json:"i64"`
+	U   uint    `json:"u"`
+	U8  uint8   `json:"u8"`
+	U16 uint16  `json:"u16"`
+	U32 uint32  `json:"u32"`
+	U64 uint64  `json:"u64"`
+	F32 float32 `json:"f32"`
+	F64 float64 `json:"f64,omitempty"`
+}
+
+func TestMapper_fastValue(t *testing.T) {
+	v := planKinds{true, "x", -1, -8, -16, -32, -64, 1, 8, 16, 32, 64, 1.5, 2.5}
+	want := map[string]any{
+		"b": true, "s": "x",
+		"i": -1, "i8": int8(-8), "i16": int16(-16), "i32": int32(-32), "i64": int64(-64),
+		"u": uint(1), "u8": uint8(8), "u16": uint16(16), "u32": uint32(32), "u64": uint64(64),
+		"f32": float32(1.5), "f64": 2.5,
+	}
+	m := New()
+	assert.Equal(t, want, m.ToMap(v), "value")
+	assert.Equal(t, want, m.ToMap(&v), "pointer")
+
+	t.Run("non-finite floats are reported", func(t *testing.T) {
+		v := planKinds{F64: math.Inf(1)}
+		_, err := m.ToMapE(&v)
+		assert.ErrorIs(t, err, ErrNonFinite)
+	})
+	t.Run("formatters apply", func(t *testing.T) {
+		v := planKinds{F64: 1.23456}
+		mp, err := New(FloatPrecision(2)).ToMapE(&v)
+		require.NoError(t, err)
+		assert.Equal(t, 1.23, mp["f64"])
+	})
+}
+
+func TestIsFast(t *testing.T) {
+	type Named int
+	var tests = []struct {
+		name string
+		t    any
+		opts []string
+		want bool
+	}{
+		{"int", 0, nil, true},
+		{"omitempty", "", []string{fOmitEmpty}, true},
+		{"named type", Named(0), nil, false},
+		{"format option", 0, []string{fEnum}, false},
+		{"slice", []int{}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isFast(reflect.TypeOf(tt.t), tt.opts))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package tagops
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// profiles holds the registered option profiles.
+var profiles sync.Map // map[reflect.Type][]Option
+
+// RegisterProfile registers the options opts, that are applied on top of the
+// Mapper configuration to the fields of the struct type t, wherever it is
+// mapped, i.e. KeyFunc or FormatTime for the shared domain types, so that the
+// policy is defined in one place, and not at every Mapper.  The options are
+// also applied to the structs nested in t, and if the nested type has its
+// own profile, it is applied on top.  Registering the profile for the same
+// type again replaces it, and registering no options removes it.
+//
+// The profiles apply to the fields only, so the options that apply to the
+// root map, such as Checksum, Prefix and WithComputed, have no effect.
+//
+// It panics if t is not a struct type or a pointer to struct.
+func RegisterProfile(t reflect.Type, opts ...Option) {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("tagops: RegisterProfile: not a struct type")
+	}
+	if len(opts) == 0 {
+		profiles.Delete(t)
+		return
+	}
+	profiles.Store(t, slices.Clone(opts))
+}
+
+// withProfile returns the Mapper with the profile of the struct type t
+// applied, if registered.
+func (m Mapper) withProfile(t reflect.Type) Mapper {
+	if opts, ok := profiles.Load(t); ok {
+		return m.With(opts.([]Option)...)
+	}
+	return m
+}
@@ -0,0 +1,74 @@
+package tagops
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type profileMoney struct {
+	Amount   int
+	Currency string
+}
+
+type profileEvent struct {
+	At time.Time `json:"at"`
+}
+
+type profileOrder struct {
+	ID    int          `json:"id"`
+	Total profileMoney `json:"total"`
+	Event profileEvent `json:"event"`
+}
+
+func TestRegisterProfile(t *testing.T) {
+	RegisterProfile(reflect.TypeOf(profileMoney{}), KeyFunc(strings.ToLower))
+	RegisterProfile(reflect.TypeOf(&profileEvent{}), FormatTime(TimeUnix))
+	t.Cleanup(func() {
+		RegisterProfile(reflect.TypeOf(profileMoney{}))
+		RegisterProfile(reflect.TypeOf(profileEvent{}))
+	})
+
+	o := profileOrder{ID: 1, Total: profileMoney{Amount: 100, Currency: "EUR"}, Event: profileEvent{At: time.Unix(1700000000, 0).UTC()}}
+	want := map[string]any{
+		"id":    1,
+		"total": map[string]any{"amount": 100, "currency": "EUR"},
+		"event": map[string]any{"at": int64(1700000000)},
+	}
+	for _, m := range []Mapper{New(), New(Tag("db"))} {
+		got, err := m.ToMapE(o)
+		require.NoError(t, err)
+		if m.Tag == "db" {
+			assert.Equal(t, map[string]any{"amount": 100, "currency": "EUR"}, got["Total"])
+			continue
+		}
+		assert.Equal(t, want, got)
+	}
+
+	t.Run("FromMap", func(t *testing.T) {
+		var got profileOrder
+		require.NoError(t, New().FromMap(want, &got))
+		assert.Equal(t, o.Total, got.Total)
+		assert.True(t, o.Event.At.Equal(got.Event.At))
+	})
+	t.Run("field lookup", func(t *testing.T) {
+		var got profileOrder
+		require.NoError(t, New().Update(&got, map[string]func(any) any{
+			"total.currency": func(any) any { return "USD" },
+		}))
+		assert.Equal(t, "USD", got.Total.Currency)
+	})
+	t.Run("removed", func(t *testing.T) {
+		RegisterProfile(reflect.TypeOf(profileMoney{}))
+		got := New().ToMap(o)
+		assert.Equal(t, map[string]any{"Amount": 100, "Currency": "EUR"}, got["total"])
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterProfile(reflect.TypeOf(1), Flatten()) })
+		assert.Panics(t, func() { RegisterProfile(nil) })
+	})
+}
@@ -0,0 +1,33 @@
+package tagops
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ProtobufTag is the tag of the structs generated by protoc-gen-go, i.e.
+// `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3"`.  With
+// Tag(ProtobufTag), the key is the name= part of the tag, and the other parts
+// are ignored, so that the generated messages are mapped under the field
+// names of the .proto file.  The internal fields of the messages are
+// unexported and are skipped, and the oneof fields, that have no protobuf
+// tag, are mapped as the untagged fields.
+const ProtobufTag = "protobuf"
+
+// protobufName is the prefix of the name part of the protobuf tag.
+const protobufName = "name="
+
+// tagValue returns the value of the tag of the field.  The protobuf tag is
+// reduced to the name, see ProtobufTag.
+func tagValue(field reflect.StructField, tag string) string {
+	val := field.Tag.Get(tag)
+	if tag != ProtobufTag || val == "" {
+		return val
+	}
+	for _, part := range strings.Split(val, tagsep) {
+		if name, ok := strings.CutPrefix(part, protobufName); ok {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pbUser resembles the struct generated by protoc-gen-go.
+type pbUser struct {
+	state         struct{}
+	sizeCache     int32
+	unknownFields []byte
+
+	UserId string   `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Age    int32    `protobuf:"varint,2,opt,name=age,proto3" json:"age,omitempty"`
+	Tags   []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Kind is the oneof field.
+	Kind isPbKind `protobuf_oneof:"kind"`
+}
+
+type isPbKind interface{ isPbKind() }
+
+func TestProtobufTag(t *testing.T) {
+	m := New(Tag(ProtobufTag))
+	u := pbUser{UserId: "u1", Age: 42, Tags: []string{"a"}}
+	assert.Equal(t, map[string]any{"user_id": "u1", "age": int32(42), "tags": []string{"a"}, "Kind": nil}, m.ToMap(&u))
+
+	var got pbUser
+	require.NoError(t, m.FromMap(map[string]any{"user_id": "u2", "age": 7}, &got))
+	assert.Equal(t, "u2", got.UserId)
+	assert.Equal(t, int32(7), got.Age)
+}
+
+func Test_tagValue(t *testing.T) {
+	type S struct {
+		A int `protobuf:"varint,1,opt,name=a_b,json=aB,proto3" json:"a,omitempty"`
+		B int `protobuf:"varint,2,opt,proto3"`
+	}
+	a := field(t, S{}, 0)
+	assert.Equal(t, "a_b", tagValue(a, ProtobufTag))
+	assert.Equal(t, "a,omitempty", tagValue(a, "json"))
+	assert.Equal(t, "", tagValue(field(t, S{}, 1), ProtobufTag))
+}
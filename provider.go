@@ -0,0 +1,48 @@
+package tagops
+
+import (
+	"reflect"
+)
+
+// MapProvider is implemented by types that can convert themselves to a map
+// without reflection.  If the value, or a nested struct field, implements
+// MapProvider, the Mapper uses the returned map as is, instead of inspecting
+// the value with reflection.  tag is the tag name of the Mapper.  It allows
+// hot types to provide hand-written or generated fast paths.
+type MapProvider interface {
+	TagOpsMap(tag string) map[string]any
+}
+
+// ValuesProvider is the Values counterpart of MapProvider.  TagOpsValues
+// must return the values in the alphabetical order of tags, consistent with
+// the keys of the map returned by TagOpsMap.
+type ValuesProvider interface {
+	TagOpsValues(tag string) []any
+}
+
+var mapProviderType = reflect.TypeOf((*MapProvider)(nil)).Elem()
+
+// asMapProvider returns the MapProvider implemented by the value v or by a
+// pointer to it.  Types with a registered converter, and nil pointers are not
+// considered providers.
+func asMapProvider(v reflect.Value) (MapProvider, bool) {
+	t := v.Type()
+	if _, ok := outConverter(t); ok {
+		return nil, false
+	}
+	if t.Implements(mapProviderType) {
+		if (t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface) && v.IsNil() {
+			return nil, false
+		}
+		return v.Interface().(MapProvider), true
+	}
+	if reflect.PointerTo(t).Implements(mapProviderType) {
+		if v.CanAddr() {
+			return v.Addr().Interface().(MapProvider), true
+		}
+		pv := reflect.New(t)
+		pv.Elem().Set(v)
+		return pv.Interface().(MapProvider), true
+	}
+	return nil, false
+}
@@ -0,0 +1,77 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// point implements MapProvider and ValuesProvider on a value receiver.
+type point struct {
+	X, Y int
+}
+
+func (p point) TagOpsMap(tag string) map[string]any {
+	return map[string]any{tag + "_x": p.X, tag + "_y": p.Y}
+}
+
+func (p point) TagOpsValues(tag string) []any {
+	return []any{p.X, p.Y}
+}
+
+// label implements MapProvider on a pointer receiver.
+type label struct {
+	Text string
+}
+
+func (l *label) TagOpsMap(tag string) map[string]any {
+	return map[string]any{"text": "<" + l.Text + ">"}
+}
+
+func TestMapProvider(t *testing.T) {
+	type Shape struct {
+		Name   string `json:"name"`
+		Center point  `json:"center"`
+		Label  label  `json:"label"`
+		Ptr    *label `json:"ptr"`
+	}
+	s := Shape{Name: "circle", Center: point{1, 2}, Label: label{"a"}}
+	t.Run("root", func(t *testing.T) {
+		assert.Equal(t, map[string]any{"db_x": 1, "db_y": 2}, New(Tag("db")).ToMap(point{1, 2}))
+	})
+	t.Run("nested", func(t *testing.T) {
+		want := map[string]any{
+			"name":   "circle",
+			"center": map[string]any{"json_x": 1, "json_y": 2},
+			"label":  map[string]any{"text": "<a>"},
+			"ptr":    (*label)(nil),
+		}
+		assert.Equal(t, want, New().ToMap(s))
+		assert.Equal(t, want, New().ToMap(&s), "addressable")
+	})
+	t.Run("flatten", func(t *testing.T) {
+		want := map[string]any{
+			"name":   "circle",
+			"json_x": 1,
+			"json_y": 2,
+			"text":   "<a>",
+			"ptr":    (*label)(nil),
+		}
+		assert.Equal(t, want, New(Flatten()).ToMap(s))
+	})
+	t.Run("non-nil pointer", func(t *testing.T) {
+		s := Shape{Ptr: &label{"b"}}
+		got := New().ToMap(s)
+		assert.Equal(t, map[string]any{"text": "<b>"}, got["ptr"])
+	})
+	t.Run("multimap", func(t *testing.T) {
+		got := ToMultiMap(s, "json", "db")
+		assert.Equal(t, map[string]any{"db_x": 1, "db_y": 2}, got["db"]["Center"])
+	})
+}
+
+func TestValuesProvider(t *testing.T) {
+	got, err := New().Values(point{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{1, 2}, got)
+}
@@ -0,0 +1,126 @@
+package tagops
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Record is the map produced by ToMap with the accessor methods.  The methods
+// accept the dot-separated key paths to the values in the nested maps, i.e.
+// "address.city".
+//
+// Record is a map, so the map produced by ToMap can be converted to it
+// directly, i.e. Record(mp).
+type Record map[string]any
+
+// ToRecord returns the Record of the struct a, see Mapper.ToRecord.
+func ToRecord(a any) (Record, error) {
+	return New().ToRecord(a)
+}
+
+// ToRecord is like ToMapE, but returns the map as a Record.
+func (m Mapper) ToRecord(a any) (Record, error) {
+	mp, err := m.ToMapE(a)
+	if mp == nil {
+		return nil, err
+	}
+	return Record(mp), err
+}
+
+// Get returns the value at path, and true, if the value exists.
+func (r Record) Get(path string) (any, bool) {
+	var cur any = map[string]any(r)
+	for _, key := range strings.Split(path, ".") {
+		mp, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = mp[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// Has returns true if the value at path exists, even if it's nil.
+func (r Record) Has(path string) bool {
+	_, ok := r.Get(path)
+	return ok
+}
+
+// MustGet returns the value at path.  It panics if the value does not exist.
+func (r Record) MustGet(path string) any {
+	v, ok := r.Get(path)
+	if !ok {
+		panic(fmt.Errorf("%w: %q", ErrFieldNotFound, path))
+	}
+	return v
+}
+
+// GetOr returns the value at path, or dflt if the value does not exist or is
+// nil, including the nil pointer.
+func (r Record) GetOr(path string, dflt any) any {
+	v, ok := r.Get(path)
+	if !ok || v == nil {
+		return dflt
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return dflt
+		}
+	}
+	return v
+}
+
+// GetString returns the string value at path.  It is not named String, so
+// that Record does not look like a fmt.Stringer.
+func (r Record) GetString(path string) (string, error) {
+	var s string
+	return s, r.as(path, &s)
+}
+
+// Int returns the integer value at path.  Floats without the fractional part
+// and numeric strings are converted.
+func (r Record) Int(path string) (int64, error) {
+	var n int64
+	return n, r.as(path, &n)
+}
+
+// Float returns the float value at path.  Integers and numeric strings are
+// converted.
+func (r Record) Float(path string) (float64, error) {
+	var f float64
+	return f, r.as(path, &f)
+}
+
+// Bool returns the bool value at path.  Strings, such as "true" or "1", are
+// parsed.
+func (r Record) Bool(path string) (bool, error) {
+	var b bool
+	return b, r.as(path, &b)
+}
+
+// Time returns the time value at path.  Strings are parsed as RFC3339.
+func (r Record) Time(path string) (time.Time, error) {
+	var t time.Time
+	return t, r.as(path, &t)
+}
+
+// recordMapper converts the Record values for the typed getters.
+var recordMapper = New(ParseStrings())
+
+// as assigns the value at path to the variable pointed to by ptr.
+func (r Record) as(path string, ptr any) error {
+	v, ok := r.Get(path)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrFieldNotFound, path)
+	}
+	if err := recordMapper.assign(context.Background(), reflect.ValueOf(ptr).Elem(), v, nil); err != nil {
+		return &FieldError{Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type person struct {
+		Name    string    `json:"name"`
+		Age     int       `json:"age"`
+		Score   float64   `json:"score"`
+		Admin   string    `json:"admin"`
+		Born    time.Time `json:"born"`
+		Note    *string   `json:"note"`
+		Address address   `json:"address"`
+	}
+	born := time.Date(1980, 1, 2, 3, 4, 5, 0, time.UTC)
+	r, err := ToRecord(person{Name: "Bob", Age: 42, Score: 3, Admin: "true", Born: born, Address: address{City: "Paris"}})
+	require.NoError(t, err)
+
+	t.Run("Has", func(t *testing.T) {
+		assert.True(t, r.Has("name"))
+		assert.True(t, r.Has("note"), "nil value exists")
+		assert.True(t, r.Has("address.city"))
+		assert.False(t, r.Has("phone"))
+		assert.False(t, r.Has("name.first"))
+	})
+	t.Run("MustGet", func(t *testing.T) {
+		assert.Equal(t, "Paris", r.MustGet("address.city"))
+		assert.PanicsWithError(t, `field not found: "address.zip"`, func() { r.MustGet("address.zip") })
+	})
+	t.Run("GetOr", func(t *testing.T) {
+		assert.Equal(t, "Bob", r.GetOr("name", "anonymous"))
+		assert.Equal(t, "none", r.GetOr("note", "none"))
+		assert.Equal(t, "none", r.GetOr("phone", "none"))
+	})
+	t.Run("typed getters", func(t *testing.T) {
+		s, err := r.GetString("address.city")
+		assert.NoError(t, err)
+		assert.Equal(t, "Paris", s)
+
+		n, err := r.Int("age")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), n)
+
+		n, err = r.Int("score")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+
+		f, err := r.Float("age")
+		assert.NoError(t, err)
+		assert.Equal(t, 42.0, f)
+
+		b, err := r.Bool("admin")
+		assert.NoError(t, err)
+		assert.True(t, b)
+
+		tm, err := r.Time("born")
+		assert.NoError(t, err)
+		assert.True(t, born.Equal(tm))
+	})
+	t.Run("typed getter errors", func(t *testing.T) {
+		_, err := r.Int("phone")
+		assert.ErrorIs(t, err, ErrFieldNotFound)
+
+		_, err = r.Int("name")
+		var fe *FieldError
+		assert.ErrorAs(t, err, &fe)
+		assert.Equal(t, "name", fe.Path)
+
+		_, err = r.GetString("address")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,77 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// secure tag and its value that marks the fields for encryption.
+const (
+	secureTag     = "secure"
+	secureEncrypt = "encrypt"
+)
+
+// EncryptFunc encrypts, or decrypts, the value v of the field with the map key
+// key.  The key may be used to select the encryption key, or as the
+// additional authenticated data.
+type EncryptFunc func(key string, v any) (any, error)
+
+// Encryption returns an Option that sets the functions to encrypt the values
+// of fields marked with the `secure:"encrypt"` tag in ToMap, and to decrypt
+// them in FromMap.  Either of the functions may be nil.  The functions receive
+// the values after formatting, i.e. time.Time formatted according to
+// FormatTime, and before parsing, respectively.
+//
+// Nested structs, map providers and Optionals marked for encryption are
+// encrypted as a whole, the functions receive and return their maps.  Such
+// fields are never flattened, embedded structs are mapped under their key,
+// as with NoFlattenAnonymous.  If the encryption fails, the field is omitted
+// and the error is reported, so that the plain value never appears in the
+// output.  TopLevel skips the secure fields, as it yields the raw values.
+func Encryption(encrypt, decrypt EncryptFunc) Option {
+	return func(m *Mapper) {
+		m.encrypt = encrypt
+		m.decrypt = decrypt
+	}
+}
+
+// isSecure returns true if the field is marked for encryption.
+func isSecure(field reflect.StructField) bool {
+	return field.Tag.Get(secureTag) == secureEncrypt
+}
+
+// secure returns true if the field is marked for encryption, and the
+// Mapper has any of the encryption functions set.
+func (m Mapper) secure(field reflect.StructField) bool {
+	return (m.encrypt != nil || m.decrypt != nil) && isSecure(field)
+}
+
+// encryptField encrypts the value val of the field, if it's marked for
+// encryption.  It returns false if the field should be omitted.  Errors are
+// reported to st at path, if st is not nil.
+func (m Mapper) encryptField(st *state, field reflect.StructField, key string, val any, path string) (any, bool) {
+	if m.encrypt == nil || !isSecure(field) {
+		return val, true
+	}
+	enc, err := m.encrypt(key, val)
+	if err != nil {
+		if st != nil {
+			st.fail(path, fmt.Errorf("encrypt: %w", err))
+		}
+		return nil, false
+	}
+	return enc, true
+}
+
+// decryptField decrypts the map value sv of the field, if it's marked for
+// encryption.
+func (m Mapper) decryptField(field reflect.StructField, key string, sv any) (any, error) {
+	if m.decrypt == nil || !isSecure(field) {
+		return sv, nil
+	}
+	dec, err := m.decrypt(key, sv)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return dec, nil
+}
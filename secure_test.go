@@ -0,0 +1,186 @@
+package tagops
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rot13 is the test "encryption".
+func rot13(key string, v any) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected string, got %T", key, v)
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return 'a' + (r-'a'+13)%26
+		case r >= 'A' && r <= 'Z':
+			return 'A' + (r-'A'+13)%26
+		}
+		return r
+	}, s), nil
+}
+
+func TestEncryption(t *testing.T) {
+	type Customer struct {
+		Name  string `json:"name"`
+		Email string `json:"email" secure:"encrypt"`
+		PIN   int    `json:"pin" secure:"encrypt"`
+	}
+	c := Customer{Name: "John", Email: "john@example.com", PIN: 1234}
+	m := New(Encryption(rot13, rot13))
+
+	t.Run("to map", func(t *testing.T) {
+		got, err := m.ToMapE(c)
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "PIN", fe.Path)
+		assert.Equal(t, map[string]any{"name": "John", "email": "wbua@rknzcyr.pbz"}, got, "failed fields are omitted")
+	})
+	t.Run("multimap", func(t *testing.T) {
+		got := m.ToMultiMap(c, "json")
+		assert.Equal(t, map[string]any{"name": "John", "email": "wbua@rknzcyr.pbz"}, got["json"])
+	})
+	t.Run("from map", func(t *testing.T) {
+		var got Customer
+		require.NoError(t, m.FromMap(map[string]any{"name": "John", "email": "wbua@rknzcyr.pbz"}, &got))
+		assert.Equal(t, Customer{Name: "John", Email: "john@example.com"}, got)
+	})
+	t.Run("decrypt error", func(t *testing.T) {
+		fail := func(string, any) (any, error) { return nil, errors.New("bad key") }
+		var got Customer
+		err := New(Encryption(nil, fail)).FromMap(map[string]any{"email": "x"}, &got)
+		assert.EqualError(t, err, "Email: decrypt: bad key")
+	})
+	t.Run("no functions", func(t *testing.T) {
+		assert.Equal(t, map[string]any{"name": "John", "email": "john@example.com", "pin": 1234}, New().ToMap(c))
+	})
+}
+
+// sealed is the test "ciphertext" of any value.
+type sealed struct{ v any }
+
+func seal(_ string, v any) (any, error) { return sealed{v}, nil }
+
+func unseal(key string, v any) (any, error) {
+	s, ok := v.(sealed)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected sealed, got %T", key, v)
+	}
+	return s.v, nil
+}
+
+// cardProvider is the MapProvider marked for encryption in the tests.
+type cardProvider struct{ Number string }
+
+func (c cardProvider) TagOpsMap(string) map[string]any {
+	return map[string]any{"number": c.Number}
+}
+
+type SecureBase struct {
+	SSN string `json:"ssn"`
+}
+
+type secureAddress struct {
+	City string `json:"city"`
+}
+
+type securePerson struct {
+	SecureBase `secure:"encrypt"`
+	Name       string                   `json:"name"`
+	Address    secureAddress            `json:"address" secure:"encrypt"`
+	Card       cardProvider             `json:"card" secure:"encrypt"`
+	Home       *secureAddress           `json:"home" secure:"encrypt"`
+	Phone      Optional[string]         `json:"phone" secure:"encrypt"`
+	Work       Optional[secureAddress]  `json:"work" secure:"encrypt"`
+	Extra      map[string]secureAddress `json:"extra,omitempty"`
+}
+
+func TestEncryption_nested(t *testing.T) {
+	p := securePerson{
+		SecureBase: SecureBase{SSN: "123-45-6789"},
+		Name:       "John",
+		Address:    secureAddress{City: "Anytown"},
+		Card:       cardProvider{Number: "4111"},
+		Phone:      Some("555-1234"),
+	}
+	m := New(Encryption(seal, unseal), Omitempty())
+	want := map[string]any{
+		"SecureBase": sealed{map[string]any{"ssn": "123-45-6789"}},
+		"name":       "John",
+		"address":    sealed{map[string]any{"city": "Anytown"}},
+		"card":       sealed{map[string]any{"number": "4111"}},
+		"home":       sealed{(*secureAddress)(nil)},
+		"phone":      sealed{"555-1234"},
+	}
+	t.Run("to map", func(t *testing.T) {
+		got, err := m.ToMapE(p)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("multimap", func(t *testing.T) {
+		got, err := m.ToMultiMapE(p, "json")
+		require.NoError(t, err)
+		for _, k := range []string{"SecureBase", "name", "address", "card", "home"} {
+			assert.Equal(t, want[k], got["json"][k], k)
+		}
+	})
+	t.Run("map input", func(t *testing.T) {
+		got, err := m.ToMapE(map[string]any{"person": p})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"person": want}, got)
+	})
+	t.Run("top level", func(t *testing.T) {
+		var keys []string
+		for k := range New(Encryption(seal, unseal)).TopLevel(p) {
+			keys = append(keys, k)
+		}
+		assert.Equal(t, []string{"name", "extra"}, keys)
+	})
+	t.Run("from map", func(t *testing.T) {
+		src := map[string]any{
+			"SecureBase": sealed{map[string]any{"ssn": "987-65-4321"}},
+			"name":       "Jane",
+			"address":    sealed{map[string]any{"city": "Othertown"}},
+			"home":       sealed{map[string]any{"city": "Hometown"}},
+			"phone":      sealed{"555-0000"},
+			"work":       sealed{map[string]any{"city": "Worktown"}},
+		}
+		var got securePerson
+		require.NoError(t, m.FromMap(src, &got))
+		assert.Equal(t, securePerson{
+			SecureBase: SecureBase{SSN: "987-65-4321"},
+			Name:       "Jane",
+			Address:    secureAddress{City: "Othertown"},
+			Home:       &secureAddress{City: "Hometown"},
+			Phone:      Some("555-0000"),
+			Work:       Some(secureAddress{City: "Worktown"}),
+		}, got)
+	})
+	t.Run("plain nested value is rejected", func(t *testing.T) {
+		var got securePerson
+		err := m.FromMap(map[string]any{"address": map[string]any{"city": "Othertown"}}, &got)
+		assert.ErrorContains(t, err, "address: expected sealed")
+		assert.Empty(t, got.Address.City)
+	})
+	t.Run("encryption error", func(t *testing.T) {
+		fail := func(string, any) (any, error) { return nil, errors.New("no key") }
+		m := New(Encryption(fail, nil))
+		got, err := m.ToMapE(p)
+		assert.ErrorContains(t, err, "encrypt: no key")
+		assert.NotContains(t, got, "address")
+		assert.NotContains(t, got, "ssn")
+		assert.NotContains(t, got, "SecureBase")
+
+		mm, err := m.ToMultiMapE(p, "json", "db")
+		assert.ErrorContains(t, err, "encrypt: no key")
+		assert.NotContains(t, mm["json"], "address")
+		assert.NotContains(t, mm["db"], "ssn")
+	})
+}
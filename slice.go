@@ -0,0 +1,157 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// IndexBy returns the map of the elements of the slice of structs, keyed by
+// the value of the field with the tag name.  If several elements have the
+// same key, the last one wins.  The field values must be comparable.
+func (m Mapper) IndexBy(slice any, name string) (map[any]any, error) {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[any]any, sv.Len())
+	for i := range sv.Len() {
+		key, err := m.elemKey(sv.Index(i), name)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out[key] = sv.Index(i).Interface()
+	}
+	return out, nil
+}
+
+// GroupBy returns the map of the elements of the slice of structs, grouped by
+// the value of the field with the tag name.  The order of elements within
+// the group is preserved.  The field values must be comparable.
+func (m Mapper) GroupBy(slice any, name string) (map[any][]any, error) {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[any][]any)
+	for i := range sv.Len() {
+		key, err := m.elemKey(sv.Index(i), name)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out[key] = append(out[key], sv.Index(i).Interface())
+	}
+	return out, nil
+}
+
+// elemKey returns the value of the field with the key name of the struct
+// element v, suitable for use as a map key.
+func (m Mapper) elemKey(v reflect.Value, name string) (any, error) {
+	fv, err := m.elemField(v, name)
+	if err != nil {
+		return nil, err
+	}
+	if !fv.Comparable() {
+		return nil, fmt.Errorf("field %q: %w: %s is not comparable", name, ErrUnsupportedKind, fv.Type())
+	}
+	return fv.Interface(), nil
+}
+
+// UnionTags returns a sorted list of tags that appear in the map of any
+// element of the slice of structs, i.e. when Omitempty is set, and optional
+// fields are present only in some elements.  The tags pinned with the col
+// tag are at their positions, see Tags.  The returned slice is never nil.
+func (m Mapper) UnionTags(slice any) ([]string, error) {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]any, sv.Len())
+	var pins map[string]int
+	for i := range sv.Len() {
+		elem := sv.Index(i).Interface()
+		if rows[i], err = m.ToMapE(elem); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		if i == 0 {
+			pins = m.columnPins(elem)
+		}
+	}
+	if pins == nil {
+		return UnionTags(rows), nil
+	}
+	union := make(map[string]any)
+	for _, row := range rows {
+		for k := range row {
+			union[k] = nil
+		}
+	}
+	return columns(union, pins), nil
+}
+
+// Columns transposes the slice of structs into the map of columns, keyed by
+// tag.  Each column has a value for every element of the slice, in the slice
+// order, with nil values for keys missing in the element map, i.e. omitted
+// with Omitempty.
+func (m Mapper) Columns(slice any) (map[string][]any, error) {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]any)
+	for i := range sv.Len() {
+		mp, err := m.ToMapE(sv.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		for k, v := range mp {
+			col, ok := out[k]
+			if !ok {
+				col = make([]any, sv.Len())
+				out[k] = col
+			}
+			col[i] = v
+		}
+	}
+	return out, nil
+}
+
+// Pluck returns the values of the field with the tag name of each element of
+// the slice of structs, in the slice order.  The values are returned as is,
+// without conversion.
+func (m Mapper) Pluck(slice any, name string) ([]any, error) {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]any, sv.Len())
+	for i := range sv.Len() {
+		fv, err := m.elemField(sv.Index(i), name)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		out[i] = fv.Interface()
+	}
+	return out, nil
+}
+
+// Filter returns a new slice of the same type with the elements of the slice
+// of structs, whose field with the tag name satisfies the predicate pred.
+// The predicate is called with the field value as is.  For arrays, the slice
+// of the array element type is returned.
+func (m Mapper) Filter(slice any, name string, pred func(any) bool) (any, error) {
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.MakeSlice(reflect.SliceOf(sv.Type().Elem()), 0, 0)
+	for i := range sv.Len() {
+		fv, err := m.elemField(sv.Index(i), name)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		if pred(fv.Interface()) {
+			out = reflect.Append(out, sv.Index(i))
+		}
+	}
+	return out.Interface(), nil
+}
@@ -0,0 +1,189 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type SliceBase struct {
+	ID int `json:"id"`
+}
+
+type sliceUser struct {
+	SliceBase
+	Name string   `json:"name"`
+	Team string   `json:"team"`
+	Tags []string `json:"tags"`
+}
+
+var sliceUsers = []sliceUser{
+	{SliceBase{1}, "John", "red", nil},
+	{SliceBase{2}, "Jane", "blue", nil},
+	{SliceBase{3}, "Bob", "red", nil},
+}
+
+func TestIndexBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		slice   any
+		tagName string
+		want    map[any]any
+		wantErr error
+	}{
+		{
+			name:    "promoted field",
+			slice:   sliceUsers,
+			tagName: "id",
+			want:    map[any]any{1: sliceUsers[0], 2: sliceUsers[1], 3: sliceUsers[2]},
+		},
+		{
+			name:    "last wins",
+			slice:   sliceUsers,
+			tagName: "team",
+			want:    map[any]any{"red": sliceUsers[2], "blue": sliceUsers[1]},
+		},
+		{
+			name:    "pointers",
+			slice:   []*sliceUser{&sliceUsers[0]},
+			tagName: "name",
+			want:    map[any]any{"John": &sliceUsers[0]},
+		},
+		{
+			name:    "not a slice",
+			slice:   sliceUsers[0],
+			tagName: "id",
+			wantErr: ErrNotSlice,
+		},
+		{
+			name:    "no such field",
+			slice:   sliceUsers,
+			tagName: "email",
+			wantErr: ErrFieldNotFound,
+		},
+		{
+			name:    "not comparable",
+			slice:   sliceUsers,
+			tagName: "tags",
+			wantErr: ErrUnsupportedKind,
+		},
+		{
+			name:    "nil element",
+			slice:   []*sliceUser{nil},
+			tagName: "id",
+			wantErr: ErrNotStruct,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IndexBy(tt.slice, tt.tagName)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	got, err := GroupBy(sliceUsers, "team")
+	assert.NoError(t, err)
+	assert.Equal(t, map[any][]any{
+		"red":  {sliceUsers[0], sliceUsers[2]},
+		"blue": {sliceUsers[1]},
+	}, got)
+
+	_, err = New(Tag("db")).GroupBy(sliceUsers, "team")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+}
+
+func TestMapper_UnionTags(t *testing.T) {
+	type row struct {
+		ID    int    `json:"id"`
+		Email string `json:"email,omitempty"`
+		Phone string `json:"phone,omitempty"`
+	}
+	m := New(Omitempty())
+	rows := []row{{ID: 1}, {ID: 2, Phone: "555"}, {ID: 3, Email: "a@b"}}
+	got, err := m.UnionTags(rows)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"email", "id", "phone"}, got)
+
+	got, err = m.UnionTags(&rows)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"email", "id", "phone"}, got)
+
+	got, err = m.UnionTags([]row{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, got)
+
+	_, err = m.UnionTags(row{})
+	assert.ErrorIs(t, err, ErrNotSlice)
+
+	_, err = m.UnionTags([]any{row{}, 42})
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
+
+func TestColumns(t *testing.T) {
+	got, err := Columns(sliceUsers)
+	assert.NoError(t, err)
+	assert.Len(t, got, 4)
+	assert.Equal(t, []any{1, 2, 3}, got["id"])
+	assert.Equal(t, []any{"John", "Jane", "Bob"}, got["name"])
+	assert.Equal(t, []any{"red", "blue", "red"}, got["team"])
+
+	got, err = Columns([]sliceUser{})
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = Columns(42)
+	assert.ErrorIs(t, err, ErrNotSlice)
+}
+
+func TestMapper_Columns_omitempty(t *testing.T) {
+	type row struct {
+		ID    int    `json:"id"`
+		Phone string `json:"phone,omitempty"`
+	}
+	got, err := New(Omitempty()).Columns([]row{{ID: 1}, {ID: 2, Phone: "555"}, {ID: 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]any{
+		"id":    {1, 2, 3},
+		"phone": {nil, "555", nil},
+	}, got)
+}
+
+func TestPluck(t *testing.T) {
+	got, err := Pluck(sliceUsers, "name")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"John", "Jane", "Bob"}, got)
+
+	got, err = Pluck(sliceUsers, "id")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{1, 2, 3}, got)
+
+	_, err = Pluck(sliceUsers, "missing")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+}
+
+func TestFilter(t *testing.T) {
+	isRed := func(v any) bool { return v == "red" }
+	got := Filter(sliceUsers, "team", isRed)
+	assert.Equal(t, []sliceUser{sliceUsers[0], sliceUsers[2]}, got)
+
+	got = Filter(sliceUsers, "team", func(any) bool { return false })
+	assert.Equal(t, []sliceUser{}, got)
+
+	ptrs := []*sliceUser{&sliceUsers[0], &sliceUsers[1]}
+	got = Filter(ptrs, "id", func(v any) bool { return v.(int) > 1 })
+	assert.Equal(t, []*sliceUser{&sliceUsers[1]}, got)
+
+	arr := [2]sliceUser{sliceUsers[0], sliceUsers[1]}
+	got = Filter(arr, "name", func(v any) bool { return v == "Jane" })
+	assert.Equal(t, []sliceUser{sliceUsers[1]}, got)
+
+	assert.Nil(t, Filter(sliceUsers, "missing", isRed))
+
+	_, err := New().Filter(sliceUsers, "missing", isRed)
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	_, err = New().Filter(42, "team", isRed)
+	assert.ErrorIs(t, err, ErrNotSlice)
+}
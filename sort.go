@@ -0,0 +1,140 @@
+package tagops
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SortBy sorts the slice of structs in place by the fields with the json tag
+// names, see Mapper.SortBy.
+func SortBy(slice any, tags ...string) error {
+	return New().SortBy(slice, tags...)
+}
+
+// SortBy sorts the slice of structs, or a pointer to a slice or an array, in
+// place by the fields with the tag names.  The elements are ordered by the
+// first field, then by the second field, and so on.  The name prefixed with
+// "-" sorts in descending order, i.e. "-created".  The sort is stable.
+//
+// Fields must be strings, bools, numbers or time.Time, or pointers to them.
+// Nil pointers sort before any value.
+func (m Mapper) SortBy(slice any, tags ...string) error {
+	if len(tags) == 0 {
+		return errors.New("SortBy: no tags")
+	}
+	sv, err := sliceValue(slice)
+	if err != nil {
+		return err
+	}
+	if !sv.CanSet() && sv.Kind() == reflect.Array {
+		return fmt.Errorf("%w: array must be passed by pointer", ErrNotSlice)
+	}
+	names := make([]string, len(tags))
+	desc := make([]bool, len(tags))
+	for i, tag := range tags {
+		names[i], desc[i] = strings.CutPrefix(tag, "-")
+	}
+	// extract the sort keys once per element.
+	n := sv.Len()
+	keys := make([][]reflect.Value, n)
+	for i := range n {
+		keys[i] = make([]reflect.Value, len(names))
+		for j, name := range names {
+			fv, err := m.elemField(sv.Index(i), name)
+			if err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+			if !isSortable(fv) {
+				return fmt.Errorf("field %q: %w: %s is not sortable", name, ErrUnsupportedKind, fv.Type())
+			}
+			if i > 0 && fv.Type() != keys[0][j].Type() {
+				return fmt.Errorf("element %d: field %q: %w: %s and %s", i, name, ErrTypeMismatch, fv.Type(), keys[0][j].Type())
+			}
+			keys[i][j] = fv
+		}
+	}
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(a, b int) bool {
+		ka, kb := keys[perm[a]], keys[perm[b]]
+		for j := range names {
+			c := compareValues(ka[j], kb[j])
+			if desc[j] {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	sorted := reflect.New(reflect.ArrayOf(n, sv.Type().Elem())).Elem()
+	for i, p := range perm {
+		sorted.Index(i).Set(sv.Index(p))
+	}
+	reflect.Copy(sv, sorted)
+	return nil
+}
+
+// isSortable returns true if compareValues can compare values of the type of
+// v.
+func isSortable(v reflect.Value) bool {
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool:
+		return true
+	}
+	return isNumber(t.Kind())
+}
+
+// compareValues compares the sortable values a and b of the same type, and
+// returns -1, 0 or 1.
+func compareValues(a, b reflect.Value) int {
+	if a.Kind() == reflect.Ptr {
+		switch {
+		case a.IsNil() && b.IsNil():
+			return 0
+		case a.IsNil():
+			return -1
+		case b.IsNil():
+			return 1
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	}
+	switch {
+	case a.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case a.CanFloat():
+		return cmp.Compare(a.Float(), b.Float())
+	case a.Kind() == reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	case a.Kind() == reflect.Bool:
+		return cmp.Compare(boolInt(a.Bool()), boolInt(b.Bool()))
+	}
+	return 0
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortBy(t *testing.T) {
+	type event struct {
+		Name string    `json:"name"`
+		Prio int       `json:"prio"`
+		At   time.Time `json:"at"`
+		Done *bool     `json:"done"`
+	}
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := func() []event {
+		return []event{
+			{"b", 2, t0.Add(time.Hour), ptr(true)},
+			{"a", 1, t0, nil},
+			{"c", 2, t0.Add(-time.Hour), ptr(false)},
+			{"d", 1, t0.Add(2 * time.Hour), nil},
+		}
+	}
+	names := func(ee []event) []string {
+		var out []string
+		for _, e := range ee {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"string", []string{"name"}, []string{"a", "b", "c", "d"}},
+		{"descending", []string{"-name"}, []string{"d", "c", "b", "a"}},
+		{"stable", []string{"prio"}, []string{"a", "d", "b", "c"}},
+		{"two keys", []string{"-prio", "-name"}, []string{"c", "b", "d", "a"}},
+		{"time", []string{"at"}, []string{"c", "a", "b", "d"}},
+		{"nil pointers first", []string{"done", "name"}, []string{"a", "d", "c", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ee := events()
+			assert.NoError(t, SortBy(ee, tt.tags...))
+			assert.Equal(t, tt.want, names(ee))
+		})
+	}
+	t.Run("pointer to array", func(t *testing.T) {
+		ee := [2]event{{Name: "z"}, {Name: "y"}}
+		assert.NoError(t, SortBy(&ee, "name"))
+		assert.Equal(t, "y", ee[0].Name)
+		assert.Error(t, SortBy(ee, "name"), "array by value")
+	})
+	t.Run("pointer elements", func(t *testing.T) {
+		ee := []*event{{Name: "z"}, {Name: "y"}}
+		assert.NoError(t, SortBy(ee, "name"))
+		assert.Equal(t, "y", ee[0].Name)
+	})
+	t.Run("errors", func(t *testing.T) {
+		type tagged struct {
+			Tags []string `json:"tags"`
+		}
+		assert.ErrorIs(t, SortBy([]tagged{{}}, "tags"), ErrUnsupportedKind)
+		assert.ErrorIs(t, SortBy(events(), "missing"), ErrFieldNotFound)
+		assert.ErrorIs(t, SortBy(events()[0], "name"), ErrNotSlice)
+		assert.ErrorIs(t, SortBy([]any{event{}, tagged{}}, "name"), ErrFieldNotFound)
+		assert.Error(t, SortBy(events()))
+	})
+}
+
+func TestSortBy_typeMismatch(t *testing.T) {
+	type a struct {
+		V int `json:"v"`
+	}
+	type b struct {
+		V string `json:"v"`
+	}
+	assert.ErrorIs(t, SortBy([]any{a{1}, b{"x"}}, "v"), ErrTypeMismatch)
+}
@@ -0,0 +1,228 @@
+package tagops
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidSpec is returned by NewFromSpec when the spec has an unknown
+// value.
+var ErrInvalidSpec = errors.New("invalid mapper spec")
+
+// MapperSpec is the declarative configuration of the Mapper, that may be
+// loaded from JSON or YAML, so that the conversion is configured at runtime,
+// i.e. per deployment.  The zero value is the default Mapper.  The named
+// values are the same as the tag options, where they exist.
+type MapperSpec struct {
+	// Tag is the tag name, "json" if empty.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// Version is the version-qualified tag, see Mapper.Version.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Omitempty, Flatten, NoFlattenAnonymous, FieldOrder, EnumStrings,
+	// ParseStrings, StrictConflicts and CollectErrors enable the options of
+	// the same names.
+	Omitempty          bool `json:"omitempty,omitempty" yaml:"omitempty,omitempty"`
+	Flatten            bool `json:"flatten,omitempty" yaml:"flatten,omitempty"`
+	NoFlattenAnonymous bool `json:"no_flatten_anonymous,omitempty" yaml:"no_flatten_anonymous,omitempty"`
+	FieldOrder         bool `json:"field_order,omitempty" yaml:"field_order,omitempty"`
+	EnumStrings        bool `json:"enum_strings,omitempty" yaml:"enum_strings,omitempty"`
+	ParseStrings       bool `json:"parse_strings,omitempty" yaml:"parse_strings,omitempty"`
+	StrictConflicts    bool `json:"strict_conflicts,omitempty" yaml:"strict_conflicts,omitempty"`
+	CollectErrors      bool `json:"collect_errors,omitempty" yaml:"collect_errors,omitempty"`
+	// Include and Exclude are the key paths, see Include and Exclude.
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	// Prefix is the prefix of the top-level keys, see Prefix.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// KeyFunc is the key function: "lower", "upper", "snake", "kebab",
+	// "camel" or "db", see DBName.
+	KeyFunc string `json:"key_func,omitempty" yaml:"key_func,omitempty"`
+	// Untagged is the policy for the untagged fields: "keyfunc",
+	// "fieldname" or "skip".
+	Untagged string `json:"untagged,omitempty" yaml:"untagged,omitempty"`
+	// Unsupported is the policy for the unsupported kinds: "keep", "skip",
+	// "nil" or "error".
+	Unsupported string `json:"unsupported,omitempty" yaml:"unsupported,omitempty"`
+	// TimeFormat is "rfc3339", "unix" or "unixms".
+	TimeFormat string `json:"time_format,omitempty" yaml:"time_format,omitempty"`
+	// TimeZone is the IANA name of the location, see TimeIn.
+	TimeZone string `json:"time_zone,omitempty" yaml:"time_zone,omitempty"`
+	// DurationFormat is "durstr", "seconds" or "millis".
+	DurationFormat string `json:"duration_format,omitempty" yaml:"duration_format,omitempty"`
+	// BytesFormat is "hex" or "base64".
+	BytesFormat string `json:"bytes_format,omitempty" yaml:"bytes_format,omitempty"`
+	// FloatPrecision is the number of decimal places of floats, if set.
+	FloatPrecision *int `json:"float_precision,omitempty" yaml:"float_precision,omitempty"`
+}
+
+// keyFuncs are the key functions available in the spec.
+var keyFuncs = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"snake": func(s string) string { return joinWords(s, "_") },
+	"kebab": func(s string) string { return joinWords(s, "-") },
+	"camel": camelCase,
+	"db":    DBName,
+}
+
+// NewFromSpec returns the Mapper configured with the spec.  It returns an
+// error wrapping ErrInvalidSpec if any of the named values is unknown.
+func NewFromSpec(spec MapperSpec) (Mapper, error) {
+	opts, err := spec.options()
+	if err != nil {
+		return Mapper{}, err
+	}
+	m := New(opts...)
+	if spec.Version != "" {
+		m = m.Version(spec.Version)
+	}
+	return m, nil
+}
+
+// options returns the options of the spec.
+func (spec MapperSpec) options() ([]Option, error) {
+	var opts []Option
+	if spec.Tag != "" {
+		opts = append(opts, Tag(spec.Tag))
+	}
+	flags := []struct {
+		on  bool
+		opt Option
+	}{
+		{spec.Omitempty, Omitempty()},
+		{spec.Flatten, Flatten()},
+		{spec.NoFlattenAnonymous, NoFlattenAnonymous()},
+		{spec.FieldOrder, FieldOrder()},
+		{spec.EnumStrings, EnumStrings()},
+		{spec.ParseStrings, ParseStrings()},
+		{spec.StrictConflicts, StrictConflicts()},
+		{spec.CollectErrors, CollectErrors()},
+		{len(spec.Include) > 0, Include(spec.Include...)},
+		{len(spec.Exclude) > 0, Exclude(spec.Exclude...)},
+		{spec.Prefix != "", Prefix(spec.Prefix)},
+		{spec.FloatPrecision != nil, FloatPrecision(derefInt(spec.FloatPrecision))},
+	}
+	for _, f := range flags {
+		if f.on {
+			opts = append(opts, f.opt)
+		}
+	}
+	named := []struct {
+		field, val string
+		opts       map[string]Option
+	}{
+		{"untagged", spec.Untagged, map[string]Option{
+			"keyfunc":   Untagged(UseKeyFunc),
+			"fieldname": Untagged(UseFieldName),
+			"skip":      Untagged(SkipUntagged),
+		}},
+		{"unsupported", spec.Unsupported, map[string]Option{
+			"keep":  Unsupported(UnsupportedKeep),
+			"skip":  Unsupported(UnsupportedSkip),
+			"nil":   Unsupported(UnsupportedEmitNil),
+			"error": Unsupported(UnsupportedError),
+		}},
+		{"time_format", spec.TimeFormat, map[string]Option{
+			fRFC3339: FormatTime(TimeRFC3339),
+			fUnix:    FormatTime(TimeUnix),
+			fUnixMs:  FormatTime(TimeUnixMilli),
+		}},
+		{"duration_format", spec.DurationFormat, map[string]Option{
+			fDurString:  FormatDuration(DurationString),
+			fDurSeconds: FormatDuration(DurationSeconds),
+			fDurMillis:  FormatDuration(DurationMillis),
+		}},
+		{"bytes_format", spec.BytesFormat, map[string]Option{
+			fHex:    FormatBytes(BytesHex),
+			fBase64: FormatBytes(BytesBase64),
+		}},
+	}
+	if spec.KeyFunc != "" {
+		fn, ok := keyFuncs[spec.KeyFunc]
+		if !ok {
+			return nil, fmt.Errorf("%w: key_func: unknown value %q", ErrInvalidSpec, spec.KeyFunc)
+		}
+		opts = append(opts, KeyFunc(fn))
+	}
+	for _, n := range named {
+		if n.val == "" {
+			continue
+		}
+		opt, ok := n.opts[n.val]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: unknown value %q", ErrInvalidSpec, n.field, n.val)
+		}
+		opts = append(opts, opt)
+	}
+	if spec.TimeZone != "" {
+		loc, err := loadLocation(spec.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time_zone: %w", ErrInvalidSpec, err)
+		}
+		opts = append(opts, TimeIn(loc))
+	}
+	return opts, nil
+}
+
+func derefInt(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// words splits the Go identifier s into words, keeping the acronyms
+// together, i.e. "HTTPServerID" is "HTTP", "Server", "ID".  Underscores and
+// dashes separate the words too.
+func words(s string) []string {
+	var (
+		out []string
+		cur []rune
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, string(cur))
+			cur = nil
+		}
+	}
+	rs := []rune(s)
+	for i, r := range rs {
+		if r == '_' || r == '-' {
+			flush()
+			continue
+		}
+		if len(cur) > 0 {
+			prev := rs[i-1]
+			lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(r)
+			acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if lowerToUpper || acronymEnd {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return out
+}
+
+// joinWords returns the lowercase words of s joined with sep.
+func joinWords(s, sep string) string {
+	return strings.ToLower(strings.Join(words(s), sep))
+}
+
+// camelCase returns s in the lower camel case, i.e. "userID" for "UserID".
+func camelCase(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		if i == 0 {
+			ws[i] = strings.ToLower(w)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(w)
+		ws[i] = string(unicode.ToUpper(r)) + w[size:]
+	}
+	return strings.Join(ws, "")
+}
@@ -0,0 +1,101 @@
+package tagops
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type specAddress struct {
+	City string `json:"city"`
+	ZIP  string `json:"zip"`
+}
+
+type specUser struct {
+	UserID    int
+	FirstName string
+	Password  string
+	Created   time.Time
+	Address   specAddress `json:"address"`
+}
+
+func TestNewFromSpec(t *testing.T) {
+	u := specUser{UserID: 1, FirstName: "Ann", Password: "x", Created: time.Unix(1700000000, 0), Address: specAddress{City: "Paris", ZIP: "75001"}}
+	want := map[string]any{
+		"app.user_id":    1,
+		"app.first_name": "Ann",
+		"app.created":    int64(1700000000),
+		"app.address":    map[string]any{"city": "Paris"},
+	}
+	t.Run("JSON", func(t *testing.T) {
+		var spec MapperSpec
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"key_func": "snake",
+			"time_format": "unix",
+			"exclude": ["password", "address.zip"],
+			"prefix": "app."
+		}`), &spec))
+		m, err := NewFromSpec(spec)
+		require.NoError(t, err)
+		assert.Equal(t, want, m.ToMap(u))
+	})
+	t.Run("YAML", func(t *testing.T) {
+		var spec MapperSpec
+		require.NoError(t, yaml.Unmarshal([]byte(`
+key_func: snake
+time_format: unix
+include: [user_id, first_name, created, address.city]
+prefix: app.
+`), &spec))
+		m, err := NewFromSpec(spec)
+		require.NoError(t, err)
+		assert.Equal(t, want, m.ToMap(u))
+	})
+	t.Run("zero value", func(t *testing.T) {
+		m, err := NewFromSpec(MapperSpec{})
+		require.NoError(t, err)
+		assert.Equal(t, New().ToMap(u), m.ToMap(u))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, spec := range []MapperSpec{
+			{KeyFunc: "pascal"},
+			{TimeFormat: "iso"},
+			{Untagged: "drop"},
+			{TimeZone: "Nowhere/Land"},
+		} {
+			_, err := NewFromSpec(spec)
+			assert.ErrorIs(t, err, ErrInvalidSpec)
+		}
+	})
+}
+
+func TestKeyFuncs(t *testing.T) {
+	tests := []struct {
+		in, snake, kebab, camel string
+	}{
+		{"UserID", "user_id", "user-id", "userID"},
+		{"HTTPServer", "http_server", "http-server", "httpServer"},
+		{"ID", "id", "id", "id"},
+		{"first_name", "first_name", "first-name", "firstName"},
+		{"Address2", "address2", "address2", "address2"},
+		{"name_ärger", "name_ärger", "name-ärger", "nameÄrger"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.snake, keyFuncs["snake"](tt.in), tt.in)
+		assert.Equal(t, tt.kebab, keyFuncs["kebab"](tt.in), tt.in)
+		assert.Equal(t, tt.camel, keyFuncs["camel"](tt.in), tt.in)
+	}
+}
+
+func TestMapper_IncludeExclude(t *testing.T) {
+	v := specUser{UserID: 1, Address: specAddress{City: "Paris", ZIP: "75001"}}
+	got := New(Include("UserID", "address"), Exclude("address.zip")).ToMap(v)
+	assert.Equal(t, map[string]any{"UserID": 1, "address": map[string]any{"city": "Paris"}}, got)
+
+	got = New(Include("address.city", "address")).ToMap(v)
+	assert.Equal(t, map[string]any{"address": map[string]any{"city": "Paris", "zip": "75001"}}, got)
+}
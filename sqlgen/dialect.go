@@ -0,0 +1,40 @@
+package sqlgen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect is the SQL dialect of the generated statements.
+type Dialect int
+
+const (
+	// Postgres uses $N placeholders and double-quoted identifiers.
+	Postgres Dialect = iota
+	// MySQL uses ? placeholders and backquoted identifiers.
+	MySQL
+	// SQLite uses ? placeholders and double-quoted identifiers.
+	SQLite
+)
+
+// placeholder returns the placeholder of the n-th argument, starting with 1.
+func (d Dialect) placeholder(n int) string {
+	if d == Postgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// quote returns the quoted identifier.  Qualified names, i.e. "public.users",
+// are quoted part by part.
+func (d Dialect) quote(ident string) string {
+	q := `"`
+	if d == MySQL {
+		q = "`"
+	}
+	parts := strings.Split(ident, ".")
+	for i, p := range parts {
+		parts[i] = q + strings.ReplaceAll(p, q, q+q) + q
+	}
+	return strings.Join(parts, ".")
+}
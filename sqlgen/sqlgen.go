@@ -0,0 +1,71 @@
+// Package sqlgen contains SQL helpers built on the tagops "db" tag
+// introspection.
+//
+// The column options in the tag describe the column, see tagops.DBColumn:
+//
+//   - pk marks the primary key column, or a part of it;
+//   - auto marks the column generated by the database, i.e. serial;
+//   - generated marks the column computed by the database, i.e. with a
+//     default value, that is never written;
+//   - type=T declares the column type, i.e. "type=varchar(255)".
+//
+// For example:
+//
+//	type User struct {
+//		ID      int64     `db:"id,pk,auto"`
+//		Name    string    `db:"name,type=varchar(255)"`
+//		Created time.Time `db:"created_at,generated"`
+//	}
+package sqlgen
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"time"
+
+	"github.com/rusq/tagops"
+)
+
+// Tag is the struct tag used for column names.
+const Tag = "db"
+
+// mapper is the Mapper used to get the columns of a model.  Embedded structs
+// are flattened, named nested structs are columns.
+var mapper = tagops.New(tagops.Tag(Tag))
+
+// column is a column of a model.
+type column struct {
+	name string
+	// field is the Go field value.
+	field reflect.Value
+	// opts are the tag options.
+	opts []string
+	// db are the column options from the tag.
+	db tagops.DBColumn
+}
+
+// columns returns the columns of the model a: the fields of the struct in
+// order, followed by the promoted fields of embedded structs.
+func columns(a any) []column {
+	var cols []column
+	for fi, fv := range mapper.TopLevelFields(a) {
+		cols = append(cols, column{name: fi.Key, field: fv, opts: fi.Options, db: fi.DBColumn()})
+	}
+	return cols
+}
+
+// written returns true if the column is written by INSERT.  The columns
+// generated by the database are written only if the value is set.
+func (c column) written() bool {
+	return !c.db.Generated && !(c.db.Auto && c.field.IsZero())
+}
+
+// updated returns true if the column is written by UPDATE.
+func (c column) updated() bool {
+	return !c.db.Generated && !c.db.Auto && !c.db.PK
+}
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
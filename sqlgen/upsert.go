@@ -0,0 +1,113 @@
+package sqlgen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rusq/tagops"
+)
+
+// Upsert is Postgres.Upsert.
+func Upsert(table string, v any, conflictCols []string) (string, []any, error) {
+	return Postgres.Upsert(table, v, conflictCols)
+}
+
+// Upsert returns the statement that inserts the struct v into the table, or
+// updates the existing row, if it conflicts on conflictCols, i.e. the
+// primary key.  If conflictCols is empty, the pk columns are used.  All
+// db-tagged fields except the conflict columns are updated.  The arguments
+// are the field values in the order of columns, the promoted fields of
+// embedded structs go last.
+//
+// The generated columns are not written, the auto columns are inserted only
+// if the value is set, and neither they, nor the pk columns are updated.
+//
+// MySQL does not support the conflict target, the row conflicts on any of
+// the unique keys, and conflictCols only exclude the columns from the
+// update.  The new values are referenced with the row alias, that requires
+// MySQL 8.0.19 or later, and if there is nothing to update, the first column
+// is assigned to itself.
+func (d Dialect) Upsert(table string, v any, conflictCols []string) (string, []any, error) {
+	cols := columns(v)
+	if len(cols) == 0 {
+		return "", nil, fmt.Errorf("upsert %s: %w: %T has no columns", table, tagops.ErrNotStruct, v)
+	}
+	if len(conflictCols) == 0 {
+		conflictCols = pkColumns(cols)
+	}
+	if d != MySQL && len(conflictCols) == 0 {
+		return "", nil, errors.New("upsert " + table + ": no conflict columns")
+	}
+	var (
+		names   []string
+		holders []string
+		args    []any
+		update  []string
+	)
+	for _, col := range cols {
+		if !col.written() {
+			continue
+		}
+		args = append(args, col.field.Interface())
+		names = append(names, d.quote(col.name))
+		holders = append(holders, d.placeholder(len(args)))
+		if col.updated() && !slices.Contains(conflictCols, col.name) {
+			update = append(update, col.name)
+		}
+	}
+	for _, cc := range conflictCols {
+		if !slices.ContainsFunc(cols, func(c column) bool { return c.name == cc }) {
+			return "", nil, fmt.Errorf("upsert %s: conflict column %q: %w", table, cc, tagops.ErrFieldNotFound)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES (%s)", d.quote(table), strings.Join(names, ", "), strings.Join(holders, ", "))
+	if d == MySQL {
+		if len(update) == 0 {
+			// no-op update of an inserted column, as MySQL has no DO
+			// NOTHING.
+			fmt.Fprintf(&sb, " ON DUPLICATE KEY UPDATE %[1]s = %[1]s", names[0])
+			return sb.String(), args, nil
+		}
+		sb.WriteString(" AS new ON DUPLICATE KEY UPDATE ")
+		for i, col := range update {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "%[1]s = new.%[1]s", d.quote(col))
+		}
+		return sb.String(), args, nil
+	}
+
+	quoted := make([]string, len(conflictCols))
+	for i, cc := range conflictCols {
+		quoted[i] = d.quote(cc)
+	}
+	fmt.Fprintf(&sb, " ON CONFLICT (%s) DO ", strings.Join(quoted, ", "))
+	if len(update) == 0 {
+		sb.WriteString("NOTHING")
+		return sb.String(), args, nil
+	}
+	sb.WriteString("UPDATE SET ")
+	for i, col := range update {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%[1]s = excluded.%[1]s", d.quote(col))
+	}
+	return sb.String(), args, nil
+}
+
+// pkColumns returns the names of the pk columns.
+func pkColumns(cols []column) []string {
+	var pk []string
+	for _, col := range cols {
+		if col.db.PK {
+			pk = append(pk, col.name)
+		}
+	}
+	return pk
+}
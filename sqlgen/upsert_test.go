@@ -0,0 +1,141 @@
+package sqlgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusq/tagops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type account struct {
+	ID    int64  `db:"id"`
+	Email string `db:"email"`
+	Name  string `db:"name"`
+}
+
+func TestDialect_Upsert(t *testing.T) {
+	a := account{ID: 1, Email: "john@example.com", Name: "John"}
+	tests := []struct {
+		name         string
+		d            Dialect
+		table        string
+		v            any
+		conflictCols []string
+		want         string
+		wantErr      error
+	}{
+		{
+			name:         "postgres",
+			d:            Postgres,
+			table:        "public.accounts",
+			v:            a,
+			conflictCols: []string{"id"},
+			want:         `INSERT INTO "public"."accounts" ("id", "email", "name") VALUES ($1, $2, $3) ON CONFLICT ("id") DO UPDATE SET "email" = excluded."email", "name" = excluded."name"`,
+		},
+		{
+			name:         "sqlite, composite key",
+			d:            SQLite,
+			table:        "accounts",
+			v:            &a,
+			conflictCols: []string{"id", "email"},
+			want:         `INSERT INTO "accounts" ("id", "email", "name") VALUES (?, ?, ?) ON CONFLICT ("id", "email") DO UPDATE SET "name" = excluded."name"`,
+		},
+		{
+			name:         "mysql",
+			d:            MySQL,
+			table:        "accounts",
+			v:            a,
+			conflictCols: []string{"id"},
+			want:         "INSERT INTO `accounts` (`id`, `email`, `name`) VALUES (?, ?, ?) AS new ON DUPLICATE KEY UPDATE `email` = new.`email`, `name` = new.`name`",
+		},
+		{
+			name:         "nothing to update",
+			d:            Postgres,
+			table:        "accounts",
+			v:            a,
+			conflictCols: []string{"id", "email", "name"},
+			want:         `INSERT INTO "accounts" ("id", "email", "name") VALUES ($1, $2, $3) ON CONFLICT ("id", "email", "name") DO NOTHING`,
+		},
+		{
+			name:         "mysql, nothing to update",
+			d:            MySQL,
+			table:        "accounts",
+			v:            a,
+			conflictCols: []string{"id", "email", "name"},
+			want:         "INSERT INTO `accounts` (`id`, `email`, `name`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `id` = `id`",
+		},
+		{
+			name:         "unknown conflict column",
+			d:            Postgres,
+			table:        "accounts",
+			v:            a,
+			conflictCols: []string{"uuid"},
+			wantErr:      tagops.ErrFieldNotFound,
+		},
+		{
+			name:         "not a struct",
+			d:            Postgres,
+			table:        "accounts",
+			v:            42,
+			conflictCols: []string{"id"},
+			wantErr:      tagops.ErrNotStruct,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := tt.d.Upsert(tt.table, tt.v, tt.conflictCols)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, []any{int64(1), "john@example.com", "John"}, args)
+		})
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	_, _, err := Upsert("accounts", account{}, nil)
+	assert.Error(t, err)
+}
+
+type product struct {
+	ID      int64     `db:"id,pk,auto"`
+	SKU     string    `db:"sku"`
+	Price   float64   `db:"price,type=numeric(10,2)"`
+	Created time.Time `db:"created_at,generated"`
+}
+
+func TestDialect_Upsert_columnOptions(t *testing.T) {
+	t.Run("auto column is not set", func(t *testing.T) {
+		got, args, err := Postgres.Upsert("products", product{SKU: "a-1", Price: 9.99}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, `INSERT INTO "products" ("sku", "price") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "sku" = excluded."sku", "price" = excluded."price"`, got)
+		assert.Equal(t, []any{"a-1", 9.99}, args)
+	})
+	t.Run("auto column is set", func(t *testing.T) {
+		got, args, err := MySQL.Upsert("products", product{ID: 7, SKU: "a-1"}, []string{"sku"})
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO `products` (`id`, `sku`, `price`) VALUES (?, ?, ?) AS new ON DUPLICATE KEY UPDATE `price` = new.`price`", got)
+		assert.Equal(t, []any{int64(7), "a-1", 0.0}, args)
+	})
+	t.Run("mysql, nothing to update without the auto column", func(t *testing.T) {
+		got, _, err := MySQL.Upsert("products", product{SKU: "a-1"}, []string{"sku", "price"})
+		require.NoError(t, err)
+		assert.Equal(t, "INSERT INTO `products` (`sku`, `price`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `sku` = `sku`", got)
+	})
+}
+
+func TestProduct_generatedIsReadBack(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var p product
+	require.NoError(t, mapper.FromMap(map[string]any{"id": int64(1), "sku": "a-1", "created_at": created}, &p))
+	assert.Equal(t, product{ID: 1, SKU: "a-1", Created: created}, p)
+
+	got, _, err := Postgres.Upsert("products", p, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, got, "created_at")
+}
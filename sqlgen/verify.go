@@ -0,0 +1,258 @@
+package sqlgen
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// MismatchKind is the kind of the difference between the model and the
+// table.
+type MismatchKind int
+
+const (
+	// MissingColumn indicates that the model field has no table column.
+	MissingColumn MismatchKind = iota + 1
+	// MissingField indicates that the table column has no model field.
+	MissingField
+	// TypeMismatch indicates that the field type is not compatible with
+	// the column data type.
+	TypeMismatch
+)
+
+func (k MismatchKind) String() string {
+	switch k {
+	case MissingColumn:
+		return "missing column"
+	case MissingField:
+		return "missing field"
+	case TypeMismatch:
+		return "type mismatch"
+	}
+	return fmt.Sprintf("MismatchKind(%d)", int(k))
+}
+
+// Mismatch is a difference between the model and the table.
+type Mismatch struct {
+	Kind   MismatchKind
+	Column string
+	// GoType is the type of the model field, empty for MissingField.
+	GoType string
+	// DBType is the data type of the table column, empty for
+	// MissingColumn.
+	DBType string
+	// DeclType is the column type declared in the tag with the type
+	// option, if any.
+	DeclType string
+}
+
+func (m Mismatch) String() string {
+	switch m.Kind {
+	case MissingColumn:
+		return fmt.Sprintf("%s: %s (%s)", m.Kind, m.Column, m.GoType)
+	case MissingField:
+		return fmt.Sprintf("%s: %s (%s)", m.Kind, m.Column, m.DBType)
+	}
+	if m.DeclType != "" {
+		return fmt.Sprintf("%s: %s: %s (%s) vs %s", m.Kind, m.Column, m.GoType, m.DeclType, m.DBType)
+	}
+	return fmt.Sprintf("%s: %s: %s vs %s", m.Kind, m.Column, m.GoType, m.DBType)
+}
+
+// Verify is Postgres.Verify.
+func Verify(db *sql.DB, table string, model any) ([]Mismatch, error) {
+	return Postgres.Verify(db, table, model)
+}
+
+// Verify compares the db-tagged fields of the struct model with the columns
+// of the table, as reported by information_schema, or by the table_info
+// pragma in SQLite, and returns the differences ordered by column name.  The
+// table may be qualified with the schema, i.e. "public.users", otherwise the
+// current schema is used, i.e. the database in MySQL.  Field types are
+// checked against the column data types loosely, i.e. any integer type
+// matches any integer column, and fields of types implementing driver.Valuer
+// are not checked.  If the column type is declared with the type tag option,
+// it is compared with the column data type instead, ignoring the length and
+// precision, and the common aliases, i.e. "varchar" is "character varying".
+//
+// It returns an error if the table does not exist, or can not be queried.
+func (d Dialect) Verify(db *sql.DB, table string, model any) ([]Mismatch, error) {
+	dbCols, err := d.tableColumns(db, table)
+	if err != nil {
+		return nil, err
+	}
+	var out []Mismatch
+	seen := make(map[string]bool)
+	for _, col := range columns(model) {
+		seen[col.name] = true
+		goType := col.field.Type()
+		dbType, ok := dbCols[col.name]
+		if !ok {
+			out = append(out, Mismatch{Kind: MissingColumn, Column: col.name, GoType: goType.String()})
+			continue
+		}
+		if decl := col.db.Type; decl != "" {
+			if baseType(decl) != baseType(dbType) {
+				out = append(out, Mismatch{Kind: TypeMismatch, Column: col.name, GoType: goType.String(), DBType: dbType, DeclType: decl})
+			}
+			continue
+		}
+		if !compatible(goType, dbType) {
+			out = append(out, Mismatch{Kind: TypeMismatch, Column: col.name, GoType: goType.String(), DBType: dbType})
+		}
+	}
+	for name, dbType := range dbCols {
+		if !seen[name] {
+			out = append(out, Mismatch{Kind: MissingField, Column: name, DBType: dbType})
+		}
+	}
+	slices.SortFunc(out, func(a, b Mismatch) int {
+		return strings.Compare(a.Column, b.Column)
+	})
+	return out, nil
+}
+
+// tableColumns returns the column name to data type map of the table.
+func (d Dialect) tableColumns(db *sql.DB, table string) (map[string]string, error) {
+	query, args := d.columnsQuery(table)
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("verify %s: %w", table, err)
+	}
+	defer rows.Close()
+	cols := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return nil, fmt.Errorf("verify %s: %w", table, err)
+		}
+		cols[name] = strings.ToLower(dataType)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("verify %s: %w", table, err)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("verify %s: %w", table, errNoTable)
+	}
+	return cols, nil
+}
+
+var errNoTable = errors.New("table not found")
+
+// columnsQuery returns the query, that selects the column names and data
+// types of the table, and its arguments.
+func (d Dialect) columnsQuery(table string) (string, []any) {
+	schema, name, qualified := strings.Cut(table, ".")
+	if !qualified {
+		name = table
+	}
+	if d == SQLite {
+		if qualified {
+			return "SELECT name, type FROM pragma_table_info(?, ?)", []any{name, schema}
+		}
+		return "SELECT name, type FROM pragma_table_info(?)", []any{name}
+	}
+	const query = "SELECT column_name, data_type FROM information_schema.columns WHERE table_schema = "
+	if qualified {
+		return query + d.placeholder(1) + " AND table_name = " + d.placeholder(2), []any{schema, name}
+	}
+	current := "current_schema()"
+	if d == MySQL {
+		current = "DATABASE()"
+	}
+	return query + current + " AND table_name = " + d.placeholder(1), []any{name}
+}
+
+// dbTypes lists the column data types compatible with each Go type family.
+var dbTypes = map[string][]string{
+	"int": {
+		"smallint", "integer", "int", "bigint", "tinyint", "mediumint",
+		"numeric", "decimal", "serial", "bigserial",
+	},
+	"float": {"real", "double precision", "double", "float", "numeric", "decimal"},
+	"string": {
+		"text", "character varying", "varchar", "character", "char", "uuid",
+		"tinytext", "mediumtext", "longtext", "json", "jsonb", "enum",
+	},
+	"bool":  {"boolean", "bool", "tinyint", "bit"},
+	"bytes": {"bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary"},
+	"time": {
+		"timestamp", "timestamp without time zone", "timestamp with time zone",
+		"timestamptz", "datetime", "date", "time",
+	},
+}
+
+// compatible returns true if the values of the Go type t can be stored in
+// the column of dbType.  Unknown types are compatible.
+func compatible(t reflect.Type, dbType string) bool {
+	fam := family(t)
+	if fam == "" {
+		return true
+	}
+	// strip the length or precision, i.e. "varchar(255)".
+	dbType, _, _ = strings.Cut(dbType, "(")
+	return slices.Contains(dbTypes[fam], strings.TrimSpace(dbType))
+}
+
+// typeAliases maps the data type aliases to one name.
+var typeAliases = map[string]string{
+	"character varying":           "varchar",
+	"character":                   "char",
+	"integer":                     "int",
+	"int4":                        "int",
+	"serial":                      "int",
+	"int8":                        "bigint",
+	"bigserial":                   "bigint",
+	"int2":                        "smallint",
+	"boolean":                     "bool",
+	"double":                      "double precision",
+	"float8":                      "double precision",
+	"float4":                      "real",
+	"decimal":                     "numeric",
+	"timestamptz":                 "timestamp with time zone",
+	"timestamp without time zone": "timestamp",
+}
+
+// baseType returns the data type t without the length or precision, in
+// lower case, with the alias resolved.
+func baseType(t string) string {
+	t, _, _ = strings.Cut(strings.ToLower(t), "(")
+	t = strings.TrimSpace(t)
+	if alias, ok := typeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// family returns the type family of the Go type t, or an empty string if
+// it is unknown.
+func family(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "time"
+	}
+	if t.Implements(valuerType) || reflect.PointerTo(t).Implements(valuerType) {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+	}
+	return ""
+}
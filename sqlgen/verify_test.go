@@ -0,0 +1,128 @@
+package sqlgen
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Base struct {
+	ID int64 `db:"id"`
+}
+
+type user struct {
+	Base
+	Name    string         `db:"name"`
+	Email   sql.NullString `db:"email"`
+	Active  bool           `db:"active"`
+	Score   float64        `db:"score"`
+	Created time.Time      `db:"created_at"`
+	Note    string         `db:"note"`
+	Skipped string         `db:"-"`
+}
+
+func TestVerify(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"column_name", "data_type"}
+	t.Run("mismatches", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2")).
+			WithArgs("public", "users").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("id", "bigint").
+				AddRow("name", "character varying").
+				AddRow("email", "text").
+				AddRow("active", "integer").
+				AddRow("score", "NUMERIC").
+				AddRow("created_at", "timestamp with time zone").
+				AddRow("updated_at", "timestamp with time zone"))
+
+		got, err := Verify(db, "public.users", user{})
+		require.NoError(t, err)
+		assert.Equal(t, []Mismatch{
+			{Kind: TypeMismatch, Column: "active", GoType: "bool", DBType: "integer"},
+			{Kind: MissingColumn, Column: "note", GoType: "string"},
+			{Kind: MissingField, Column: "updated_at", DBType: "timestamp with time zone"},
+		}, got)
+	})
+	t.Run("no mismatches", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE table_schema = DATABASE() AND table_name = ?")).
+			WithArgs("o'brien").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("id", "int(11)").
+				AddRow("name", "varchar(255)").
+				AddRow("email", "varchar(255)").
+				AddRow("active", "tinyint(1)").
+				AddRow("score", "double").
+				AddRow("created_at", "datetime").
+				AddRow("note", "text"))
+
+		got, err := MySQL.Verify(db, "o'brien", &user{})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("table not found", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("WHERE table_schema = current_schema() AND table_name = $1")).
+			WithArgs("nope").
+			WillReturnRows(sqlmock.NewRows(columns))
+		_, err := Verify(db, "nope", user{})
+		assert.ErrorIs(t, err, errNoTable)
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerify_declaredType(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("information_schema").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type"}).
+			AddRow("id", "integer").
+			AddRow("sku", "text").
+			AddRow("price", "real").
+			AddRow("created_at", "timestamp without time zone"))
+
+	got, err := Verify(db, "products", product{})
+	require.NoError(t, err)
+	assert.Equal(t, []Mismatch{
+		{Kind: TypeMismatch, Column: "price", GoType: "float64", DBType: "real", DeclType: "numeric(10,2)"},
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialect_columnsQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		d         Dialect
+		table     string
+		wantQuery string
+		wantArgs  []any
+	}{
+		{"postgres", Postgres, "users", "SELECT column_name, data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1", []any{"users"}},
+		{"postgres qualified", Postgres, "app.users", "SELECT column_name, data_type FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2", []any{"app", "users"}},
+		{"mysql", MySQL, "users", "SELECT column_name, data_type FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?", []any{"users"}},
+		{"sqlite", SQLite, "users", "SELECT name, type FROM pragma_table_info(?)", []any{"users"}},
+		{"sqlite qualified", SQLite, "main.users", "SELECT name, type FROM pragma_table_info(?, ?)", []any{"users", "main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.d.columnsQuery(tt.table)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestMismatch_String(t *testing.T) {
+	assert.Equal(t, "missing column: note (string)", Mismatch{Kind: MissingColumn, Column: "note", GoType: "string"}.String())
+	assert.Equal(t, "type mismatch: active: bool vs integer", Mismatch{Kind: TypeMismatch, Column: "active", GoType: "bool", DBType: "integer"}.String())
+	assert.Equal(t, "type mismatch: price: float64 (numeric(10,2)) vs real", Mismatch{Kind: TypeMismatch, Column: "price", GoType: "float64", DBType: "real", DeclType: "numeric(10,2)"}.String())
+}
@@ -0,0 +1,95 @@
+package sqlgen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rusq/tagops"
+)
+
+// operators maps the tag options to the comparison operators of Where.
+var operators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"lt":   "<",
+	"lte":  "<=",
+	"gt":   ">",
+	"gte":  ">=",
+	"like": "LIKE",
+	"in":   "IN",
+}
+
+// Where is Postgres.Where.
+func Where(filter any) (clause string, args []any, err error) {
+	return Postgres.Where(filter)
+}
+
+// Where returns the conditions for the non-empty db-tagged fields of the
+// struct filter, joined with AND, and the arguments for them.  The clause
+// does not include the WHERE keyword, and it is empty if all fields are
+// empty.  It returns an error if the filter is not a struct or a pointer to
+// it, i.e. nil, so that the missing filter does not match all rows.
+//
+// The fields are compared for equality, the operator can be changed with the
+// tag option: eq, ne, lt, lte, gt, gte, like or in, i.e.
+// `db:"created_at,gte"`.  Slices are compared with IN, and an empty non-nil
+// slice matches nothing, the condition is FALSE.  Pointers to zero values
+// are not empty, so they can be used to filter by zero values, i.e. *bool.
+// Nil pointers, at any level of indirection, are empty.
+func (d Dialect) Where(filter any) (clause string, args []any, err error) {
+	if !isStruct(filter) {
+		return "", nil, fmt.Errorf("where: %w: %T", tagops.ErrNotStruct, filter)
+	}
+	var conds []string
+	for _, col := range columns(filter) {
+		fv := col.field
+		for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if col.field.IsZero() || fv.Kind() == reflect.Ptr {
+			continue
+		}
+		op := "="
+		for _, opt := range col.opts {
+			if o, ok := operators[opt]; ok {
+				op = o
+			}
+		}
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			op = "IN"
+		}
+		if op != "IN" {
+			args = append(args, fv.Interface())
+			conds = append(conds, d.quote(col.name)+" "+op+" "+d.placeholder(len(args)))
+			continue
+		}
+		if fv.Kind() != reflect.Slice && fv.Kind() != reflect.Array {
+			// a single value
+			args = append(args, fv.Interface())
+			conds = append(conds, d.quote(col.name)+" IN ("+d.placeholder(len(args))+")")
+			continue
+		}
+		if fv.Len() == 0 {
+			// IN () is not valid SQL, and nothing matches the empty list.
+			conds = append(conds, "FALSE")
+			continue
+		}
+		holders := make([]string, fv.Len())
+		for i := range fv.Len() {
+			args = append(args, fv.Index(i).Interface())
+			holders[i] = d.placeholder(len(args))
+		}
+		conds = append(conds, d.quote(col.name)+" IN ("+strings.Join(holders, ", ")+")")
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// isStruct returns true if v is a struct or a non-nil pointer to it.
+func isStruct(v any) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Struct
+}
@@ -0,0 +1,121 @@
+package sqlgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusq/tagops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type userFilter struct {
+	Name    string    `db:"name,like"`
+	Status  []string  `db:"status"`
+	Active  *bool     `db:"active"`
+	Since   time.Time `db:"created_at,gte"`
+	Role    string    `db:"role,ne"`
+	Team    int       `db:"team_id,in"`
+	Ignored string    `db:"-"`
+}
+
+func TestDialect_Where(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	inactive := false
+	tests := []struct {
+		name       string
+		d          Dialect
+		filter     any
+		wantClause string
+		wantArgs   []any
+	}{
+		{
+			name:   "empty filter",
+			d:      Postgres,
+			filter: userFilter{Ignored: "x"},
+		},
+		{
+			name:       "all operators",
+			d:          Postgres,
+			filter:     &userFilter{Name: "jo%", Status: []string{"new", "active"}, Active: &inactive, Since: since, Role: "admin", Team: 7},
+			wantClause: `"name" LIKE $1 AND "status" IN ($2, $3) AND "active" = $4 AND "created_at" >= $5 AND "role" <> $6 AND "team_id" IN ($7)`,
+			wantArgs:   []any{"jo%", "new", "active", false, since, "admin", 7},
+		},
+		{
+			name:       "empty list",
+			d:          Postgres,
+			filter:     userFilter{Name: "jo%", Status: []string{}},
+			wantClause: `"name" LIKE $1 AND FALSE`,
+			wantArgs:   []any{"jo%"},
+		},
+		{
+			name:       "mysql",
+			d:          MySQL,
+			filter:     userFilter{Name: "jo%", Team: 7},
+			wantClause: "`name` LIKE ? AND `team_id` IN (?)",
+			wantArgs:   []any{"jo%", 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, args, err := tt.d.Where(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClause, clause)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestWhere_promoted(t *testing.T) {
+	type Base struct {
+		ID int64 `db:"id,gt"`
+	}
+	type filter struct {
+		Base
+		Name string `db:"name"`
+	}
+	clause, args, err := Where(filter{Base{10}, "John"})
+	require.NoError(t, err)
+	assert.Equal(t, `"name" = $1 AND "id" > $2`, clause)
+	assert.Equal(t, []any{"John", int64(10)}, args)
+}
+
+func TestWhere_tagRules(t *testing.T) {
+	type Base struct {
+		ID   int64  `db:"id,gt"`
+		Name string `db:"name,like"`
+	}
+	type Hidden struct {
+		Secret string `db:"secret"`
+	}
+	type filter struct {
+		Base   `db:"base"`
+		Hidden `db:"-"`
+		Name   string `db:"name"`
+	}
+	// the tagged embedded struct is a named column, the "-" one is ignored,
+	// so Base.Name does not take part in the filter.
+	clause, args, err := Where(filter{Base{ID: 10, Name: "x%"}, Hidden{"s"}, "John"})
+	require.NoError(t, err)
+	assert.Equal(t, `"base" = $1 AND "name" = $2`, clause)
+	assert.Equal(t, []any{Base{ID: 10, Name: "x%"}, "John"}, args)
+}
+
+func TestWhere_nilPointer(t *testing.T) {
+	type filter struct {
+		Active **bool `db:"active"`
+		Name   string `db:"name"`
+	}
+	var active *bool
+	clause, args, err := Where(filter{Active: &active, Name: "John"})
+	require.NoError(t, err)
+	assert.Equal(t, `"name" = $1`, clause)
+	assert.Equal(t, []any{"John"}, args)
+}
+
+func TestWhere_errors(t *testing.T) {
+	for _, filter := range []any{nil, (*userFilter)(nil), 42, map[string]any{"name": "x"}} {
+		_, _, err := Where(filter)
+		assert.ErrorIs(t, err, tagops.ErrNotStruct, "%T", filter)
+	}
+}
@@ -0,0 +1,42 @@
+package tagops
+
+import (
+	"context"
+	"fmt"
+)
+
+// StreamMaps converts the structs received from ch with the Mapper
+// configured with options opts, and passes the maps to sink, see
+// Mapper.StreamMaps.
+func StreamMaps(ctx context.Context, ch <-chan any, sink func(map[string]any) error, opts ...Option) error {
+	return New(opts...).StreamMaps(ctx, ch, sink)
+}
+
+// StreamMaps converts the structs received from ch with ToMapCtx, and passes
+// the maps to sink, one at a time, i.e. to write them to a websocket or a
+// server-sent events stream.  The next value is not received from ch until
+// sink returns, so a slow sink applies the backpressure to the producer.
+//
+// It returns nil when ch is closed, the context error if ctx is cancelled,
+// or the first conversion or sink error, with the number of the record.
+// The remaining values are not drained from ch, so the producer should
+// watch ctx as well.
+func (m Mapper) StreamMaps(ctx context.Context, ch <-chan any, sink func(map[string]any) error) error {
+	for n := 1; ; n++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case a, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			mp, err := m.ToMapCtx(ctx, a)
+			if err != nil {
+				return fmt.Errorf("record %d: %w", n, err)
+			}
+			if err := sink(mp); err != nil {
+				return fmt.Errorf("record %d: %w", n, err)
+			}
+		}
+	}
+}
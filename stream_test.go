@@ -0,0 +1,57 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamMaps(t *testing.T) {
+	type S struct {
+		ID int `json:"id"`
+	}
+	feed := func(vs ...any) <-chan any {
+		ch := make(chan any, len(vs))
+		for _, v := range vs {
+			ch <- v
+		}
+		close(ch)
+		return ch
+	}
+	t.Run("all records", func(t *testing.T) {
+		var got []map[string]any
+		err := StreamMaps(context.Background(), feed(S{1}, &S{2}), func(mp map[string]any) error {
+			got = append(got, mp)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []map[string]any{{"id": 1}, {"id": 2}}, got)
+	})
+	t.Run("conversion error", func(t *testing.T) {
+		err := StreamMaps(context.Background(), feed(S{1}, 42), func(map[string]any) error { return nil })
+		assert.ErrorIs(t, err, ErrNotStruct)
+		assert.ErrorContains(t, err, "record 2")
+	})
+	t.Run("sink error", func(t *testing.T) {
+		errSink := errors.New("closed")
+		var n int
+		err := StreamMaps(context.Background(), feed(S{1}, S{2}), func(map[string]any) error {
+			n++
+			return errSink
+		})
+		assert.ErrorIs(t, err, errSink)
+		assert.Equal(t, 1, n)
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan any)
+		go func() { ch <- S{1} }()
+		err := StreamMaps(ctx, ch, func(map[string]any) error {
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -23,7 +23,9 @@ func PrepareToMap(opts ...Option) func(any) map[string]any {
 // ToMap converts an argument a which should be some struct type, to a
 // map[tag]value.  If omitempty is specified, fields having empty values and
 // tag option "omitempty" are skipped.  If flatten is true, all nested
-// non-anonymous structs are flattened into the parent map.
+// non-anonymous structs are flattened into the parent map.  Fields with the
+// omitif tag are skipped when the condition on the sibling field holds, i.e.
+// `omitif:"status==draft"`, regardless of omitempty.
 func ToMap(a any, tag string, omitempty bool, flatten bool) map[string]any {
 	m := Mapper{
 		Tag:       tag,
@@ -34,7 +36,8 @@ func ToMap(a any, tag string, omitempty bool, flatten bool) map[string]any {
 }
 
 // Tags returns a sorted list of names in tags, given a struct object.  The
-// empty fields are included and the map is flattened.
+// empty fields are included and the map is flattened.  The returned slice is
+// never nil.
 func Tags(a any, tag string) []string {
 	m := Mapper{
 		Tag:       tag,
@@ -55,3 +58,65 @@ func Values(a any, tag string) ([]any, error) {
 	}
 	return m.Values(a)
 }
+
+// FromMap populates the struct pointed to by a with values from the map src,
+// it is the reverse of ToMap.  Map keys are matched to struct field tags.  If
+// flatten is true, nested non-anonymous structs are populated from the same
+// map, otherwise they are populated from the nested map[string]any.  Slices,
+// arrays and string-keyed maps of structs are populated from slices and maps
+// of nested map[string]any.  Keys that are not present in src leave the
+// corresponding fields untouched.  Pointer fields are allocated when the map
+// has a value, and set to nil when the map value is nil, so that an absent key
+// is distinguished from null.  Renamed fields may list their historic keys in
+// the alias tag, i.e. `json:"username" alias:"user_name,login"`, that are
+// used when the key is absent.  Fields with the readonly tag option, i.e.
+// `json:"id,readonly"`, are not populated, and fields with the writeonly
+// option are not written by ToMap.
+// Values are converted with registered converters (see RegisterConverter),
+// numeric values are converted between numeric types if they fit.
+func FromMap(src map[string]any, a any, tag string, flatten bool) error {
+	m := Mapper{
+		Tag:     tag,
+		Flatten: flatten,
+	}
+	return m.FromMap(src, a)
+}
+
+// ToMultiMap converts the struct a to maps for each of the tags in one pass,
+// avoiding a separate reflection pass per tag.  The result is keyed by tag.
+// Empty fields are included, and nested structs are not flattened.
+func ToMultiMap(a any, tags ...string) map[string]map[string]any {
+	return New().ToMultiMap(a, tags...)
+}
+
+// IndexBy returns the map of the elements of the slice of structs, keyed by
+// the value of the field with the json tag name.  See Mapper.IndexBy.
+func IndexBy(slice any, tagName string) (map[any]any, error) {
+	return New().IndexBy(slice, tagName)
+}
+
+// GroupBy returns the map of the elements of the slice of structs, grouped by
+// the value of the field with the json tag name.  See Mapper.GroupBy.
+func GroupBy(slice any, tagName string) (map[any][]any, error) {
+	return New().GroupBy(slice, tagName)
+}
+
+// Columns returns the columns of the slice of structs, keyed by the json tag
+// name.  See Mapper.Columns.
+func Columns(slice any) (map[string][]any, error) {
+	return New().Columns(slice)
+}
+
+// Pluck returns the values of the field with the json tag name of each
+// element of the slice of structs.  See Mapper.Pluck.
+func Pluck(slice any, tagName string) ([]any, error) {
+	return New().Pluck(slice, tagName)
+}
+
+// Filter returns the elements of the slice of structs, whose field with the
+// json tag name satisfies the predicate pred.  It returns nil on error, use
+// Mapper.Filter to get the error.
+func Filter(slice any, tagName string, pred func(any) bool) any {
+	out, _ := New().Filter(slice, tagName, pred)
+	return out
+}
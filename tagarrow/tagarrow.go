@@ -0,0 +1,207 @@
+// Package tagarrow converts slices of tagged structs into Arrow record batches
+// and Parquet files, so that the analytics exports do not need the JSON
+// intermediate step.
+//
+// The record has a column per key of the maps returned by tagops.Mapper.ToMap
+// for the elements of the slice, in the sorted order, as in Tags, and a row
+// per element.  The column types are inferred from the values:
+//
+//   - bool is Boolean;
+//   - signed integers are Int64, unsigned integers are Uint64;
+//   - floats are Float64;
+//   - strings are String, byte slices are Binary;
+//   - time.Time is the nanosecond Timestamp in UTC.
+//
+// Nil values, and the values missing in the element map, i.e. omitted with
+// tagops.Omitempty, are nulls.  The column without values has the Null type.
+// Other values, i.e. nested structs, can not be converted, they should be
+// flattened with tagops.Flatten or skipped with the tag.
+package tagarrow
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/rusq/tagops"
+)
+
+// ErrUnsupportedType is returned when the column has a value without the
+// Arrow type, or values of different types.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Record converts the slice of structs into the Arrow record batch, allocated
+// with mem, see the package documentation.  The structs are mapped by the
+// Mapper with the options opts, i.e. tagops.Tag.  The caller must release the
+// record.
+func Record(mem memory.Allocator, slice any, opts ...tagops.Option) (arrow.RecordBatch, error) {
+	keys, cols, err := columns(tagops.New(opts...), slice)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]arrow.Field, len(keys))
+	for i, key := range keys {
+		typ, err := columnType(cols[key])
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", key, err)
+		}
+		fields[i] = arrow.Field{Name: key, Type: typ, Nullable: true}
+	}
+	b := array.NewRecordBuilder(mem, arrow.NewSchema(fields, nil))
+	defer b.Release()
+	for i, key := range keys {
+		fb := b.Field(i)
+		for _, v := range cols[key] {
+			appendValue(fb, deref(v))
+		}
+	}
+	return b.NewRecordBatch(), nil
+}
+
+// WriteParquet writes the slice of structs to w as the Parquet file with one
+// row group, see Record.  The file is written with the default Parquet and
+// Arrow writer properties, and the Arrow schema is stored in the file
+// metadata, so that the column types are restored when the file is read with
+// Arrow.
+func WriteParquet(w io.Writer, slice any, opts ...tagops.Option) error {
+	rec, err := Record(memory.DefaultAllocator, slice, opts...)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+	fw, err := pqarrow.NewFileWriter(rec.Schema(), w, nil, pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+	if err != nil {
+		return err
+	}
+	if err := fw.Write(rec); err != nil {
+		fw.Close()
+		return err
+	}
+	return fw.Close()
+}
+
+// columns returns the sorted keys of the maps of the slice elements, and the
+// values of each key, in the order of elements.  The values missing in the
+// element map are nil.
+func columns(m tagops.Mapper, slice any) ([]string, map[string][]any, error) {
+	sv := reflect.ValueOf(slice)
+	if sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("%w: %T", tagops.ErrNotSlice, slice)
+	}
+	rows := make([]map[string]any, sv.Len())
+	union := make(map[string]any)
+	for i := range rows {
+		mp, err := m.ToMapE(sv.Index(i).Interface())
+		if err != nil {
+			return nil, nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		for k := range mp {
+			union[k] = nil
+		}
+		rows[i] = mp
+	}
+	keys := tagops.Keys(union)
+	cols := make(map[string][]any, len(keys))
+	for _, key := range keys {
+		col := make([]any, len(rows))
+		for i, mp := range rows {
+			col[i] = mp[key]
+		}
+		cols[key] = col
+	}
+	return keys, cols, nil
+}
+
+// columnType returns the Arrow type of the column values.
+func columnType(values []any) (arrow.DataType, error) {
+	var typ arrow.DataType = arrow.Null
+	for _, v := range values {
+		rv := deref(v)
+		if !rv.IsValid() {
+			continue
+		}
+		vt, err := dataType(rv)
+		if err != nil {
+			return nil, err
+		}
+		if typ.ID() == arrow.NULL {
+			typ = vt
+		} else if !arrow.TypeEqual(typ, vt) {
+			return nil, fmt.Errorf("%w: mixed %s and %s values", ErrUnsupportedType, typ, vt)
+		}
+	}
+	return typ, nil
+}
+
+// dataType returns the Arrow type of the value v.
+func dataType(v reflect.Value) (arrow.DataType, error) {
+	if v.Type() == timeType {
+		return arrow.FixedWidthTypes.Timestamp_ns, nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case reflect.Float32, reflect.Float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case reflect.String:
+		return arrow.BinaryTypes.String, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return arrow.BinaryTypes.Binary, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, v.Type())
+}
+
+// appendValue appends the value v to the builder b of the column type, see
+// columnType.  The invalid value is appended as null.
+func appendValue(b array.Builder, v reflect.Value) {
+	if !v.IsValid() {
+		b.AppendNull()
+		return
+	}
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		b.Append(v.Bool())
+	case *array.Int64Builder:
+		b.Append(v.Int())
+	case *array.Uint64Builder:
+		b.Append(v.Uint())
+	case *array.Float64Builder:
+		b.Append(v.Float())
+	case *array.StringBuilder:
+		b.Append(v.String())
+	case *array.BinaryBuilder:
+		b.Append(v.Bytes())
+	case *array.TimestampBuilder:
+		b.Append(arrow.Timestamp(v.Interface().(time.Time).UnixNano()))
+	}
+}
+
+// deref returns the value of v, dereferencing the pointers.  It returns the
+// invalid value for nil.
+func deref(v any) reflect.Value {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
@@ -0,0 +1,144 @@
+package tagarrow
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/rusq/tagops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type event struct {
+	ID      int64     `json:"id"`
+	Name    string    `json:"name"`
+	Score   *float64  `json:"score"`
+	Count   uint16    `json:"count"`
+	OK      bool      `json:"ok"`
+	Payload []byte    `json:"payload"`
+	At      time.Time `json:"at"`
+	Note    *string   `json:"note"`
+}
+
+func testEvents() []event {
+	score := 0.5
+	return []event{
+		{ID: 1, Name: "a", Score: &score, Count: 3, OK: true, Payload: []byte("x"), At: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{ID: 2, Name: "b"},
+	}
+}
+
+func TestRecord(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	events := testEvents()
+	rec, err := Record(mem, events)
+	require.NoError(t, err)
+	defer rec.Release()
+
+	require.EqualValues(t, 2, rec.NumRows())
+	var names []string
+	for _, f := range rec.Schema().Fields() {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"at", "count", "id", "name", "note", "ok", "payload", "score"}, names)
+
+	col := func(name string) arrow.Array {
+		idx := rec.Schema().FieldIndices(name)
+		require.Len(t, idx, 1, name)
+		return rec.Column(idx[0])
+	}
+	assert.Equal(t, []int64{1, 2}, col("id").(*array.Int64).Int64Values())
+	assert.Equal(t, "b", col("name").(*array.String).Value(1))
+	scores := col("score").(*array.Float64)
+	assert.Equal(t, 0.5, scores.Value(0))
+	assert.True(t, scores.IsNull(1))
+	assert.Equal(t, []uint64{3, 0}, col("count").(*array.Uint64).Uint64Values())
+	assert.True(t, col("ok").(*array.Boolean).Value(0))
+	assert.Equal(t, []byte("x"), col("payload").(*array.Binary).Value(0))
+	assert.Equal(t, arrow.Timestamp(events[0].At.UnixNano()), col("at").(*array.Timestamp).Value(0))
+	assert.Equal(t, arrow.NULL, col("note").DataType().ID())
+	assert.Equal(t, 2, col("note").NullN())
+}
+
+func TestRecord_options(t *testing.T) {
+	type row struct {
+		C string `db:"c,omitempty"`
+		B string `db:"b"`
+		A string `db:"a"`
+	}
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rec, err := Record(mem, []row{{A: "a", B: "b"}, {C: "c"}}, tagops.Tag("db"), tagops.Omitempty())
+	require.NoError(t, err)
+	defer rec.Release()
+
+	assert.Equal(t, "a", rec.ColumnName(0))
+	assert.Equal(t, "b", rec.ColumnName(1))
+	assert.Equal(t, "c", rec.ColumnName(2))
+	c := rec.Column(2).(*array.String)
+	assert.True(t, c.IsNull(0), "omitted value is null")
+	assert.Equal(t, "c", c.Value(1))
+}
+
+func TestRecord_errors(t *testing.T) {
+	type nested struct {
+		Inner struct {
+			X int `json:"x"`
+		} `json:"inner"`
+	}
+	type mixed struct {
+		V any `json:"v"`
+	}
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	_, err := Record(mem, []nested{{}})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	_, err = Record(mem, []mixed{{V: 1}, {V: "x"}})
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+	_, err = Record(mem, 42)
+	assert.ErrorIs(t, err, tagops.ErrNotSlice)
+
+	rec, err := Record(mem, []nested{{}}, tagops.Flatten())
+	require.NoError(t, err, "flattened nested struct is supported")
+	defer rec.Release()
+	assert.Equal(t, "x", rec.ColumnName(0))
+}
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	events := testEvents()
+	require.NoError(t, WriteParquet(&buf, events))
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	tbl, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(buf.Bytes()), nil, pqarrow.ArrowReadProperties{}, mem)
+	require.NoError(t, err)
+	defer tbl.Release()
+
+	require.EqualValues(t, 2, tbl.NumRows())
+	require.EqualValues(t, 8, tbl.NumCols())
+	col := func(name string) arrow.Array {
+		idx := tbl.Schema().FieldIndices(name)
+		require.Len(t, idx, 1, name)
+		chunks := tbl.Column(idx[0]).Data().Chunks()
+		require.Len(t, chunks, 1, name)
+		return chunks[0]
+	}
+	assert.Equal(t, []int64{1, 2}, col("id").(*array.Int64).Int64Values())
+	assert.Equal(t, "a", col("name").(*array.String).Value(0))
+	assert.True(t, col("score").IsNull(1))
+	assert.Equal(t, arrow.Timestamp(events[0].At.UnixNano()), col("at").(*array.Timestamp).Value(0))
+	assert.Equal(t, 2, col("note").NullN())
+
+	assert.ErrorIs(t, WriteParquet(&buf, 42), tagops.ErrNotSlice)
+}
@@ -0,0 +1,47 @@
+package tagops
+
+import (
+	"reflect"
+)
+
+// TagMap returns the map of names in fromTag to names in toTag for every
+// field of the struct a, i.e. json name to db name.  Nested structs are
+// flattened.  Fields that are skipped in either of the tags are not included.
+// It returns nil if a is not a struct.
+func TagMap(a any, fromTag, toTag string) map[string]string {
+	v := reflect.ValueOf(a)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	out := make(map[string]string)
+	tagMap(out, v, fromTag, toTag)
+	return out
+}
+
+// tagMap populates out with fromTag to toTag name pairs for the struct value v.
+func tagMap(out map[string]string, v reflect.Value, fromTag, toTag string) {
+	typ := v.Type()
+	for i := range v.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		if isNested(field.Type) {
+			tagMap(out, fv, fromTag, toTag)
+			continue
+		}
+		from, err := tagName(field, fv, fromTag, false)
+		if err != nil {
+			continue
+		}
+		to, err := tagName(field, fv, toTag, false)
+		if err != nil {
+			continue
+		}
+		out[from] = to
+	}
+}
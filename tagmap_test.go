@@ -0,0 +1,62 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagMap(t *testing.T) {
+	type (
+		Address struct {
+			City string `json:"city" db:"city_name"`
+		}
+		Person struct {
+			ID       int     `json:"id" db:"person_id"`
+			Name     string  `json:"name"`
+			Password string  `json:"-" db:"password"`
+			Internal string  `json:"internal" db:"-"`
+			Address  Address `json:"address"`
+		}
+	)
+	type args struct {
+		a       any
+		fromTag string
+		toTag   string
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]string
+	}{
+		{
+			name: "json to db",
+			args: args{Person{}, "json", "db"},
+			want: map[string]string{
+				"id":   "person_id",
+				"name": "Name",
+				"city": "city_name",
+			},
+		},
+		{
+			name: "db to json",
+			args: args{&Person{}, "db", "json"},
+			want: map[string]string{
+				"person_id": "id",
+				"Name":      "name",
+				"city_name": "city",
+			},
+		},
+		{
+			name: "not a struct",
+			args: args{42, "json", "db"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TagMap(tt.args.a, tt.args.fromTag, tt.args.toTag)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
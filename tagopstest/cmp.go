@@ -0,0 +1,45 @@
+package tagopstest
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// IgnoreTagged returns a cmp.Option that ignores the struct fields, whose tag
+// tag has one of the names, i.e. IgnoreTagged("cmp", "-") ignores the fields
+// marked `cmp:"-"`, and IgnoreTagged("json", "id", "created_at") ignores the
+// fields with these json names.  The name is matched against the tag name and
+// the tag options, so that IgnoreTagged("json", "volatile") ignores
+// `json:"ts,volatile"`.  If names is empty, all fields that have the tag are
+// ignored.
+func IgnoreTagged(tag string, names ...string) cmp.Option {
+	return cmp.FilterPath(func(p cmp.Path) bool {
+		sf, ok := p.Last().(cmp.StructField)
+		if !ok {
+			return false
+		}
+		field := p.Index(-2).Type().Field(sf.Index())
+		return tagMatches(field, tag, names)
+	}, cmp.Ignore())
+}
+
+// tagMatches returns true if the tag of the field has one of the names, or if
+// names is empty, if the field has the tag.
+func tagMatches(field reflect.StructField, tag string, names []string) bool {
+	value, ok := field.Tag.Lookup(tag)
+	if !ok {
+		return false
+	}
+	if len(names) == 0 {
+		return true
+	}
+	for _, part := range strings.Split(value, ",") {
+		if slices.Contains(names, part) {
+			return true
+		}
+	}
+	return false
+}
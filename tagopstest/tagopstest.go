@@ -0,0 +1,88 @@
+// Package tagopstest provides test helpers that compare structs by their json
+// tag names, so that tests can ignore fields, such as timestamps or IDs,
+// without custom comparison options.
+package tagopstest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rusq/tagops"
+)
+
+// TestingT is the subset of testing.TB used by the assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// AssertEqualByTags asserts that the structs want and got have equal values
+// of the fields with the tag names tags.  Tag names may be dot-separated paths
+// to the fields of nested structs, i.e. "address.city", and the name of a
+// nested struct compares all of its fields.  If tags is empty, all fields are
+// compared.  On failure, it reports the differing fields by their tag paths,
+// and returns false.
+func AssertEqualByTags(t TestingT, want, got any, tags ...string) bool {
+	t.Helper()
+	return assertEqual(t, want, got, tags, func(path string) bool {
+		return len(tags) == 0 || matchesAny(path, tags)
+	})
+}
+
+// AssertEqualExceptTags is like AssertEqualByTags, but compares all fields,
+// except the fields with the tag names tags.
+func AssertEqualExceptTags(t TestingT, want, got any, tags ...string) bool {
+	t.Helper()
+	return assertEqual(t, want, got, tags, func(path string) bool {
+		return !matchesAny(path, tags)
+	})
+}
+
+func assertEqual(t TestingT, want, got any, tags []string, include func(path string) bool) bool {
+	t.Helper()
+	changes, err := tagops.Diff(want, got)
+	if err != nil {
+		t.Errorf("tagopstest: %s", err)
+		return false
+	}
+	if unknown := unknownTags(want, got, tags); len(unknown) > 0 {
+		t.Errorf("tagopstest: unknown tags: %s", strings.Join(unknown, ", "))
+		return false
+	}
+	var sb strings.Builder
+	for _, c := range changes {
+		if include(c.Path) {
+			fmt.Fprintf(&sb, "\t%s\n", c)
+		}
+	}
+	if sb.Len() == 0 {
+		return true
+	}
+	t.Errorf("Not equal (want → got):\n%s", sb.String())
+	return false
+}
+
+// matchesAny returns true if the path is one of the tags, or a path of the
+// field nested in one of them.
+func matchesAny(path string, tags []string) bool {
+	for _, tag := range tags {
+		if path == tag || strings.HasPrefix(path, tag+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownTags returns the tags that are not present in either of the maps of
+// want and got, they are most likely misspelt.
+func unknownTags(want, got any, tags []string) []string {
+	wm, _ := tagops.ToRecord(want)
+	gm, _ := tagops.ToRecord(got)
+	var unknown []string
+	for _, tag := range tags {
+		if !wm.Has(tag) && !gm.Has(tag) {
+			unknown = append(unknown, tag)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,117 @@
+package tagopstest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeT records the reported errors.
+type fakeT struct {
+	errs []string
+}
+
+func (*fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errs = append(f.errs, fmt.Sprintf(format, args...))
+}
+
+type address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type user struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Address address   `json:"address"`
+}
+
+var (
+	want = user{ID: 1, Name: "Bob", Created: time.Unix(1, 0), Address: address{City: "Paris", Zip: "75001"}}
+	got  = user{ID: 2, Name: "Bob", Created: time.Unix(2, 0), Address: address{City: "Paris", Zip: "75002"}}
+)
+
+func TestAssertEqualByTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		wantOK   bool
+		wantErrs []string
+	}{
+		{name: "equal fields", tags: []string{"name", "address.city"}, wantOK: true},
+		{
+			name:     "nested struct",
+			tags:     []string{"name", "address"},
+			wantErrs: []string{"Not equal (want → got):\n\taddress.zip: \"75001\" → \"75002\"\n"},
+		},
+		{
+			name:     "all fields",
+			wantErrs: []string{"Not equal (want → got):\n\taddress.zip: \"75001\" → \"75002\"\n\tcreated: 1970-01-01 00:00:01 +0000 UTC → 1970-01-01 00:00:02 +0000 UTC\n\tid: 1 → 2\n"},
+		},
+		{
+			name:     "unknown tag",
+			tags:     []string{"name", "nmae"},
+			wantErrs: []string{"tagopstest: unknown tags: nmae"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := new(fakeT)
+			assert.Equal(t, tt.wantOK, AssertEqualByTags(ft, utc(want), utc(got), tt.tags...))
+			assert.Equal(t, tt.wantErrs, ft.errs)
+		})
+	}
+}
+
+func TestAssertEqualExceptTags(t *testing.T) {
+	ft := new(fakeT)
+	assert.True(t, AssertEqualExceptTags(ft, want, got, "id", "created", "address.zip"))
+	assert.Empty(t, ft.errs)
+
+	assert.False(t, AssertEqualExceptTags(ft, want, got, "id", "created"))
+	assert.Equal(t, []string{"Not equal (want → got):\n\taddress.zip: \"75001\" → \"75002\"\n"}, ft.errs)
+
+	ft = new(fakeT)
+	assert.False(t, AssertEqualExceptTags(ft, want, 42))
+	assert.Len(t, ft.errs, 1)
+}
+
+// utc returns u with the created time in UTC, for the stable output.
+func utc(u user) user {
+	u.Created = u.Created.UTC()
+	return u
+}
+
+func TestIgnoreTagged(t *testing.T) {
+	type item struct {
+		ID      int       `json:"id" cmp:"-"`
+		Name    string    `json:"name"`
+		Updated time.Time `json:"updated,volatile"`
+		Nested  *item     `json:"nested"`
+	}
+	a := item{ID: 1, Name: "x", Updated: time.Unix(1, 0), Nested: &item{ID: 10, Name: "y"}}
+	b := item{ID: 2, Name: "x", Updated: time.Unix(2, 0), Nested: &item{ID: 20, Name: "y"}}
+	tests := []struct {
+		name string
+		opt  cmp.Option
+		want bool
+	}{
+		{"cmp dash", IgnoreTagged("cmp", "-"), false},
+		{"json names", IgnoreTagged("json", "id", "updated"), true},
+		{"json option", cmp.Options{IgnoreTagged("json", "volatile"), IgnoreTagged("cmp")}, true},
+		{"no match", IgnoreTagged("json", "name"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cmp.Equal(a, b, tt.opt))
+		})
+	}
+	assert.NotEmpty(t, cmp.Diff(a, b, IgnoreTagged("cmp", "-")))
+	assert.Empty(t, cmp.Diff(a, b, IgnoreTagged("cmp", "-"), IgnoreTagged("json", "volatile")))
+}
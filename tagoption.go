@@ -0,0 +1,112 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// OptionHandler defines the behavior of a custom tag option, registered with
+// RegisterOption.  Either of the functions may be nil, then the option has no
+// effect in that direction.
+type OptionHandler struct {
+	// Format converts the struct field value for ToMap.  Returning ErrSkip
+	// omits the field.
+	Format ConvertCtxFunc
+	// Parse converts the map value for FromMap.  The returned value is
+	// assigned to the field with the usual conversion rules.
+	Parse ConvertCtxFunc
+}
+
+// builtinOptions is the catalogue of tag options known to the package.
+// Options ending with "=" take an argument.
+var builtinOptions = []string{
+	fOmitEmpty,
+	fRequired,
+	fReadOnly, fWriteOnly,
+	fEnum,
+	fHex, fBase64,
+	fRFC3339, fUnix, fUnixMs, fTZPrefix,
+	fDurString, fDurSeconds, fDurMillis,
+	fPrecPrefix,
+	fPK, fAuto, fGenerated, fTypePrefix,
+}
+
+// customOptions holds registered option handlers, keyed by name.
+var customOptions sync.Map // map[string]OptionHandler
+
+// RegisterOption registers the handler for the custom tag option name, i.e.
+// "cents" for `json:"price,cents"`.  If a field has several custom options,
+// the first one in the tag is used.  Custom options take precedence over the
+// Mapper format settings and converters.
+//
+// It panics if the name is empty, contains a comma, or is one of the
+// built-in options.
+func RegisterOption(name string, handler OptionHandler) {
+	if name == "" || strings.Contains(name, tagsep) {
+		panic(fmt.Sprintf("tagops: RegisterOption: invalid option name %q", name))
+	}
+	if isBuiltinOption(name) {
+		panic(fmt.Sprintf("tagops: RegisterOption: %q is a built-in option", name))
+	}
+	customOptions.Store(name, handler)
+}
+
+// isBuiltinOption returns true if the option name is one of the built-in
+// options.
+func isBuiltinOption(name string) bool {
+	return slices.ContainsFunc(builtinOptions, func(opt string) bool {
+		if strings.HasSuffix(opt, "=") {
+			return strings.HasPrefix(name, opt)
+		}
+		return name == opt
+	})
+}
+
+// optionHandler returns the name and the handler of the first custom option
+// in opts.
+func optionHandler(opts []string) (string, OptionHandler, bool) {
+	for _, opt := range opts {
+		if h, ok := customOptions.Load(opt); ok {
+			return opt, h.(OptionHandler), true
+		}
+	}
+	return "", OptionHandler{}, false
+}
+
+// formatOption converts the value fv with the Format function of the custom
+// option in opts.  It returns false if there's none.  On error, the original
+// value is returned.
+func formatOption(ctx context.Context, fv reflect.Value, opts []string) (any, bool, error) {
+	name, h, ok := optionHandler(opts)
+	if !ok || h.Format == nil {
+		return nil, false, nil
+	}
+	val, err := h.Format(ctx, fv.Interface())
+	if errors.Is(err, ErrSkip) {
+		return nil, true, err
+	}
+	if err != nil {
+		return fv.Interface(), true, fmt.Errorf("option %s: %w", name, err)
+	}
+	return val, true, nil
+}
+
+// parseOption converts the map value sv with the Parse function of the
+// custom option in opts and assigns it to fv.  It returns false if there's
+// none.
+func parseOption(ctx context.Context, fv reflect.Value, sv any, opts []string) (bool, error) {
+	name, h, ok := optionHandler(opts)
+	if !ok || h.Parse == nil {
+		return false, nil
+	}
+	val, err := h.Parse(ctx, sv)
+	if err != nil {
+		return true, fmt.Errorf("option %s: %w", name, err)
+	}
+	return true, assign(ctx, fv, val)
+}
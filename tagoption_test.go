@@ -0,0 +1,65 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	RegisterOption("cents", OptionHandler{
+		Format: func(_ context.Context, v any) (any, error) {
+			f, ok := v.(float64)
+			if !ok {
+				return nil, errors.New("cents: not a float64")
+			}
+			return int64(math.Round(f * 100)), nil
+		},
+		Parse: func(_ context.Context, v any) (any, error) {
+			switch n := v.(type) {
+			case int:
+				return float64(n) / 100, nil
+			case int64:
+				return float64(n) / 100, nil
+			}
+			return nil, fmt.Errorf("cents: unexpected %T", v)
+		},
+	})
+	RegisterOption("omit", OptionHandler{
+		Format: func(context.Context, any) (any, error) {
+			return nil, ErrSkip
+		},
+	})
+}
+
+func TestRegisterOption(t *testing.T) {
+	type Item struct {
+		Name     string  `json:"name,omit"`
+		Price    float64 `json:"price,cents"`
+		Discount float64 `json:"discount,omitempty,cents"`
+		Bad      string  `json:"bad,cents"`
+	}
+
+	t.Run("to map", func(t *testing.T) {
+		got, err := New(Omitempty()).ToMapE(Item{Name: "x", Price: 12.34, Bad: "y"})
+		var fe *FieldError
+		require.ErrorAs(t, err, &fe)
+		assert.Equal(t, "Bad", fe.Path)
+		assert.Equal(t, map[string]any{"price": int64(1234), "bad": "y"}, got)
+	})
+	t.Run("from map", func(t *testing.T) {
+		var got Item
+		require.NoError(t, New().FromMap(map[string]any{"name": "x", "price": 1234, "discount": int64(50)}, &got))
+		assert.Equal(t, Item{Name: "x", Price: 12.34, Discount: 0.5}, got)
+	})
+	t.Run("invalid names", func(t *testing.T) {
+		for _, name := range []string{"", "a,b", "omitempty", "hex", "tz=UTC", "prec=2"} {
+			assert.Panics(t, func() { RegisterOption(name, OptionHandler{}) }, name)
+		}
+	})
+}
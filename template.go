@@ -0,0 +1,83 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TemplateData returns the nested map of the struct a for use as the data of
+// text/template and html/template, so that templates can address fields by
+// tag names, i.e. {{.address.city}}.  See Mapper.TemplateData.
+func TemplateData(a any) map[string]any {
+	return New().TemplateData(a)
+}
+
+// TemplateData returns the map of the struct a for use as the template data.
+// Nested structs are nested maps, unless Flatten is set.  Conversion errors
+// are ignored, as with ToMap.
+func (m Mapper) TemplateData(a any) map[string]any {
+	return m.ToMap(a)
+}
+
+// TemplateFuncs returns the helper functions for templates that use the
+// TemplateData.  The map can be passed to Funcs of text/template or
+// html/template.  The functions are:
+//
+//   - get: returns the value at the dot-separated path of keys, or nil, i.e.
+//     {{get . "address.city"}};
+//   - default: returns the value, or the default, if the value is empty, i.e.
+//     {{default "n/a" .email}};
+//   - join: joins the elements of the slice with the separator, i.e.
+//     {{join ", " .tags}}.
+func TemplateFuncs() map[string]any {
+	return map[string]any{
+		"get":     Lookup,
+		"default": templateDefault,
+		"join":    templateJoin,
+	}
+}
+
+// Lookup returns the value at the dot-separated path of keys in the nested map
+// mp, i.e. "address.city".  It returns nil if there's no such value.
+func Lookup(mp map[string]any, path string) any {
+	var cur any = mp
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		if cur, ok = m[key]; !ok {
+			return nil
+		}
+	}
+	return cur
+}
+
+// templateDefault returns v, or dflt if v is empty.
+func templateDefault(dflt, v any) any {
+	if v == nil {
+		return dflt
+	}
+	if isEmpty(reflect.ValueOf(v)) {
+		return dflt
+	}
+	return v
+}
+
+// templateJoin joins the elements of the slice v, formatted with fmt.Sprint,
+// with the separator sep.
+func templateJoin(sep string, v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	sv, err := sliceValue(v)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, sv.Len())
+	for i := range sv.Len() {
+		parts[i] = fmt.Sprint(sv.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
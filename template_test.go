@@ -0,0 +1,59 @@
+package tagops
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateData(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		Name    string   `json:"name"`
+		Email   string   `json:"email"`
+		Tags    []string `json:"tags"`
+		Address Address  `json:"address"`
+	}
+	u := User{Name: "<John>", Tags: []string{"a", "b"}, Address: Address{"Anytown"}}
+
+	t.Run("text/template", func(t *testing.T) {
+		tmpl := template.Must(template.New("").Funcs(TemplateFuncs()).Parse(
+			`{{.name}} from {{.address.city}} {{get . "address.city"}} {{default "n/a" .email}} {{join ", " .tags}}`))
+		var sb strings.Builder
+		require.NoError(t, tmpl.Execute(&sb, TemplateData(u)))
+		assert.Equal(t, "<John> from Anytown Anytown n/a a, b", sb.String())
+	})
+	t.Run("html/template", func(t *testing.T) {
+		tmpl := htmltemplate.Must(htmltemplate.New("").Funcs(TemplateFuncs()).Parse(`<b>{{.name}}</b>`))
+		var sb strings.Builder
+		require.NoError(t, tmpl.Execute(&sb, TemplateData(&u)))
+		assert.Equal(t, "<b>&lt;John&gt;</b>", sb.String())
+	})
+}
+
+func TestLookup(t *testing.T) {
+	mp := map[string]any{"a": map[string]any{"b": 1}, "c": nil}
+	assert.Equal(t, 1, Lookup(mp, "a.b"))
+	assert.Equal(t, map[string]any{"b": 1}, Lookup(mp, "a"))
+	assert.Nil(t, Lookup(mp, "a.x"))
+	assert.Nil(t, Lookup(mp, "a.b.c"))
+	assert.Nil(t, Lookup(mp, "c"))
+	assert.Nil(t, Lookup(nil, "a"))
+}
+
+func Test_templateJoin(t *testing.T) {
+	got, err := templateJoin("-", []any{1, "x"})
+	require.NoError(t, err)
+	assert.Equal(t, "1-x", got)
+	got, err = templateJoin("-", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+	_, err = templateJoin("-", 42)
+	assert.ErrorIs(t, err, ErrNotSlice)
+}
@@ -0,0 +1,172 @@
+package tagops
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeFormat defines how time.Time values are emitted by ToMap.
+type TimeFormat int
+
+const (
+	// TimeAsIs emits time.Time values as is.  This is the default.
+	TimeAsIs TimeFormat = iota
+	// TimeRFC3339 emits RFC3339 strings with nanoseconds, if present.  Tag
+	// option "rfc3339".
+	TimeRFC3339
+	// TimeUnix emits unix time in seconds.  Tag option "unix".
+	TimeUnix
+	// TimeUnixMilli emits unix time in milliseconds.  Tag option "unixms".
+	TimeUnixMilli
+)
+
+// time tag options.
+const (
+	fRFC3339  = "rfc3339"
+	fUnix     = "unix"
+	fUnixMs   = "unixms"
+	fTZPrefix = "tz=" // i.e. "tz=Europe/London"
+)
+
+// FormatTime returns an Option that sets the format of time.Time values.  The
+// format may be overridden per field with the tag option, i.e.
+// `json:"ts,unixms"`.  The time format takes precedence over the registered
+// converters.  FromMap parses the values in the same format back.
+func FormatTime(f TimeFormat) Option {
+	return func(m *Mapper) {
+		m.timeFormat = f
+	}
+}
+
+// TimeIn returns an Option that converts time.Time values to the location loc
+// before formatting.  The location may be overridden per field with the tag
+// option "tz=", i.e. `json:"ts,tz=Europe/London"`.
+func TimeIn(loc *time.Location) Option {
+	return func(m *Mapper) {
+		m.timeLoc = loc
+	}
+}
+
+// locations caches the locations loaded for "tz=" tag options.
+var locations sync.Map // map[string]*time.Location
+
+// loadLocation is a caching version of time.LoadLocation.
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// timeSettings returns the time format and location, taking into account the
+// tag options opts.
+func (m Mapper) timeSettings(opts []string) (TimeFormat, *time.Location, error) {
+	f, loc := m.timeFormat, m.timeLoc
+	for _, o := range opts {
+		switch o {
+		case fRFC3339:
+			f = TimeRFC3339
+		case fUnix:
+			f = TimeUnix
+		case fUnixMs:
+			f = TimeUnixMilli
+		default:
+			if name, ok := strings.CutPrefix(o, fTZPrefix); ok {
+				var err error
+				if loc, err = loadLocation(name); err != nil {
+					return f, nil, err
+				}
+			}
+		}
+	}
+	return f, loc, nil
+}
+
+// formatTime formats the time t according to the time settings.  It returns
+// false if there are no time settings.
+func (m Mapper) formatTime(t time.Time, opts []string) (any, bool, error) {
+	f, loc, err := m.timeSettings(opts)
+	if err != nil {
+		return nil, true, err
+	}
+	if f == TimeAsIs && loc == nil {
+		return nil, false, nil
+	}
+	if loc != nil {
+		t = t.In(loc)
+	}
+	switch f {
+	case TimeRFC3339:
+		return t.Format(time.RFC3339Nano), true, nil
+	case TimeUnix:
+		return t.Unix(), true, nil
+	case TimeUnixMilli:
+		return t.UnixMilli(), true, nil
+	default:
+		return t, true, nil
+	}
+}
+
+// parseTime parses the map value sv formatted by formatTime.  It returns false
+// if sv is a time.Time, or there are no time settings.
+func (m Mapper) parseTime(sv any, opts []string) (time.Time, bool, error) {
+	if _, ok := sv.(time.Time); ok {
+		return time.Time{}, false, nil
+	}
+	f, loc, err := m.timeSettings(opts)
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	if f == TimeAsIs && loc == nil {
+		return time.Time{}, false, nil
+	}
+	var t time.Time
+	switch sv := sv.(type) {
+	case string:
+		n, nErr := strconv.ParseInt(sv, 10, 64)
+		if (f == TimeUnix || f == TimeUnixMilli) && nErr == nil {
+			// numeric string, i.e. from ToKV.
+			t = unixTime(f, n)
+			break
+		}
+		if t, err = time.Parse(time.RFC3339Nano, sv); err != nil {
+			return t, true, err
+		}
+	default:
+		var n int64
+		if err := toInt64(&n, sv); err != nil {
+			return t, true, fmt.Errorf("time: %w", err)
+		}
+		t = unixTime(f, n)
+	}
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return t, true, nil
+}
+
+// unixTime returns the time for the unix timestamp n in the format f.
+func unixTime(f TimeFormat, n int64) time.Time {
+	if f == TimeUnixMilli {
+		return time.UnixMilli(n)
+	}
+	return time.Unix(n, 0)
+}
+
+// toInt64 converts the numeric value v to int64.
+func toInt64(n *int64, v any) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !isNumber(rv.Kind()) {
+		return fmt.Errorf("expected number, got %T", v)
+	}
+	return convertNumber(reflect.ValueOf(n).Elem(), rv)
+}
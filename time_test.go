@@ -0,0 +1,102 @@
+package tagops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTime(t *testing.T) {
+	type Event struct {
+		At      time.Time `json:"at"`
+		AtMs    time.Time `json:"at_ms,unixms"`
+		AtLocal time.Time `json:"at_local,rfc3339,tz=Asia/Tokyo"`
+		Empty   time.Time `json:"empty,omitempty,unix"`
+	}
+	ts := time.Date(2021, 1, 1, 12, 0, 0, 500_000_000, time.UTC)
+	ev := Event{At: ts, AtMs: ts, AtLocal: ts}
+
+	tests := []struct {
+		name string
+		m    Mapper
+		want map[string]any
+	}{
+		{
+			name: "as is, tag options",
+			m:    New(Omitempty()),
+			want: map[string]any{
+				"at":       ts,
+				"at_ms":    ts.UnixMilli(),
+				"at_local": "2021-01-01T21:00:00.5+09:00",
+			},
+		},
+		{
+			name: "rfc3339",
+			m:    New(FormatTime(TimeRFC3339)),
+			want: map[string]any{
+				"at":       "2021-01-01T12:00:00.5Z",
+				"at_ms":    ts.UnixMilli(),
+				"at_local": "2021-01-01T21:00:00.5+09:00",
+				"empty":    time.Time{}.Unix(),
+			},
+		},
+		{
+			name: "unix in location",
+			m:    New(FormatTime(TimeUnix), TimeIn(time.FixedZone("X", 3600))),
+			want: map[string]any{
+				"at":       ts.Unix(),
+				"at_ms":    ts.UnixMilli(),
+				"at_local": "2021-01-01T21:00:00.5+09:00",
+				"empty":    time.Time{}.Unix(),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.ToMapE(ev)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("location in", func(t *testing.T) {
+		loc := time.FixedZone("X", 3600)
+		got := New(TimeIn(loc)).ToMap(ev)
+		assert.Equal(t, loc, got["at"].(time.Time).Location())
+	})
+	t.Run("bad location", func(t *testing.T) {
+		_, err := New().ToMapE(struct {
+			At time.Time `json:"at,tz=Nowhere/Nothing"`
+		}{})
+		assert.Error(t, err)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		m := New(FormatTime(TimeRFC3339))
+		var got Event
+		err := m.FromMap(m.ToMap(ev), &got)
+		assert.NoError(t, err)
+		assert.True(t, ev.At.Equal(got.At), "at")
+		assert.True(t, ts.Truncate(time.Millisecond).Equal(got.AtMs), "at_ms")
+		assert.True(t, ev.AtLocal.Equal(got.AtLocal), "at_local")
+		assert.Equal(t, "Asia/Tokyo", got.AtLocal.Location().String())
+	})
+	t.Run("parse json numbers", func(t *testing.T) {
+		var got Event
+		err := New(FormatTime(TimeUnix)).FromMap(map[string]any{"at": float64(ts.Unix())}, &got)
+		assert.NoError(t, err)
+		assert.True(t, ts.Truncate(time.Second).Equal(got.At))
+	})
+	t.Run("parse error", func(t *testing.T) {
+		var got Event
+		err := New(FormatTime(TimeRFC3339)).FromMap(map[string]any{"at": "yesterday"}, &got)
+		assert.Error(t, err)
+	})
+}
+
+func Test_tagName_multipleOptions(t *testing.T) {
+	type S struct {
+		At time.Time `json:"at,unix,omitempty"`
+	}
+	_, err := tagName(field(t, S{}, 0), value(t, S{}, 0), "json", true)
+	assert.ErrorIs(t, err, ErrSkip)
+}
@@ -0,0 +1,284 @@
+package tagops
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EncodeTOML writes the TOML encoding of the struct a, converted with the
+// Mapper with options opts, to w, see Mapper.EncodeTOML.
+func EncodeTOML(w io.Writer, a any, opts ...Option) error {
+	return New(opts...).EncodeTOML(w, a)
+}
+
+// EncodeTOML writes the TOML encoding of the map of the struct a to w, so
+// that the output has the same keys and values as ToMap, including flattened
+// and formatted fields.  The leaf values are written first, then the nested
+// structs as [tables], and the slices of structs as [[arrays of tables]].
+// The keys are written in the alphabetical order, or in the field order, if
+// FieldOrder is set.
+//
+// TOML has no null, so the nil values are omitted.  Byte slices are written
+// as base64 strings, as in JSON, and values of the types that can not be
+// represented in TOML result in ErrUnsupportedKind.
+func (m Mapper) EncodeTOML(w io.Writer, a any) error {
+	mp, ord, err := m.document(a)
+	if err != nil {
+		return err
+	}
+	enc := tomlEncoder{w: bufio.NewWriter(w)}
+	if err := enc.table(nil, mp, ord, false); err != nil {
+		return err
+	}
+	return enc.w.Flush()
+}
+
+type tomlEncoder struct {
+	w       *bufio.Writer
+	written bool
+}
+
+// table writes the table mp with the key path.  Arrays of tables have the
+// header written for every element.
+func (e *tomlEncoder) table(path []string, mp map[string]any, ord *keyOrder, arr bool) error {
+	var leaves, tables []string
+	for _, k := range ord.sorted(mp) {
+		switch v := mp[k]; {
+		case v == nil || isNilValue(reflect.ValueOf(v)):
+		case isTable(v):
+			tables = append(tables, k)
+		default:
+			if _, ok := tableArray(v); ok {
+				tables = append(tables, k)
+			} else {
+				leaves = append(leaves, k)
+			}
+		}
+	}
+	if len(path) > 0 && (arr || len(leaves) > 0 || len(tables) == 0) {
+		if e.written {
+			e.w.WriteByte('\n')
+		}
+		if arr {
+			fmt.Fprintf(e.w, "[[%s]]\n", tomlPath(path))
+		} else {
+			fmt.Fprintf(e.w, "[%s]\n", tomlPath(path))
+		}
+		e.written = true
+	}
+	for _, k := range leaves {
+		e.w.WriteString(tomlKey(k))
+		e.w.WriteString(" = ")
+		if err := tomlValue(e.w, mp[k]); err != nil {
+			return &FieldError{Path: strings.Join(append(path, k), "."), Err: err}
+		}
+		e.w.WriteByte('\n')
+		e.written = true
+	}
+	for _, k := range tables {
+		sub := append(path[:len(path):len(path)], k)
+		if nested, ok := mp[k].(map[string]any); ok {
+			if err := e.table(sub, nested, ord.sub(k), false); err != nil {
+				return err
+			}
+			continue
+		}
+		elems, _ := tableArray(mp[k])
+		for _, el := range elems {
+			if err := e.table(sub, el, ord.sub(k), true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isNilValue returns true if v is a nil pointer, interface, map or slice.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func isTable(v any) bool {
+	_, ok := v.(map[string]any)
+	return ok
+}
+
+// tableArray returns the elements of v, if v is a non-empty slice of maps,
+// that is written as the array of tables.
+func tableArray(v any) ([]map[string]any, bool) {
+	switch v := v.(type) {
+	case []map[string]any:
+		return v, len(v) > 0
+	case []any:
+		out := make([]map[string]any, 0, len(v))
+		for _, e := range v {
+			mp, ok := e.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, mp)
+		}
+		return out, len(out) > 0
+	}
+	return nil, false
+}
+
+// tomlValue writes the inline TOML value of v to w.
+func tomlValue(w *bufio.Writer, v any) error {
+	switch v := v.(type) {
+	case string:
+		w.WriteString(tomlString(v))
+		return nil
+	case []byte:
+		w.WriteString(tomlString(base64.StdEncoding.EncodeToString(v)))
+		return nil
+	case time.Time:
+		w.WriteString(v.Format(time.RFC3339Nano))
+		return nil
+	case map[string]any:
+		w.WriteByte('{')
+		n := 0
+		for _, k := range Keys(v) {
+			if v[k] == nil {
+				continue
+			}
+			if n++; n > 1 {
+				w.WriteString(", ")
+			}
+			w.WriteString(tomlKey(k))
+			w.WriteString(" = ")
+			if err := tomlValue(w, v[k]); err != nil {
+				return err
+			}
+		}
+		w.WriteByte('}')
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		w.WriteString(strconv.FormatBool(rv.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.WriteString(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return fmt.Errorf("%w: %d overflows TOML integer", ErrUnsupportedKind, rv.Uint())
+		}
+		w.WriteString(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		w.WriteString(tomlFloat(rv.Float()))
+	case reflect.String:
+		w.WriteString(tomlString(rv.String()))
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return fmt.Errorf("%w: nil in array", ErrUnsupportedKind)
+		}
+		return tomlValue(w, rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		w.WriteByte('[')
+		for i := range rv.Len() {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			if err := tomlValue(w, rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: %s", ErrUnsupportedKind, rv.Type())
+		}
+		mp := make(map[string]any, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			mp[it.Key().String()] = it.Value().Interface()
+		}
+		return tomlValue(w, mp)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedKind, rv.Type())
+	}
+	return nil
+}
+
+// tomlFloat formats f as TOML float, that always has the fraction or the
+// exponent.
+func tomlFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// tomlString returns s as TOML basic string.
+func tomlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// tomlKey returns k as the bare key, if possible, or as the quoted key.
+func tomlKey(k string) string {
+	if k == "" {
+		return `""`
+	}
+	for _, r := range k {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return tomlString(k)
+		}
+	}
+	return k
+}
+
+// tomlPath returns the dotted key of the table path.
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, k := range path {
+		keys[i] = tomlKey(k)
+	}
+	return strings.Join(keys, ".")
+}
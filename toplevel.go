@@ -0,0 +1,171 @@
+package tagops
+
+import (
+	"iter"
+	"reflect"
+	"slices"
+)
+
+// TopLevel returns an iterator over the key and field value pairs of the
+// first level of the struct a, i.e. the keys of the map returned by ToMap.
+// The values are not converted and are not descended into, nested structs
+// are yielded as is.  Fields of flattened structs are promoted, and the key
+// conflicts are resolved as in ToMap, see StrictConflicts.  The fields are
+// yielded in the field order, the promoted fields after the fields of the
+// struct.  Hooks are not called.  The fields marked for encryption are
+// skipped if Encryption is set, as the raw values can not be encrypted.
+//
+// The keys have the prefix, if set, see Prefix.
+//
+// The sequence is empty if a is not a struct or a non-nil pointer to struct.
+func (m Mapper) TopLevel(a any) iter.Seq2[string, reflect.Value] {
+	return func(yield func(string, reflect.Value) bool) {
+		for fi, fv := range m.TopLevelFields(a) {
+			if !yield(fi.Key, fv) {
+				return
+			}
+		}
+	}
+}
+
+// TopLevelFields is like TopLevel, but yields the FieldInfo of the fields,
+// so that the tag options can be inspected, i.e. with FieldInfo.DBColumn.
+func (m Mapper) TopLevelFields(a any) iter.Seq2[FieldInfo, reflect.Value] {
+	return func(yield func(FieldInfo, reflect.Value) bool) {
+		v, err := derefStruct(reflect.ValueOf(a))
+		if err != nil {
+			return
+		}
+		for _, f := range m.topFields(v.Type(), v, true) {
+			if m.secure(f.fi.Field) {
+				continue
+			}
+			f.fi.Key = m.prefix + f.fi.Key
+			if !yield(f.fi, v.FieldByIndex(f.index)) {
+				return
+			}
+		}
+	}
+}
+
+// topField is the field that maps to the key of the first level of the map
+// of the struct, see Mapper.topFields.
+type topField struct {
+	fi FieldInfo
+	// index is the index sequence of the field in the struct.
+	index []int
+	depth int
+	// tagged is true if the key comes from the tag.
+	tagged bool
+	// readOnly is true if the field, or any flattened struct it's promoted
+	// from, is read-only.
+	readOnly bool
+}
+
+// topFields returns the fields of the struct type typ, that map to the keys
+// of the first level of the map, in the order of TopLevel.  The fields of
+// the flattened structs are promoted, and the key conflicts are resolved
+// following the Go embedding rules, as in ToMap: the shallowest field wins,
+// and of several fields at the same depth, the only tagged one, otherwise
+// the key is omitted.  If v is valid, it is the value of the struct, and the
+// empty fields are omitted, if Omitempty is set.  If output is true, the
+// write-only fields are omitted, as in ToMap.
+func (m Mapper) topFields(typ reflect.Type, v reflect.Value, output bool) []topField {
+	var all []topField
+	m.collectFields(&all, typ, v, topField{}, output)
+
+	depth := make(map[string]int, len(all))
+	for _, f := range all {
+		if d, ok := depth[f.fi.Key]; !ok || f.depth < d {
+			depth[f.fi.Key] = f.depth
+		}
+	}
+	// candidates are the fields at the shallowest depth of the key.
+	type candidates struct{ n, tagged, first, firstTagged int }
+	cands := make(map[string]*candidates, len(depth))
+	for i, f := range all {
+		if f.depth != depth[f.fi.Key] {
+			continue
+		}
+		c, ok := cands[f.fi.Key]
+		if !ok {
+			c = &candidates{first: i, firstTagged: -1}
+			cands[f.fi.Key] = c
+		}
+		c.n++
+		if f.tagged {
+			c.tagged++
+			if c.firstTagged < 0 {
+				c.firstTagged = i
+			}
+		}
+	}
+	// dominant is the index of the field that wins the key, or -1 if the
+	// key is in conflict, see entry.dominant.
+	dominant := make(map[string]int, len(cands))
+	for key, c := range cands {
+		switch {
+		case c.n == 1:
+			dominant[key] = c.first
+		case c.tagged == 1:
+			dominant[key] = c.firstTagged
+		default:
+			dominant[key] = -1
+		}
+	}
+	out := make([]topField, 0, len(dominant))
+	for i, f := range all {
+		if dominant[f.fi.Key] == i {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// collectFields appends the fields of the struct type typ to out, followed by
+// the fields of the flattened structs.  parent is the flattened struct field
+// of typ, or zero for the root struct.
+func (m Mapper) collectFields(out *[]topField, typ reflect.Type, v reflect.Value, parent topField, output bool) {
+	m = m.withProfile(typ)
+	plan := m.plan(typ)
+	var nested []int
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		field := fp.field
+		if !field.IsExported() {
+			continue
+		}
+		if isNested(field.Type) && m.flattens(field, fp.tag) {
+			nested = append(nested, i)
+			continue
+		}
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+		key, err := m.fieldKey(fp, fv, m.Omitempty && fv.IsValid())
+		if err != nil || output && fp.has(fWriteOnly) {
+			continue
+		}
+		*out = append(*out, topField{
+			fi:       FieldInfo{Field: field, Key: key, Path: joinPath(parent.fi.Path, field.Name), Options: fp.opts},
+			index:    append(slices.Clip(parent.index), i),
+			depth:    parent.depth,
+			tagged:   fp.tagged(),
+			readOnly: parent.readOnly || fp.has(fReadOnly),
+		})
+	}
+	for _, i := range nested {
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+		fp := &plan.fields[i]
+		m.collectFields(out, fp.field.Type, fv, topField{
+			fi:       FieldInfo{Path: joinPath(parent.fi.Path, fp.field.Name)},
+			index:    append(slices.Clip(parent.index), i),
+			depth:    parent.depth + 1,
+			readOnly: parent.readOnly || fp.has(fReadOnly),
+		}, output)
+	}
+}
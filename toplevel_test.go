@@ -0,0 +1,135 @@
+package tagops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapper_TopLevel(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Base struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type User struct {
+		Base
+		Name    string  `json:"name"`
+		Email   string  `json:"email,omitempty"`
+		Address Address `json:"address"`
+		secret  string
+	}
+	u := &User{Base: Base{1, "shadowed"}, Name: "John", Address: Address{"Anytown"}, secret: "x"}
+
+	collect := func(m Mapper, a any) map[string]any {
+		out := make(map[string]any)
+		for key, v := range m.TopLevel(a) {
+			out[key] = v.Interface()
+		}
+		return out
+	}
+
+	t.Run("nested values are not descended into", func(t *testing.T) {
+		assert.Equal(t, map[string]any{
+			"id":      1,
+			"name":    "John",
+			"email":   "",
+			"address": Address{"Anytown"},
+		}, collect(New(), u))
+	})
+	t.Run("omitempty and flatten", func(t *testing.T) {
+		assert.Equal(t, map[string]any{
+			"id":   1,
+			"name": "John",
+			"city": "Anytown",
+		}, collect(New(Omitempty(), Flatten()), u))
+	})
+	t.Run("values are addressable through a pointer", func(t *testing.T) {
+		for key, v := range New().TopLevel(u) {
+			if key == "email" {
+				v.SetString("john@example.com")
+			}
+		}
+		assert.Equal(t, "john@example.com", u.Email)
+	})
+	t.Run("early stop", func(t *testing.T) {
+		n := 0
+		for range New().TopLevel(u) {
+			n++
+			break
+		}
+		assert.Equal(t, 1, n)
+	})
+	t.Run("not a struct", func(t *testing.T) {
+		assert.Empty(t, collect(New(), 42))
+		assert.Empty(t, collect(New(), (*User)(nil)))
+		assert.Empty(t, collect(New(), nil))
+	})
+}
+
+func TestMapper_TopLevel_conflicts(t *testing.T) {
+	type Inner struct {
+		Code string `json:"code"`
+	}
+	type First struct {
+		Inner
+		Note string
+	}
+	type Second struct {
+		Code string `json:"code"`
+		Note string
+		Tag  string `json:"tag"`
+	}
+	type Third struct {
+		Tag string
+	}
+	type S struct {
+		First
+		Second
+		Third
+	}
+	s := S{First{Inner{"deep"}, "n1"}, Second{"shallow", "n2", "tagged"}, Third{"untagged"}}
+
+	m := New(KeyFunc(strings.ToLower))
+	got := make(map[string]any)
+	for key, v := range m.TopLevel(s) {
+		got[key] = v.Interface()
+	}
+	want := map[string]any{
+		"code": "shallow", // the deeper field of the earlier embed loses
+		"tag":  "tagged",  // the tagged field wins at the same depth
+		// note is ambiguous, and is omitted
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, want, m.ToMap(s), "TopLevel must yield the keys of ToMap")
+
+	vals, err := m.Pluck([]S{s}, "code")
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"shallow"}, vals)
+	_, err = m.Pluck([]S{s}, "note")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+}
+
+func TestMapper_TopLevelFields(t *testing.T) {
+	type Base struct {
+		ID int `db:"id,pk"`
+	}
+	type Row struct {
+		Base
+		Name string `db:"name,type=text"`
+	}
+	var got []FieldInfo
+	for fi := range New(Tag("db")).TopLevelFields(Row{}) {
+		got = append(got, fi)
+	}
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "name", got[0].Key)
+		assert.Equal(t, []string{"type=text"}, got[0].Options)
+		assert.Equal(t, "id", got[1].Key)
+		assert.Equal(t, "Base.ID", got[1].Path)
+		assert.True(t, got[1].DBColumn().PK)
+	}
+}
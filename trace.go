@@ -0,0 +1,102 @@
+package tagops
+
+import (
+	"reflect"
+)
+
+// TraceKind is the kind of the TraceEvent.
+type TraceKind int
+
+const (
+	// TraceEnterStruct is emitted before the fields of the struct, including
+	// nested ones, are mapped.
+	TraceEnterStruct TraceKind = iota
+	// TraceFieldResolved is emitted when the key of the field is resolved
+	// and the field is going to be mapped.
+	TraceFieldResolved
+	// TraceFieldSkipped is emitted when the field is skipped, with the
+	// reason.
+	TraceFieldSkipped
+	// TraceValueConverted is emitted when the leaf value is converted, with
+	// the resulting value, and the error, if any.
+	TraceValueConverted
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceEnterStruct:
+		return "enter struct"
+	case TraceFieldResolved:
+		return "field resolved"
+	case TraceFieldSkipped:
+		return "field skipped"
+	case TraceValueConverted:
+		return "value converted"
+	}
+	return "unknown"
+}
+
+// TraceEvent is the structured event of the conversion, see WithTrace.
+type TraceEvent struct {
+	Kind TraceKind
+	// Op is "ToMap" or "FromMap".
+	Op string
+	// Path is the dot-separated path of Go field names from the root
+	// struct, i.e. "Address.ZIP", or the path of the struct for
+	// TraceEnterStruct.
+	Path string
+	// Type is the type of the struct or the field.
+	Type reflect.Type
+	// Key is the resolved key of the field, if known.
+	Key string
+	// Reason is the reason why the field is skipped.
+	Reason string
+	// Value is the converted value for ToMap, and the source value for
+	// FromMap.
+	Value any
+	// Err is the conversion error, if any.
+	Err error
+}
+
+// WithTrace returns an Option that sets the function fn, that receives the
+// events of ToMap and FromMap: entering the struct, resolving or skipping
+// the field, with the reason, and converting the value, so that the
+// conversion problems can be logged in production.  fn is called
+// synchronously, and must be safe for concurrent use, if the Mapper is
+// shared.  See also Explain.
+func WithTrace(fn func(event TraceEvent)) Option {
+	return func(m *Mapper) {
+		m.traceFn = fn
+	}
+}
+
+// tracing reports whether the trace events are consumed, either by the trace
+// function or by the metrics.
+func (m Mapper) tracing() bool {
+	return m.traceFn != nil || m.metrics != nil
+}
+
+// trace emits the event ev, if tracing is enabled.
+func (m Mapper) trace(ev TraceEvent) {
+	if m.metrics != nil {
+		m.metrics.event(ev)
+	}
+	if m.traceFn != nil {
+		m.traceFn(ev)
+	}
+}
+
+// traceSkip emits the TraceFieldSkipped event for the field at path.
+func (m Mapper) traceSkip(op string, field reflect.StructField, path, key, reason string) {
+	if m.tracing() {
+		m.trace(TraceEvent{Kind: TraceFieldSkipped, Op: op, Path: path, Type: field.Type, Key: key, Reason: reason})
+	}
+}
+
+// lastErr returns the last error recorded in st after the first n.
+func (st *state) lastErr(n int) error {
+	if len(st.errs) > n {
+		return st.errs[len(st.errs)-1]
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package tagops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type traceUser struct {
+	Name     string `json:"name"`
+	secret   string
+	Nick     string `json:"nick,omitempty"`
+	Password string `json:"password,writeonly"`
+	Age      int    `json:"age"`
+}
+
+// traceLog collects the events, omitting the types, for comparison.
+type traceLog []TraceEvent
+
+func (l *traceLog) add(ev TraceEvent) {
+	ev.Type = nil
+	*l = append(*l, ev)
+}
+
+func TestWithTrace(t *testing.T) {
+	t.Run("ToMap", func(t *testing.T) {
+		var log traceLog
+		m := New(Omitempty(), WithTrace(log.add))
+		_, err := m.ToMapE(traceUser{Name: "ann", Age: 42})
+		require.NoError(t, err)
+		want := traceLog{
+			{Kind: TraceEnterStruct, Op: "ToMap"},
+			{Kind: TraceFieldResolved, Op: "ToMap", Path: "Name", Key: "name"},
+			{Kind: TraceValueConverted, Op: "ToMap", Path: "Name", Key: "name", Value: "ann"},
+			{Kind: TraceFieldSkipped, Op: "ToMap", Path: "secret", Reason: "unexported"},
+			{Kind: TraceFieldSkipped, Op: "ToMap", Path: "Nick", Reason: "empty, with omitempty"},
+			{Kind: TraceFieldSkipped, Op: "ToMap", Path: "Password", Key: "password", Reason: "write-only"},
+			{Kind: TraceFieldResolved, Op: "ToMap", Path: "Age", Key: "age"},
+			{Kind: TraceValueConverted, Op: "ToMap", Path: "Age", Key: "age", Value: 42},
+		}
+		assert.Equal(t, want, log)
+	})
+	t.Run("FromMap", func(t *testing.T) {
+		var log traceLog
+		m := New(WithTrace(log.add))
+		var u traceUser
+		err := m.FromMap(map[string]any{"name": "ann", "age": "x"}, &u)
+		require.Error(t, err)
+		require.Len(t, log, 8)
+		assert.Equal(t, TraceEvent{Kind: TraceFieldSkipped, Op: "FromMap", Path: "Nick", Key: "nick", Reason: "absent in the source"}, log[4])
+		last := log[len(log)-1]
+		assert.Equal(t, TraceValueConverted, last.Kind)
+		assert.Equal(t, "Age", last.Path)
+		assert.Equal(t, "x", last.Value)
+		var fe *FieldError
+		assert.False(t, errors.As(last.Err, &fe), "the event carries the conversion error itself")
+		assert.Error(t, last.Err)
+	})
+}
+
+func TestTraceKind_String(t *testing.T) {
+	assert.Equal(t, "field skipped", TraceFieldSkipped.String())
+	assert.Equal(t, "unknown", TraceKind(-1).String())
+}
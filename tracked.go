@@ -0,0 +1,60 @@
+package tagops
+
+import "strings"
+
+// Tracked wraps the struct value and tracks the changes made to it since the
+// snapshot.  T must be a struct type, not a pointer, as the snapshot is a copy
+// of the value.
+//
+// The snapshot is a shallow copy, so the changes made in place to the
+// elements of slices or maps, that are shared with the snapshot, are not
+// detected.  Assign new slices and maps to the fields instead.
+type Tracked[T any] struct {
+	m    Mapper
+	orig T
+	cur  T
+}
+
+// Track returns the Tracked value of v, with the snapshot taken.  The
+// changes are reported by the Mapper configured with options opts.
+func Track[T any](v T, opts ...Option) *Tracked[T] {
+	return &Tracked[T]{m: New(opts...), orig: v, cur: v}
+}
+
+// Value returns the pointer to the tracked value for modification.
+func (t *Tracked[T]) Value() *T {
+	return &t.cur
+}
+
+// Changed returns true if the value differs from the snapshot.
+func (t *Tracked[T]) Changed() bool {
+	changes, err := t.m.Diff(t.orig, t.cur)
+	return err != nil || len(changes) > 0
+}
+
+// Changes returns the map of the modified fields to their new values, i.e.
+// for the PATCH request body or the SQL UPDATE.  Changed fields of nested
+// structs are in nested maps, unless flattened.  Fields that were omitted
+// since the snapshot, i.e. with omitempty, have nil values.  See Diff.
+func (t *Tracked[T]) Changes() (map[string]any, error) {
+	changes, err := t.m.Diff(t.orig, t.cur)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]any, len(changes))
+	for _, c := range changes {
+		setPath(out, strings.Split(c.Path, "."), c.New)
+	}
+	return out, nil
+}
+
+// Commit takes a new snapshot of the current value, i.e. after the changes
+// are saved.
+func (t *Tracked[T]) Commit() {
+	t.orig = t.cur
+}
+
+// Reset discards the changes and restores the value from the snapshot.
+func (t *Tracked[T]) Reset() {
+	t.cur = t.orig
+}
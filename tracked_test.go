@@ -0,0 +1,50 @@
+package tagops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracked(t *testing.T) {
+	u := diffUser{Name: "John", Age: 30, Address: diffAddress{City: "Anytown", ZIP: "123"}}
+	tr := Track(u, Omitempty())
+	assert.False(t, tr.Changed())
+
+	v := tr.Value()
+	v.Age = 31
+	v.Address.ZIP = ""
+	v.Tags = []string{"new"}
+	assert.True(t, tr.Changed())
+
+	got, err := tr.Changes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"age":     31,
+		"tags":    []string{"new"},
+		"address": map[string]any{"zip": nil},
+	}, got)
+	assert.Equal(t, u, tr.orig, "snapshot is not modified")
+
+	t.Run("commit", func(t *testing.T) {
+		tr.Commit()
+		assert.False(t, tr.Changed())
+		tr.Value().Name = "Jane"
+		got, err := tr.Changes()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"name": "Jane"}, got)
+	})
+	t.Run("reset", func(t *testing.T) {
+		tr.Reset()
+		assert.Equal(t, "John", tr.Value().Name)
+		assert.False(t, tr.Changed())
+	})
+	t.Run("flatten", func(t *testing.T) {
+		tr := Track(u, Flatten())
+		tr.Value().Address.City = "Othertown"
+		got, err := tr.Changes()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"city": "Othertown"}, got)
+	})
+}
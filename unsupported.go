@@ -0,0 +1,79 @@
+package tagops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// UnsupportedPolicy defines how ToMap handles fields of kinds that can not be
+// serialised: func, chan and unsafe.Pointer.
+type UnsupportedPolicy int
+
+const (
+	// UnsupportedKeep keeps the raw value in the map.  This is the default.
+	UnsupportedKeep UnsupportedPolicy = iota
+	// UnsupportedSkip omits the field from the map.
+	UnsupportedSkip
+	// UnsupportedEmitNil sets the map value to nil.
+	UnsupportedEmitNil
+	// UnsupportedError omits the field from the map and reports an error
+	// wrapping ErrUnsupportedKind.
+	UnsupportedError
+)
+
+// Unsupported returns an Option that sets the policy for fields of
+// unsupported kinds.  Fields that have a converter registered are always
+// converted.
+func Unsupported(p UnsupportedPolicy) Option {
+	return func(m *Mapper) {
+		m.unsupported = p
+	}
+}
+
+// isUnsupported returns true if values of kind k can not be serialised.
+func isUnsupported(k reflect.Kind) bool {
+	switch k {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+	return false
+}
+
+// value returns the map value for the leaf field value fv, applying the
+// custom tag options, formats, unsupported kinds policy and the registered
+// converters.  opts are the tag options of the field.  If EncoderSafe is set,
+// the value is reduced to the encoder-safe types.
+func (m Mapper) value(ctx context.Context, fv reflect.Value, opts []string) (any, error) {
+	val, err := m.convert(ctx, fv, opts)
+	if !m.encoderSafe || errors.Is(err, ErrSkip) || errors.Is(err, ErrUnsupportedKind) {
+		return val, err
+	}
+	safe, ok := m.safeValue(reflect.ValueOf(val))
+	if !ok {
+		return nil, ErrSkip
+	}
+	return safe, err
+}
+
+// convert returns the map value for the leaf field value fv, see value.
+func (m Mapper) convert(ctx context.Context, fv reflect.Value, opts []string) (any, error) {
+	if val, ok, err := formatOption(ctx, fv, opts); ok {
+		return val, err
+	}
+	if val, ok, err := m.format(fv, opts); ok {
+		return val, err
+	}
+	if _, ok := outConverter(fv.Type()); !ok && isUnsupported(fv.Kind()) {
+		switch m.unsupported {
+		case UnsupportedSkip:
+			return nil, ErrSkip
+		case UnsupportedEmitNil:
+			return nil, nil
+		case UnsupportedError:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedKind, fv.Kind())
+		}
+	}
+	return convertOut(ctx, fv)
+}
@@ -0,0 +1,50 @@
+package tagops
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsupported(t *testing.T) {
+	type S struct {
+		Name     string         `json:"name"`
+		Callback func()         `json:"callback"`
+		Events   chan int       `json:"events"`
+		Ptr      unsafe.Pointer `json:"ptr"`
+	}
+	s := S{Name: "John", Callback: func() {}, Events: make(chan int)}
+
+	tests := []struct {
+		name     string
+		policy   UnsupportedPolicy
+		wantKeys []string
+		wantNil  bool
+		wantErr  bool
+	}{
+		{"keep", UnsupportedKeep, []string{"callback", "events", "name", "ptr"}, false, false},
+		{"skip", UnsupportedSkip, []string{"name"}, false, false},
+		{"emit nil", UnsupportedEmitNil, []string{"callback", "events", "name", "ptr"}, true, false},
+		{"error", UnsupportedError, []string{"name"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(Unsupported(tt.policy))
+			got, err := m.ToMapE(s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToMapE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnsupportedKind)
+			}
+			assert.Equal(t, tt.wantKeys, Keys(got))
+			if tt.wantNil {
+				assert.Nil(t, got["callback"])
+				assert.Nil(t, got["events"])
+			}
+			mm := m.ToMultiMap(s, "json")
+			assert.Equal(t, tt.wantKeys, Keys(mm["json"]))
+		})
+	}
+}
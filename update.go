@@ -0,0 +1,69 @@
+package tagops
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Update applies the functions in updates to the fields of the struct pointed
+// to by dst, see Mapper.Update.
+func Update(dst any, updates map[string]func(old any) any) error {
+	return New().Update(dst, updates)
+}
+
+// Update applies the functions in updates to the fields of the struct pointed
+// to by dst.  The updates are keyed by the field key in the tag, or by the
+// dot-separated key path for fields of nested structs, i.e. "address.city".
+// Each function receives the current value of the field and returns the new
+// one, that is assigned with the same conversion rules and tag options as in
+// FromMap.  The updates are applied in the order of keys, and the first error
+// stops them.  It returns an error wrapping ErrFieldNotFound if there's no
+// field for the key, and ErrReadOnly if the field is read-only.
+func (m Mapper) Update(dst any, updates map[string]func(old any) any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected non-nil pointer to struct, got %T", ErrNotStruct, dst)
+	}
+	for _, key := range slices.Sorted(maps.Keys(updates)) {
+		fv, f, ok := m.fieldByPath(v.Elem(), key)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrFieldNotFound, key)
+		}
+		if f.readOnly {
+			return fmt.Errorf("%w: %q", ErrReadOnly, key)
+		}
+		if err := m.assign(context.Background(), fv, updates[key](fv.Interface()), f.fi.Options); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// fieldByPath returns the value and the description of the field of the
+// struct value v with the dot-separated key path.  The field is read-only if
+// any of the fields on the path is, as the nested fields of the read-only
+// struct are read-only too.
+func (m Mapper) fieldByPath(v reflect.Value, path string) (reflect.Value, topField, bool) {
+	var readOnly bool
+	for {
+		key, rest, nested := strings.Cut(path, ".")
+		f, ok := m.lookupField(v.Type(), key)
+		if !ok {
+			return reflect.Value{}, topField{}, false
+		}
+		fv := v.FieldByIndex(f.index)
+		readOnly = readOnly || f.readOnly
+		if !nested {
+			f.readOnly = readOnly
+			return fv, f, true
+		}
+		if !isNested(fv.Type()) {
+			return reflect.Value{}, topField{}, false
+		}
+		v, path = fv, rest
+	}
+}
@@ -0,0 +1,61 @@
+package tagops
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	trim := func(old any) any { return strings.TrimSpace(old.(string)) }
+	u := diffUser{Name: "  John ", Age: 30, Address: diffAddress{City: " Anytown"}}
+
+	err := Update(&u, map[string]func(any) any{
+		"name":         trim,
+		"address.city": trim,
+		"age":          func(old any) any { return old.(int) + 1 },
+		"tags":         func(any) any { return []string{"x"} },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, diffUser{Name: "John", Age: 31, Tags: []string{"x"}, Address: diffAddress{City: "Anytown"}}, u)
+
+	t.Run("conversion", func(t *testing.T) {
+		require.NoError(t, Update(&u, map[string]func(any) any{
+			"age": func(any) any { return 40.0 },
+		}))
+		assert.Equal(t, 40, u.Age)
+		assert.Error(t, Update(&u, map[string]func(any) any{
+			"age": func(any) any { return "forty" },
+		}))
+	})
+	t.Run("flattened", func(t *testing.T) {
+		type Base struct {
+			ID int `json:"id"`
+		}
+		type S struct {
+			Base
+		}
+		var s S
+		require.NoError(t, Update(&s, map[string]func(any) any{"id": func(any) any { return 7 }}))
+		assert.Equal(t, 7, s.ID)
+	})
+	t.Run("tag options", func(t *testing.T) {
+		var s struct {
+			At time.Time `json:"at,unix"`
+		}
+		require.NoError(t, Update(&s, map[string]func(any) any{"at": func(any) any { return 1700000000 }}))
+		assert.Equal(t, time.Unix(1700000000, 0), s.At)
+	})
+	t.Run("not found", func(t *testing.T) {
+		for _, key := range []string{"nope", "name.x", "address.nope"} {
+			err := Update(&u, map[string]func(any) any{key: trim})
+			assert.ErrorIs(t, err, ErrFieldNotFound, key)
+		}
+	})
+	t.Run("not a pointer", func(t *testing.T) {
+		assert.ErrorIs(t, Update(u, nil), ErrNotStruct)
+	})
+}
@@ -0,0 +1,74 @@
+package tagops
+
+import (
+	"encoding/base64"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncodeYAML writes the YAML encoding of the struct a, converted with the
+// Mapper with options opts, to w, see Mapper.EncodeYAML.
+func EncodeYAML(w io.Writer, a any, opts ...Option) error {
+	return New(opts...).EncodeYAML(w, a)
+}
+
+// EncodeYAML writes the YAML encoding of the map of the struct a to w, so
+// that the output has the same keys and values as ToMap, including flattened
+// and formatted fields.  The structs in slices and maps are converted with
+// the same Mapper configuration.  The keys are written in the alphabetical
+// order, or in the field order, if FieldOrder is set.  Byte slices are
+// written as base64 strings, as in JSON.
+func (m Mapper) EncodeYAML(w io.Writer, a any) error {
+	mp, ord, err := m.document(a)
+	if err != nil {
+		return err
+	}
+	node, err := yamlNode(mp, ord)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// yamlNode returns the YAML node for the value v, with the keys of the maps
+// in order ord.
+func yamlNode(v any, ord *keyOrder) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range ord.sorted(v) {
+			val, err := yamlNode(v[k], ord.sub(k))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, val)
+		}
+		return node, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, e := range v {
+			val, err := yamlNode(e, ord)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, val)
+		}
+		return node, nil
+	case []byte:
+		if v == nil {
+			return yamlNode(nil, nil)
+		}
+		return yamlNode(base64.StdEncoding.EncodeToString(v), nil)
+	}
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}